@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// commonWords are the commonest English words. Longer words outside them
+// count as rare.
+var commonWords = wordSet(`
+	a about above across act after again against age ago air all almost alone
+	along already also although always am among an and another answer any
+	anyone anything are area around as ask at away back bad be became because
+	become been before began begin behind being believe best better between big
+	black body book both boy bring brought but by call came can car care carry
+	case cause center certain change child children city class clear close cold
+	come common could country course cut dark day did different do does done
+	door down draw during each early earth east easy eat end enough even ever
+	every eye face fact fall family far father feel feet few field find fine
+	fire first five follow food for form found four free friend from front full
+	game gave get girl give given go going good got great green ground group
+	grow had half hand happen hard has have he head hear heard heart help her
+	here high him himself his hold home hope hour house how however hundred
+	idea if important in inside into is it its itself just keep kept kind knew
+	know land large last late later learn least leave left less let letter life
+	light like line list little live long look lost lot love low made make man
+	many may me mean men might mind minute miss money more morning most mother
+	move much must my name near need never new next night no not nothing now
+	number of off often old on once one only open or order other others our out
+	over own page paper part party past people perhaps person picture place
+	plan play point possible power present problem public put question quite
+	rather read ready real really reason red remember rest right road room run
+	said same saw say school sea second see seem seemed seen self sense set
+	several shall she short should show side simple since small so some someone
+	something sometimes soon sound speak stand start state still stood stop
+	story street strong study such sure system table take talk tell ten than
+	that the their them themselves then there these they thing things think
+	third this those though thought three through time to today together told
+	too took toward town tree true try turn two under understand until up upon
+	us use used very voice wait walk want war was watch water way we week well
+	went were what whatever when where whether which while white who whole whom
+	whose why will with within without woman women word words work world would
+	write year years yes yet you young your
+`)
+
+func wordSet(s string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(s) {
+		set[w] = true
+	}
+	return set
+}
+
+// readability is what `zippy analyze` reports about a text.
+type readability struct {
+	Words     int `json:"words"`
+	Sentences int `json:"sentences"`
+	Syllables int `json:"syllables"`
+	// Rare counts the words of four or more letters that are not among
+	// commonWords, leaving out names.
+	Rare     int     `json:"rare_words"`
+	Grade    float64 `json:"flesch_kincaid_grade"`
+	Language string  `json:"language,omitempty"`
+	WPM      int     `json:"recommended_wpm"`
+}
+
+func (r readability) sentenceLength() float64 {
+	return float64(r.Words) / float64(max(r.Sentences, 1))
+}
+
+func (r readability) rareRatio() float64 {
+	return float64(r.Rare) / float64(max(r.Words, 1))
+}
+
+// analyze measures the text's words, leaving out headings and anything
+// without letters, such as numbers and dashes.
+func analyze(words []token) readability {
+	var r readability
+	for _, tok := range words {
+		if tok.headingLine {
+			continue
+		}
+		word := strings.TrimFunc(tok.text, func(r rune) bool { return !unicode.IsLetter(r) })
+		if word == "" {
+			continue
+		}
+		r.Words++
+		if tok.sentenceStart() || r.Sentences == 0 {
+			r.Sentences++
+		}
+		r.Syllables += syllables(word)
+		lower := strings.ToLower(word)
+		name := !tok.sentenceStart() && unicode.IsUpper([]rune(word)[0])
+		if len([]rune(word)) >= 4 && !name && !common(lower) {
+			r.Rare++
+		}
+	}
+	if r.Words == 0 {
+		return r
+	}
+	r.Grade = 0.39*r.sentenceLength() + 11.8*float64(r.Syllables)/float64(r.Words) - 15.59
+	r.Grade = math.Round(max(r.Grade, 0)*10) / 10
+	r.Language = detectLanguage(words)
+	r.WPM = recommendedWPM(r.Grade, r.rareRatio())
+	return r
+}
+
+// common reports whether a word is among commonWords, also when inflected,
+// so that "walked" and "stories" count like "walk" and "story".
+func common(word string) bool {
+	if commonWords[word] {
+		return true
+	}
+	word = strings.TrimSuffix(word, "'s")
+	for _, suffix := range []string{"ies", "ing", "ed", "es", "s", "ly", "er", "est"} {
+		base, ok := strings.CutSuffix(word, suffix)
+		if !ok {
+			continue
+		}
+		if commonWords[base] || commonWords[base+"e"] || (suffix == "ies" && commonWords[base+"y"]) {
+			return true
+		}
+	}
+	return commonWords[word]
+}
+
+// syllables estimates an English word's syllables by its groups of vowels,
+// not counting a silent final e.
+func syllables(word string) int {
+	word = strings.ToLower(word)
+	n, vowel := 0, false
+	for _, r := range word {
+		v := strings.ContainsRune("aeiouy", r)
+		if v && !vowel {
+			n++
+		}
+		vowel = v
+	}
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && n > 1 {
+		n--
+	}
+	return max(n, 1)
+}
+
+// recommendedWPM suggests a starting speed for a text: 650 WPM, less 25 for
+// every grade level and 4 for every percent of rare words, kept between 200
+// and 600 and rounded to 25.
+func recommendedWPM(grade, rare float64) int {
+	wpm := 650 - 25*grade - 400*rare
+	wpm = min(max(wpm, 200), 600)
+	return int(math.Round(wpm/25)) * 25
+}
+
+func (r readability) write(w io.Writer) {
+	fmt.Fprintf(w, "Words:                 %s\n", formatCount(r.Words))
+	fmt.Fprintf(w, "Sentences:             %s\n", formatCount(r.Sentences))
+	if r.Words == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Average sentence:      %.1f words\n", r.sentenceLength())
+	fmt.Fprintf(w, "Syllables per word:    %.2f\n", float64(r.Syllables)/float64(r.Words))
+	fmt.Fprintf(w, "Flesch-Kincaid grade:  %.1f\n", r.Grade)
+	fmt.Fprintf(w, "Rare words:            %.0f%%\n", r.rareRatio()*100)
+	if r.Language != "" {
+		fmt.Fprintf(w, "Language:              %s\n", r.Language)
+	}
+	fmt.Fprintf(w, "Recommended speed:     %d WPM\n", r.WPM)
+	if r.Language != "" && r.Language != "English" {
+		fmt.Fprintln(w, "The grade, rare words and speed are measured as for English, so take them loosely.")
+	}
+}
+
+// runAnalyze implements `zippy analyze`, reporting how hard a text is to
+// read and a speed to start it at.
+func runAnalyze(args []string) int {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	var (
+		file   string
+		asJSON bool
+	)
+	fs.StringVar(&file, "file", "", "path to input text")
+	fs.BoolVar(&asJSON, "json", false, "print the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s analyze [options]\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Report the readability of the input and a recommended speed for it.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	text, err := readInput(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Provide input via -file or stdin.")
+		fs.PrintDefaults()
+		return 1
+	}
+	r := analyze(tokenize(text))
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	r.write(os.Stdout)
+	return 0
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestSyllables(t *testing.T) {
+	for word, want := range map[string]int{"cat": 1, "make": 1, "table": 2, "reading": 2, "Interpretive": 4, "rhythm": 1} {
+		if got := syllables(word); got != want {
+			t.Errorf("syllables(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestCommonWords(t *testing.T) {
+	for _, word := range []string{"walked", "stories", "making", "houses", "father's"} {
+		if !common(word) {
+			t.Errorf("expected %q to count as common", word)
+		}
+	}
+	if common("hermeneutics") {
+		t.Error("expected hermeneutics to count as rare")
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	easy := analyze(tokenize("# Chapter One\n\nThe cat sat on the mat. It was a cold day. Then Alice came home."))
+	if easy.Words != 15 || easy.Sentences != 3 {
+		t.Fatalf("expected 15 words in 3 sentences, leaving out the heading, got %d in %d", easy.Words, easy.Sentences)
+	}
+	if easy.Rare != 0 {
+		t.Fatalf("expected no rare words, names aside, got %d", easy.Rare)
+	}
+	hard := analyze(tokenize("Epistemological considerations notwithstanding, phenomenological hermeneutics necessitates reconceptualization."))
+	if hard.Grade <= easy.Grade || hard.WPM >= easy.WPM {
+		t.Fatalf("expected the harder text to get a higher grade and a lower speed, got %+v and %+v", easy, hard)
+	}
+	if hard.WPM != 200 {
+		t.Fatalf("expected the speed to bottom out at 200, got %d", hard.WPM)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bannerHeight is how many rows a banner letter takes.
+const bannerHeight = 5
+
+// bannerFont is a small block font for -banner. Letters are drawn in capitals
+// whatever the case of the text; runes it has no glyph for show as a box.
+var bannerFont = map[rune][bannerHeight]string{
+	'A':  {" ## ", "#  #", "####", "#  #", "#  #"},
+	'B':  {"### ", "#  #", "### ", "#  #", "### "},
+	'C':  {" ###", "#   ", "#   ", "#   ", " ###"},
+	'D':  {"### ", "#  #", "#  #", "#  #", "### "},
+	'E':  {"####", "#   ", "### ", "#   ", "####"},
+	'F':  {"####", "#   ", "### ", "#   ", "#   "},
+	'G':  {" ###", "#   ", "# ##", "#  #", " ###"},
+	'H':  {"#  #", "#  #", "####", "#  #", "#  #"},
+	'I':  {"###", " # ", " # ", " # ", "###"},
+	'J':  {"  ##", "   #", "   #", "#  #", " ## "},
+	'K':  {"#  #", "# # ", "##  ", "# # ", "#  #"},
+	'L':  {"#   ", "#   ", "#   ", "#   ", "####"},
+	'M':  {"#   #", "## ##", "# # #", "#   #", "#   #"},
+	'N':  {"#   #", "##  #", "# # #", "#  ##", "#   #"},
+	'O':  {" ## ", "#  #", "#  #", "#  #", " ## "},
+	'P':  {"### ", "#  #", "### ", "#   ", "#   "},
+	'Q':  {" ## ", "#  #", "#  #", "# ##", " ###"},
+	'R':  {"### ", "#  #", "### ", "# # ", "#  #"},
+	'S':  {" ###", "#   ", " ## ", "   #", "### "},
+	'T':  {"#####", "  #  ", "  #  ", "  #  ", "  #  "},
+	'U':  {"#  #", "#  #", "#  #", "#  #", " ## "},
+	'V':  {"#   #", "#   #", "#   #", " # # ", "  #  "},
+	'W':  {"#   #", "#   #", "# # #", "## ##", "#   #"},
+	'X':  {"#   #", " # # ", "  #  ", " # # ", "#   #"},
+	'Y':  {"#   #", " # # ", "  #  ", "  #  ", "  #  "},
+	'Z':  {"####", "   #", " ## ", "#   ", "####"},
+	'0':  {" ## ", "#  #", "#  #", "#  #", " ## "},
+	'1':  {" # ", "## ", " # ", " # ", "###"},
+	'2':  {"### ", "   #", " ## ", "#   ", "####"},
+	'3':  {"### ", "   #", " ## ", "   #", "### "},
+	'4':  {"#  #", "#  #", "####", "   #", "   #"},
+	'5':  {"####", "#   ", "### ", "   #", "### "},
+	'6':  {" ## ", "#   ", "### ", "#  #", " ## "},
+	'7':  {"####", "   #", "  # ", " #  ", " #  "},
+	'8':  {" ## ", "#  #", " ## ", "#  #", " ## "},
+	'9':  {" ## ", "#  #", " ###", "   #", " ## "},
+	' ':  {"  ", "  ", "  ", "  ", "  "},
+	'.':  {" ", " ", " ", " ", "#"},
+	',':  {"  ", "  ", "  ", " #", "# "},
+	'!':  {"#", "#", "#", " ", "#"},
+	'?':  {"### ", "   #", " ## ", "    ", " #  "},
+	':':  {" ", "#", " ", "#", " "},
+	';':  {"  ", " #", "  ", " #", "# "},
+	'\'': {"#", "#", " ", " ", " "},
+	'"':  {"# #", "# #", "   ", "   ", "   "},
+	'-':  {"   ", "   ", "###", "   ", "   "},
+	'(':  {" #", "# ", "# ", "# ", " #"},
+	')':  {"# ", " #", " #", " #", "# "},
+}
+
+var bannerUnknown = [bannerHeight]string{"###", "# #", "# #", "# #", "###"}
+
+func bannerGlyph(r rune) [bannerHeight]string {
+	if g, ok := bannerFont[unicode.ToUpper(r)]; ok {
+		return g
+	}
+	switch r {
+	case '“', '”':
+		return bannerFont['"']
+	case '‘', '’':
+		return bannerFont['\'']
+	case '—', '–':
+		return bannerFont['-']
+	}
+	return bannerUnknown
+}
+
+// formatBanner draws a frame in block letters with the pivot letter in the
+// pivot color, anchored at the pivot column like formatWord. It reports false
+// if the frame does not fit, so that the caller can fall back to plain text.
+func formatBanner(word string, width, height int, align alignment, t theme) (string, int, bool) {
+	runes := []rune(word)
+	if len(runes) == 0 || height < bannerHeight {
+		return "", 0, false
+	}
+	pivot := min(framePivot(runes), len(runes)-1)
+
+	var rows [bannerHeight]strings.Builder
+	leftWidth, pivotWidth, total := 0, 0, 0
+	for i, r := range runes {
+		g := bannerGlyph(r)
+		w := lipgloss.Width(g[0]) + 1
+		style := t.text()
+		if i == pivot {
+			style = t.pivot()
+			leftWidth, pivotWidth = total, w-1
+		}
+		for row := range rows {
+			rows[row].WriteString(style.Render(strings.ReplaceAll(g[row], "#", "█")))
+			rows[row].WriteString(t.text().Render(" "))
+		}
+		total += w
+	}
+	// Center the pivot glyph on the pivot column.
+	col := align.pivotColumn(width, leftWidth+pivotWidth/2)
+	leftPad := col - leftWidth - pivotWidth/2
+	if leftPad < 0 || leftPad+total-1 > width {
+		return "", 0, false
+	}
+	pad := t.text().Render(strings.Repeat(" ", leftPad))
+	lines := make([]string, bannerHeight)
+	for row := range rows {
+		lines[row] = pad + rows[row].String()
+	}
+	return strings.Join(lines, "\n"), col, true
+}
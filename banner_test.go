@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestBannerGlyphsAreRectangular(t *testing.T) {
+	for r, g := range bannerFont {
+		for _, row := range g {
+			if len(row) != len(g[0]) {
+				t.Errorf("glyph %q has rows of different widths: %q", r, g)
+				break
+			}
+		}
+	}
+}
+
+func TestFormatBanner(t *testing.T) {
+	out, col, ok := formatBanner("hi", 30, 10, alignORP, theme{})
+	if !ok {
+		t.Fatal("expected hi to fit")
+	}
+	lines := strings.Split(ansi.Strip(out), "\n")
+	want := []string{
+		"    #  # ### ",
+		"    #  #  #  ",
+		"    ####  #  ",
+		"    #  #  #  ",
+		"    #  # ### ",
+	}
+	for i := range want {
+		if lines[i] != strings.ReplaceAll(want[i], "#", "█") {
+			t.Fatalf("unexpected banner:\n%s", strings.Join(lines, "\n"))
+		}
+	}
+	// The pivot is the I, whose middle sits on the pivot column.
+	if col != 10 || []rune(lines[1])[col] != '█' {
+		t.Fatalf("expected the pivot glyph centered on column 10, got %d", col)
+	}
+	if _, _, ok := formatBanner("extraordinarily", 30, 10, alignORP, theme{}); ok {
+		t.Fatal("expected a long word not to fit")
+	}
+	if _, _, ok := formatBanner("hi", 30, 4, alignORP, theme{}); ok {
+		t.Fatal("expected no banner without room for it")
+	}
+}
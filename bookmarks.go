@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// toggleBookmark drops a bookmark at the current word, or removes the one
+// already there, and persists the result.
+func (m *model) toggleBookmark() {
+	if m.docKey == "" {
+		m.notice = "Bookmarks need file input"
+		return
+	}
+	pos := m.stream.Pos()
+	if i, found := slices.BinarySearch(m.bookmarks, pos); found {
+		m.bookmarks = slices.Delete(m.bookmarks, i, i+1)
+		m.notice = fmt.Sprintf("Removed bookmark at word %d", pos+1)
+	} else {
+		m.bookmarks = slices.Insert(m.bookmarks, i, pos)
+		m.notice = fmt.Sprintf("Bookmarked word %d", pos+1)
+		if err := m.startHook("bookmark", "ZIPPY_HOOK_BOOKMARK="+strconv.Itoa(pos+1)); err != nil {
+			m.notice = fmt.Sprintf("Could not run the bookmark hook: %v", err)
+		}
+	}
+	m.saveBookmarks()
+}
+
+func (m *model) saveBookmarks() {
+	bookmarks := slices.Clone(m.bookmarks)
+	if err := updateDoc(m.docKey, func(d *docState) { d.Bookmarks = bookmarks }); err != nil {
+		m.notice = fmt.Sprintf("Could not save bookmarks: %v", err)
+	}
+}
+
+// nextBookmark jumps to the first bookmark after the current word, wrapping
+// around to the first one.
+func (m *model) nextBookmark() {
+	if len(m.bookmarks) == 0 {
+		m.notice = "No bookmarks"
+		return
+	}
+	pos := m.stream.Pos()
+	target := m.bookmarks[0]
+	for _, b := range m.bookmarks {
+		if b > pos {
+			target = b
+			break
+		}
+	}
+	m.stream.Seek(target)
+	i := slices.Index(m.bookmarks, target)
+	m.notice = fmt.Sprintf("Bookmark %d/%d", i+1, len(m.bookmarks))
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// minBoxWidth is the narrowest -box that leaves room for a word.
+const minBoxWidth = 10
+
+// boxFits reports whether the -box frame fits in the word area.
+func (m model) boxFits(contentHeight int) bool {
+	return m.boxWidth > 0 && m.boxWidth <= m.width && contentHeight >= 3
+}
+
+// boxed draws block inside a rounded border inner columns wide. The reticle,
+// if on, becomes ticks in the top and bottom border at column col.
+func (m model) boxed(block string, col, inner int) string {
+	border := m.theme.dim()
+	top, bottom := "╭"+strings.Repeat("─", inner)+"╮", "╰"+strings.Repeat("─", inner)+"╯"
+	if m.reticle != reticleOff && col >= 0 && col < inner {
+		left, right := strings.Repeat("─", col), strings.Repeat("─", inner-col-1)
+		top, bottom = "╭"+left+"┬"+right+"╮", "╰"+left+"┴"+right+"╯"
+	}
+	lines := strings.Split(block, "\n")
+	for i, line := range lines {
+		line = truncate(line, inner)
+		pad := m.theme.text().Render(strings.Repeat(" ", inner-lipgloss.Width(line)))
+		lines[i] = border.Render("│") + line + pad + border.Render("│")
+	}
+	return border.Render(top) + "\n" + strings.Join(lines, "\n") + "\n" + border.Render(bottom)
+}
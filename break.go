@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// breakDue reports whether playback has gone on long enough without a pause
+// for -break-every or -break-words to call for a break.
+func (m model) breakDue() bool {
+	if !m.running {
+		return false
+	}
+	return (m.breakEvery > 0 && m.since(m.playingSince) >= m.breakEvery) ||
+		(m.breakWords > 0 && m.wordsRead-m.stretchStart >= m.breakWords)
+}
+
+// takeBreak pauses playback behind the break screen.
+func (m *model) takeBreak() {
+	m.stretch = m.since(m.playingSince)
+	m.stretchWords = m.wordsRead - m.stretchStart
+	m.setRunning(false)
+	m.onBreak = true
+}
+
+// handleBreakKey ends a break, carrying on reading with any key but quit,
+// which asks first as it does while reading.
+func (m *model) handleBreakKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "ctrl+c" {
+		return tea.Quit
+	}
+	m.onBreak = false
+	if key.Matches(msg, m.keys().Quit) {
+		return m.quit()
+	}
+	return m.togglePlay()
+}
+
+func (m model) breakScreen() string {
+	text := fmt.Sprintf("Take a break\n\nYou have read %s in %s without stopping.\nLook away from the screen for a while.\n\nPress any key to carry on.",
+		formatCount(m.stretchWords)+" words", m.stretch.Round(time.Second))
+	return m.theme.place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.theme.text().Render(text))
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// caseMode is how words are cased on screen. Only the display changes: the
+// tokens, and so searching, keep the document's own casing.
+type caseMode int
+
+const (
+	caseAsIs caseMode = iota
+	caseLower
+	caseUpper
+	caseSmallCaps
+)
+
+var caseNames = []string{"as-is", "lower", "upper", "small-caps"}
+
+func (c caseMode) String() string { return caseNames[c] }
+
+func parseCase(s string) (caseMode, error) {
+	for i, name := range caseNames {
+		if s == name {
+			return caseMode(i), nil
+		}
+	}
+	return caseAsIs, fmt.Errorf("unknown case %q; use as-is, lower, upper or small-caps", s)
+}
+
+// smallCaps maps lowercase letters to their small capital forms. Unicode has
+// none for x, which is left alone.
+var smallCaps = map[rune]rune{
+	'a': 'ᴀ', 'b': 'ʙ', 'c': 'ᴄ', 'd': 'ᴅ', 'e': 'ᴇ', 'f': 'ꜰ', 'g': 'ɢ',
+	'h': 'ʜ', 'i': 'ɪ', 'j': 'ᴊ', 'k': 'ᴋ', 'l': 'ʟ', 'm': 'ᴍ', 'n': 'ɴ',
+	'o': 'ᴏ', 'p': 'ᴘ', 'q': 'ǫ', 'r': 'ʀ', 's': 'ꜱ', 't': 'ᴛ', 'u': 'ᴜ',
+	'v': 'ᴠ', 'w': 'ᴡ', 'y': 'ʏ', 'z': 'ᴢ',
+}
+
+// apply recases s rune by rune, so that the pivot stays on the same letter.
+func (c caseMode) apply(s string) string {
+	switch c {
+	case caseLower:
+		return strings.Map(unicode.ToLower, s)
+	case caseUpper:
+		return strings.Map(unicode.ToUpper, s)
+	case caseSmallCaps:
+		return strings.Map(func(r rune) rune {
+			if sc, ok := smallCaps[r]; ok {
+				return sc
+			}
+			return r
+		}, s)
+	}
+	return s
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// cleanOptions say what `zippy clean` clears, and for which documents.
+type cleanOptions struct {
+	positions bool
+	bookmarks bool
+	words     bool
+	settings  bool
+	stats     bool
+	// missing forgets everything about documents whose file is gone.
+	missing bool
+	// cache removes temp files left behind by a crash.
+	cache bool
+	// olderThan and file narrow the clean down to documents and sessions
+	// last read at least that long ago, or to one file.
+	olderThan time.Duration
+	file      string
+}
+
+// applies reports whether the options reach a document last read at when;
+// one never read counts as old.
+func (o cleanOptions) applies(key string, when, now time.Time) bool {
+	if o.file != "" && key != o.file {
+		return false
+	}
+	return o.olderThan == 0 || when.IsZero() || now.Sub(when) >= o.olderThan
+}
+
+func fileGone(path string) bool {
+	_, err := os.Stat(path)
+	return err != nil
+}
+
+// empty reports whether nothing worth keeping is left of a document's state.
+func (d docState) empty() bool {
+	return d.Hash == "" && len(d.Bookmarks) == 0 && len(d.Unknown) == 0 && d.WordsRead == 0 && d.WPM == 0 && d.Chunk == 0 && d.Profile == ""
+}
+
+// cleanDocs clears what the options ask for in the document store and
+// returns how many documents it changed. A document left with nothing
+// remembered is dropped.
+func cleanDocs(store *docStore, o cleanOptions, now time.Time) int {
+	changed := 0
+	for key, d := range store.Documents {
+		if !o.applies(key, d.LastRead, now) {
+			continue
+		}
+		if o.missing && fileGone(key) {
+			delete(store.Documents, key)
+			changed++
+			continue
+		}
+		before := *d
+		if o.positions {
+			d.Position, d.Hash, d.Total, d.Context = 0, "", 0, nil
+		}
+		if o.bookmarks {
+			d.Bookmarks, d.HighlightsSent = nil, nil
+		}
+		if o.words {
+			d.Unknown = nil
+		}
+		if o.settings {
+			d.WPM, d.Chunk, d.Profile = 0, 0, ""
+		}
+		if o.stats {
+			d.WordsRead, d.ReadTime = 0, 0
+		}
+		if reflect.DeepEqual(before, *d) {
+			continue
+		}
+		changed++
+		if d.empty() {
+			delete(store.Documents, key)
+		}
+	}
+	return changed
+}
+
+// cleanSessions returns the sessions the options leave in the statistics
+// store. Sessions of piped text only go with -stats and no -file.
+func cleanSessions(sessions []session, o cleanOptions, now time.Time) []session {
+	var kept []session
+	for _, s := range sessions {
+		reached := o.applies(s.Document, s.Start, now)
+		if reached && (o.stats || (o.missing && s.Document != "" && fileGone(s.Document))) {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	return kept
+}
+
+// saveSessions rewrites the statistics store with the given sessions. The
+// caller holds lockState.
+func saveSessions(sessions []session) error {
+	path, err := statsPath()
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, s := range sessions {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// staleTempFiles lists the files a crash can leave behind: spooled input in
+// the cache directory and half-written stores in the data directory. Files
+// touched within the last day may belong to a zippy that is still running.
+func staleTempFiles(o cleanOptions, now time.Time) []string {
+	age := max(o.olderThan, 24*time.Hour)
+	var patterns []string
+	if dir, err := cacheDir(); err == nil {
+		patterns = append(patterns, filepath.Join(dir, "zippy-spool-*"))
+	}
+	patterns = append(patterns, filepath.Join(os.TempDir(), "zippy-spool-*"))
+	if dir, err := dataDir(); err == nil {
+		patterns = append(patterns, filepath.Join(dir, "*.tmp"))
+	}
+	var stale []string
+	for _, p := range patterns {
+		matches, _ := filepath.Glob(p)
+		for _, path := range matches {
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() && now.Sub(info.ModTime()) >= age {
+				stale = append(stale, path)
+			}
+		}
+	}
+	return stale
+}
+
+// runClean implements `zippy clean`, which forgets saved state selectively
+// so that the data directory does not grow forever.
+func runClean(args []string) int {
+	fs := flag.NewFlagSet("clean", flag.ExitOnError)
+	var (
+		o                   cleanOptions
+		all, dryRun         bool
+		olderThan, fileName string
+	)
+	fs.BoolVar(&o.positions, "positions", false, "clear saved reading positions")
+	fs.BoolVar(&o.bookmarks, "bookmarks", false, "clear bookmarks")
+	fs.BoolVar(&o.words, "words", false, "clear the words flagged as unknown")
+	fs.BoolVar(&o.settings, "settings", false, "clear the speed, chunk size and profile remembered per document")
+	fs.BoolVar(&o.stats, "stats", false, "clear reading statistics: recorded sessions and per-document totals")
+	fs.BoolVar(&o.missing, "missing", false, "forget documents whose file no longer exists, and their sessions")
+	fs.BoolVar(&o.cache, "cache", false, "remove temp files left behind by a crash")
+	fs.BoolVar(&all, "all", false, "clear everything the other options name")
+	fs.StringVar(&olderThan, "older-than", "", "only clear documents and sessions last read at least this long ago, e.g. 90d or 12w")
+	fs.StringVar(&fileName, "file", "", "only clear what is kept for this file")
+	fs.BoolVar(&dryRun, "dry-run", false, "say what would be cleared without clearing it")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s clean [options]\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Clear saved state selectively. Say what to clear, and optionally for what, e.g.")
+		fmt.Fprintln(os.Stderr, "  zippy clean -positions -older-than 90d")
+		fmt.Fprintln(os.Stderr, "  zippy clean -all -file book.txt")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	if all {
+		o.positions, o.bookmarks, o.words, o.settings, o.stats, o.missing, o.cache = true, true, true, true, true, true, true
+	}
+	if !o.positions && !o.bookmarks && !o.words && !o.settings && !o.stats && !o.missing && !o.cache {
+		fs.Usage()
+		return 2
+	}
+	if olderThan != "" {
+		d, err := parseSince(olderThan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Bad -older-than %q: use e.g. 90d, 12w or 48h.\n", olderThan)
+			return 2
+		}
+		o.olderThan = d
+	}
+	o.file = docKey(fileName)
+
+	now := time.Now()
+	unlock, err := lockState()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not lock saved state:", err)
+		return 1
+	}
+	defer unlock()
+	store, err := loadDocStore()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read saved state:", err)
+		return 1
+	}
+	sessions, err := loadSessions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read statistics:", err)
+		return 1
+	}
+	docs := cleanDocs(store, o, now)
+	kept := cleanSessions(sessions, o, now)
+	var stale []string
+	if o.cache && o.file == "" {
+		stale = staleTempFiles(o, now)
+	}
+
+	verb := "Cleared"
+	if dryRun {
+		verb = "Would clear"
+	}
+	fmt.Printf("%s %s, %s and %s.\n", verb, countNoun(docs, "document"), countNoun(len(sessions)-len(kept), "session"), countNoun(len(stale), "temp file"))
+	if dryRun {
+		return 0
+	}
+	status := 0
+	if docs > 0 {
+		if err := store.save(); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not save state:", err)
+			status = 1
+		}
+	}
+	if len(kept) < len(sessions) {
+		if err := saveSessions(kept); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not save statistics:", err)
+			status = 1
+		}
+	}
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not remove temp file:", err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// countNoun says how many of something there are, e.g. "1 session" or
+// "3 sessions".
+func countNoun(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
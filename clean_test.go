@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCleanDocs(t *testing.T) {
+	dir := t.TempDir()
+	old, recent := filepath.Join(dir, "old.txt"), filepath.Join(dir, "recent.txt")
+	for _, path := range []string{old, recent} {
+		if err := os.WriteFile(path, []byte("words"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	newStore := func() *docStore {
+		return &docStore{Documents: map[string]*docState{
+			old:                 {Position: 9, Hash: "a", LastRead: now.Add(-100 * 24 * time.Hour), Bookmarks: []int{3}},
+			recent:              {Position: 4, Hash: "b", LastRead: now.Add(-time.Hour), WPM: 400},
+			"/gone/deleted.txt": {Position: 2, Hash: "c", LastRead: now},
+		}}
+	}
+
+	store := newStore()
+	if n := cleanDocs(store, cleanOptions{positions: true, olderThan: 90 * 24 * time.Hour}, now); n != 1 {
+		t.Fatalf("expected one old document to be cleared, got %d", n)
+	}
+	if d := store.Documents[old]; d == nil || d.Hash != "" || len(d.Bookmarks) != 1 {
+		t.Fatalf("expected the old position to go and its bookmark to stay, got %+v", d)
+	}
+	if store.Documents[recent].Hash != "b" {
+		t.Fatal("expected the recent position to stay")
+	}
+
+	store = newStore()
+	cleanDocs(store, cleanOptions{positions: true, file: recent}, now)
+	if d := store.Documents[recent]; d == nil || d.Hash != "" || d.WPM != 400 {
+		t.Fatalf("expected only the position of recent.txt to go, got %+v", d)
+	}
+	if store.Documents[old].Hash != "a" {
+		t.Fatal("expected other files to be left alone")
+	}
+
+	store = newStore()
+	cleanDocs(store, cleanOptions{positions: true, bookmarks: true}, now)
+	if _, ok := store.Documents[old]; ok {
+		t.Fatal("expected a document with nothing left to be dropped")
+	}
+
+	store = newStore()
+	if n := cleanDocs(store, cleanOptions{missing: true}, now); n != 1 || len(store.Documents) != 2 {
+		t.Fatalf("expected only the deleted file to be forgotten, got %d and %v", n, store.Documents)
+	}
+}
+
+func TestCleanSessions(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sessions := []session{
+		{Document: "/gone/a.txt", Start: now.Add(-40 * 24 * time.Hour), Words: 100},
+		{Document: "", Start: now.Add(-40 * 24 * time.Hour), Words: 100},
+		{Document: "/gone/b.txt", Start: now.Add(-time.Hour), Words: 100},
+	}
+	if kept := cleanSessions(sessions, cleanOptions{stats: true, olderThan: 30 * 24 * time.Hour}, now); len(kept) != 1 || kept[0].Document != "/gone/b.txt" {
+		t.Fatalf("expected only the recent session to stay, got %v", kept)
+	}
+	if kept := cleanSessions(sessions, cleanOptions{stats: true, file: "/gone/b.txt"}, now); len(kept) != 2 {
+		t.Fatalf("expected only b.txt's session to go, got %v", kept)
+	}
+	if kept := cleanSessions(sessions, cleanOptions{missing: true}, now); len(kept) != 1 || kept[0].Document != "" {
+		t.Fatalf("expected sessions of deleted files to go and piped text to stay, got %v", kept)
+	}
+	if kept := cleanSessions(sessions, cleanOptions{positions: true}, now); len(kept) != 3 {
+		t.Fatalf("expected sessions to stay unless asked, got %v", kept)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// clock is where the model gets the time from and schedules its ticks with,
+// so that playback can be driven without waiting in tests.
+type clock interface {
+	Now() time.Time
+	// Tick returns a command that sends fn's message once d has passed.
+	Tick(d time.Duration, fn func(time.Time) tea.Msg) tea.Cmd
+}
+
+// wallClock is the real time.
+type wallClock struct{}
+
+func (wallClock) Now() time.Time { return time.Now() }
+
+func (wallClock) Tick(d time.Duration, fn func(time.Time) tea.Msg) tea.Cmd {
+	return tea.Tick(d, fn)
+}
+
+// now is the time on the model's clock, the wall clock if it was not given
+// one.
+func (m model) now() time.Time {
+	if m.clock == nil {
+		return time.Now()
+	}
+	return m.clock.Now()
+}
+
+func (m model) since(t time.Time) time.Duration {
+	return m.now().Sub(t)
+}
+
+func (m model) tick(d time.Duration, fn func(time.Time) tea.Msg) tea.Cmd {
+	if m.clock == nil {
+		return wallClock{}.Tick(d, fn)
+	}
+	return m.clock.Tick(d, fn)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeClock only moves when told to. Its ticks fire as soon as they are
+// run, moving the clock on by their interval first.
+type fakeClock struct {
+	t time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time { return c.t }
+
+func (c *fakeClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func (c *fakeClock) Tick(d time.Duration, fn func(time.Time) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		c.Advance(d)
+		return fn(c.t)
+	}
+}
+
+// step runs cmd and feeds its message back to the model.
+func step(t *testing.T, m model, cmd tea.Cmd) (model, tea.Cmd) {
+	t.Helper()
+	next, cmd := m.Update(runCmd(t, cmd))
+	return next.(model), cmd
+}
+
+func TestPlaybackOnFakeClock(t *testing.T) {
+	c := newFakeClock()
+	m := model{stream: newEagerStream(tokenize("one two three four five six"), false), pacing: pacing{wpm: 60}, clock: c, markA: -1, markB: -1}
+	next, cmd := m.Update(keyMsg(" "))
+	m = next.(model)
+	start := c.Now()
+	for range 3 {
+		m, cmd = step(t, m, cmd)
+	}
+	if m.stream.Pos() != 3 {
+		t.Fatalf("expected three words read, at %d", m.stream.Pos())
+	}
+	if played := m.playedFor(); played != c.Now().Sub(start) || played < 3*time.Second {
+		t.Fatalf("expected to have played for the time the ticks took, got %v", played)
+	}
+	if s := m.frameStats; s.frames != 3 || s.dropped != 0 || s.actual != s.scheduled {
+		t.Fatalf("expected frames exactly on time, got %+v", s)
+	}
+
+	// Time spent paused does not count.
+	played := m.playedFor()
+	m = press(m, " ")
+	c.Advance(time.Hour)
+	if m.playedFor() != played {
+		t.Fatalf("expected a pause to stop the clock, got %v after %v", m.playedFor(), played)
+	}
+	next, cmd = m.Update(keyMsg(" "))
+	m = next.(model)
+	c.Advance(time.Second)
+	if m.playedFor() != played+time.Second {
+		t.Fatalf("expected playing to count again, got %v", m.playedFor())
+	}
+	if m, _ = step(t, m, cmd); m.stream.Pos() != 4 {
+		t.Fatalf("expected playback to carry on, at %d", m.stream.Pos())
+	}
+}
+
+func TestBreakAfterTimeOnFakeClock(t *testing.T) {
+	c := newFakeClock()
+	m := model{stream: newEagerStream(tokenize("a b c d e f g h"), false), pacing: pacing{wpm: 60}, clock: c, breakEvery: 2500 * time.Millisecond, markA: -1, markB: -1}
+	next, cmd := m.Update(keyMsg(" "))
+	m = next.(model)
+	for !m.onBreak && cmd != nil {
+		m, cmd = step(t, m, cmd)
+	}
+	if !m.onBreak || m.stretch < 2500*time.Millisecond || m.stretch > 4*time.Second {
+		t.Fatalf("expected a break about 2.5s in, got break %v after %v", m.onBreak, m.stretch)
+	}
+}
+
+func TestResumeAfterTimeBudgetOnFakeClock(t *testing.T) {
+	c := newFakeClock()
+	m := model{stream: newEagerStream(tokenize("a b c d e f g h i j"), false), pacing: pacing{wpm: 60}, clock: c, duration: 2 * time.Second, onLimit: limitPause, markA: -1, markB: -1}
+	next, cmd := m.Update(keyMsg(" "))
+	m = next.(model)
+	for m.running {
+		m, cmd = step(t, m, cmd)
+	}
+	if m.limitReached == "" || m.stream.Pos() != 1 {
+		t.Fatalf("expected the budget to stop playback at word 2, got %q at %d", m.limitReached, m.stream.Pos())
+	}
+
+	next, cmd = m.Update(keyMsg(" "))
+	m = next.(model)
+	if m, cmd = step(t, m, cmd); !m.running || m.limitReached != "" {
+		t.Fatal("expected playing on past the limit to carry on reading")
+	}
+	for m.running {
+		m, cmd = step(t, m, cmd)
+	}
+	if m.limitReached == "" || m.stream.Pos() != 2 {
+		t.Fatalf("expected another budget of the same size, stopped at %d", m.stream.Pos())
+	}
+}
+
+func TestResumeAfterMaxWordsOnFakeClock(t *testing.T) {
+	c := newFakeClock()
+	m := model{stream: newEagerStream(tokenize("a b c d e f g h i j"), false), pacing: pacing{wpm: 60, chunk: 1}, clock: c, maxWords: 3, onLimit: limitPause, markA: -1, markB: -1}
+	next, cmd := m.Update(keyMsg(" "))
+	m = next.(model)
+	for m.running {
+		m, cmd = step(t, m, cmd)
+	}
+	if m.limitReached == "" || m.wordsRead != 3 {
+		t.Fatalf("expected to stop after 3 words, got %q after %d", m.limitReached, m.wordsRead)
+	}
+
+	next, cmd = m.Update(keyMsg(" "))
+	m = next.(model)
+	if m, cmd = step(t, m, cmd); !m.running || m.limitReached != "" {
+		t.Fatal("expected playing on past the limit to carry on reading")
+	}
+	for m.running {
+		m, cmd = step(t, m, cmd)
+	}
+	if m.limitReached == "" || m.wordsRead != 6 {
+		t.Fatalf("expected another 3 words, stopped after %d", m.wordsRead)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// command is one of zippy's subcommands.
+type command struct {
+	name string
+	desc string
+	run  func(args []string) int
+}
+
+// subcommands lists the commands zippy knows, in the order usage shows them.
+func subcommands() []command {
+	return []command{
+		{"read", "read a file or piped text word by word (the default)", func(args []string) int { return runRead(args, pickNone) }},
+		// `zippy list` and `zippy history` take the reader's options,
+		// which apply once a document has been picked.
+		{"list", "continue a document with a saved position", func(args []string) int { return runRead(args, pickSaved) }},
+		{"history", "pick a recently read document to resume", func(args []string) int { return runRead(args, pickHistory) }},
+		{"plan", "print the reading schedule without playing", runPlan},
+		{"estimate", "print how long documents take to read", runEstimate},
+		{"analyze", "report how hard a text is to read and a speed to start it at", runAnalyze},
+		{"replay", "play back a session recorded with -timing-log", runReplay},
+		{"status", "print a status line about what zippy is reading, e.g. for tmux", runStatus},
+		{"words", "list the words flagged as unknown while reading", runWords},
+		{"stats", "report how much and how fast you have been reading", runStats},
+		{"clean", "clear saved positions, bookmarks, statistics or temp files", runClean},
+		{"keys", "print the key bindings", runKeys},
+		{"setup", "choose the starting speed, theme and keys", runSetup},
+		{"completion", "print a shell completion script", runCompletion},
+	}
+}
+
+// run dispatches to the subcommand named by the first argument. Anything
+// else is read, so `zippy book.txt` and `zippy -wpm 400 -file book.txt` are
+// short for `zippy read ...`.
+func run(args []string) int {
+	if len(args) > 0 {
+		for _, c := range subcommands() {
+			if c.name == args[0] {
+				return c.run(args[1:])
+			}
+		}
+		if unknownCommand(args[0]) {
+			fmt.Fprintf(os.Stderr, "Unknown command %q; see %s -h.\n", args[0], os.Args[0])
+			return 2
+		}
+	}
+	return runRead(args, pickNone)
+}
+
+// unknownCommand tells a mistyped command from a file to read: it is not an
+// option, does not exist and does not look like a path.
+func unknownCommand(arg string) bool {
+	if strings.HasPrefix(arg, "-") || strings.ContainsAny(arg, `./\`) {
+		return false
+	}
+	_, err := os.Stat(arg)
+	return err != nil
+}
+
+// usage describes the commands, and then the options of fs.
+func usage(fs *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: %s [read] [options] [FILE]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s <command> [options]\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, c := range subcommands() {
+		fmt.Fprintf(os.Stderr, "  %-12s%s\n", c.name, c.desc)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Input can be a FILE, given with -file or piped into stdin.")
+	fmt.Fprintln(os.Stderr, "Each command has its own options; these are those of read and list:")
+	fmt.Fprintln(os.Stderr)
+	fs.PrintDefaults()
+}
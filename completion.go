@@ -0,0 +1,198 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// completionFlag is a reader option as shell completion sees it.
+type completionFlag struct {
+	name string
+	desc string
+	// takesValue is false for flags given on their own, like -yes.
+	takesValue bool
+}
+
+func completionFlags(fs *flag.FlagSet) []completionFlag {
+	var flags []completionFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		desc, _, _ := strings.Cut(f.Usage, ";")
+		b, isBool := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{
+			name:       f.Name,
+			desc:       strings.TrimSpace(desc),
+			takesValue: !isBool || !b.IsBoolFlag(),
+		})
+	})
+	return flags
+}
+
+// runCompletion implements `zippy completion`. With a shell's name it
+// prints the script that teaches the shell zippy's subcommands and options;
+// the scripts then call `zippy completion themes` and `zippy completion
+// documents` for the theme names and saved documents as they are at the time.
+func runCompletion(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s completion bash|zsh|fish\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Print a shell completion script, e.g. for ~/.bashrc:")
+		fmt.Fprintln(os.Stderr, "  source <(zippy completion bash)")
+		return 2
+	}
+	fs, _ := readFlags()
+	flags := completionFlags(fs)
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(os.Stdout, flags)
+	case "zsh":
+		writeZshCompletion(os.Stdout, flags)
+	case "fish":
+		writeFishCompletion(os.Stdout, flags)
+	case "themes":
+		cfg, err := loadConfig()
+		if err != nil {
+			return 1
+		}
+		for _, name := range themeNames(cfg) {
+			fmt.Println(name)
+		}
+	case "documents":
+		store, err := loadDocStore()
+		if err != nil {
+			return 1
+		}
+		for _, e := range docEntries(store, time.Now()) {
+			fmt.Println(e.path)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "No completion for %q; use bash, zsh or fish.\n", args[0])
+		return 2
+	}
+	return 0
+}
+
+func subcommandNames() string {
+	var names []string
+	for _, c := range subcommands() {
+		names = append(names, c.name)
+	}
+	return strings.Join(names, " ")
+}
+
+func writeBashCompletion(w io.Writer, flags []completionFlag) {
+	var all, valued []string
+	for _, f := range flags {
+		all = append(all, "-"+f.name)
+		if f.takesValue && f.name != "file" && f.name != "theme" {
+			valued = append(valued, "-"+f.name)
+		}
+	}
+	fmt.Fprintf(w, `# bash completion for zippy
+_zippy() {
+	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}
+	local IFS=$'\n'
+	case $prev in
+	-file)
+		COMPREPLY=($(compgen -W "$(zippy completion documents 2>/dev/null)" -- "$cur") $(compgen -f -- "$cur"))
+		return
+		;;
+	-theme)
+		COMPREPLY=($(compgen -W "$(zippy completion themes 2>/dev/null)" -- "$cur"))
+		return
+		;;
+	%s)
+		return
+		;;
+	completion)
+		COMPREPLY=($(compgen -W $'bash\nzsh\nfish' -- "$cur"))
+		return
+		;;
+	esac
+	if [[ $COMP_CWORD -eq 1 && $cur != -* ]]; then
+		COMPREPLY=($(compgen -W $'%s' -- "$cur"))
+		return
+	fi
+	COMPREPLY=($(compgen -W $'%s' -- "$cur"))
+}
+complete -o filenames -F _zippy zippy
+`, strings.Join(valued, "|"), strings.ReplaceAll(subcommandNames(), " ", `\n`), strings.Join(all, `\n`))
+}
+
+// zshQuote escapes an option description for _arguments.
+func zshQuote(s string) string {
+	return strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`, ":", `\:`).Replace(s)
+}
+
+func writeZshCompletion(w io.Writer, flags []completionFlag) {
+	fmt.Fprintln(w, "#compdef zippy")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "_zippy_documents() {")
+	fmt.Fprintln(w, "\tlocal -a docs")
+	fmt.Fprintln(w, "\tdocs=(${(f)\"$(zippy completion documents 2>/dev/null)\"})")
+	fmt.Fprintln(w, "\t_alternative 'documents:saved document:compadd -a docs' 'files:file:_files'")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "_zippy_themes() {")
+	fmt.Fprintln(w, "\tlocal -a themes")
+	fmt.Fprintln(w, "\tthemes=(${(f)\"$(zippy completion themes 2>/dev/null)\"})")
+	fmt.Fprintln(w, "\tcompadd -a themes")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "_zippy() {")
+	fmt.Fprintln(w, "\tlocal -a commands")
+	fmt.Fprintln(w, "\tcommands=(")
+	for _, c := range subcommands() {
+		fmt.Fprintf(w, "\t\t'%s:%s'\n", c.name, zshQuote(c.desc))
+	}
+	fmt.Fprintln(w, "\t)")
+	fmt.Fprintln(w, "\tif [[ $words[2] == completion ]]; then")
+	fmt.Fprintln(w, "\t\t_values shell bash zsh fish")
+	fmt.Fprintln(w, "\t\treturn")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w, "\t_arguments \\")
+	for _, f := range flags {
+		spec := fmt.Sprintf("-%s[%s]", f.name, zshQuote(f.desc))
+		switch {
+		case f.name == "file":
+			spec += ":file:_zippy_documents"
+		case f.name == "theme":
+			spec += ":theme:_zippy_themes"
+		case f.takesValue:
+			spec += ":" + f.name + ": "
+		}
+		fmt.Fprintf(w, "\t\t'%s' \\\n", spec)
+	}
+	fmt.Fprintln(w, "\t\t'1:: :{_describe command commands}'")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, `_zippy "$@"`)
+}
+
+// fishQuote quotes s as a single-quoted fish string.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+func writeFishCompletion(w io.Writer, flags []completionFlag) {
+	fmt.Fprintln(w, "# fish completion for zippy")
+	fmt.Fprintln(w, "complete -c zippy -f")
+	for _, c := range subcommands() {
+		fmt.Fprintf(w, "complete -c zippy -n __fish_use_subcommand -a %s -d %s\n", c.name, fishQuote(c.desc))
+	}
+	fmt.Fprintln(w, "complete -c zippy -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'")
+	for _, f := range flags {
+		line := fmt.Sprintf("complete -c zippy -o %s -d %s", f.name, fishQuote(f.desc))
+		switch {
+		case f.name == "file":
+			line += " -r -F -a '(zippy completion documents 2>/dev/null)'"
+		case f.name == "theme":
+			line += " -x -a '(zippy completion themes 2>/dev/null)'"
+		case f.takesValue:
+			line += " -x"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestCompletionScripts(t *testing.T) {
+	fs := flag.NewFlagSet("zippy", flag.ContinueOnError)
+	fs.String("file", "", "path to input text")
+	fs.String("theme", "", "color theme")
+	fs.Int("wpm", 300, "words per minute; 50 to 1200")
+	fs.Bool("yes", false, "quit without asking [really]")
+	flags := completionFlags(fs)
+	if len(flags) != 4 || flags[2].name != "wpm" || flags[2].desc != "words per minute" || !flags[2].takesValue || flags[3].takesValue {
+		t.Fatalf("unexpected flags %+v", flags)
+	}
+
+	var bash, zsh, fish strings.Builder
+	writeBashCompletion(&bash, flags)
+	writeZshCompletion(&zsh, flags)
+	writeFishCompletion(&fish, flags)
+	for _, want := range []string{`-file\n-theme\n-wpm\n-yes`, "\t-wpm)", "zippy completion themes", "read\\nlist"} {
+		if !strings.Contains(bash.String(), want) {
+			t.Errorf("expected %q in the bash script", want)
+		}
+	}
+	for _, want := range []string{"'-wpm[words per minute]:wpm: '", `'-yes[quit without asking \[really\]]'`, "-file[path to input text]:file:_zippy_documents"} {
+		if !strings.Contains(zsh.String(), want) {
+			t.Errorf("expected %q in the zsh script", want)
+		}
+	}
+	for _, want := range []string{"-o wpm -d 'words per minute' -x", "-o yes -d 'quit without asking [really]'\n", "-a '(zippy completion documents 2>/dev/null)'"} {
+		if !strings.Contains(fish.String(), want) {
+			t.Errorf("expected %q in the fish script", want)
+		}
+	}
+}
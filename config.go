@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// config is the optional config file, JSON at
+// $XDG_CONFIG_HOME/zippy/config.json (or the platform's equivalent), or
+// wherever $ZIPPY_CONFIG says. Command-line flags and the environment take
+// precedence over it.
+type config struct {
+	// WPM is the starting speed, like -wpm.
+	WPM    int              `json:"wpm,omitempty"`
+	Theme  string           `json:"theme,omitempty"`
+	Themes map[string]theme `json:"themes,omitempty"`
+	// PivotColor and PivotStyle override the theme's pivot, like
+	// -pivot-color and -pivot-style.
+	PivotColor string `json:"pivot_color,omitempty"`
+	PivotStyle string `json:"pivot_style,omitempty"`
+	// Align and Vertical place the word, like -align and -vertical.
+	Align    string   `json:"align,omitempty"`
+	Vertical *float64 `json:"vertical,omitempty"`
+	// Wheel is what the mouse wheel does, like -wheel.
+	Wheel string `json:"wheel,omitempty"`
+	// MinWPM and MaxWPM bound the speed, like -min-wpm and -max-wpm.
+	MinWPM int `json:"min_wpm,omitempty"`
+	MaxWPM int `json:"max_wpm,omitempty"`
+	// WPMPresets are the speeds for the preset keys 1 to 9.
+	WPMPresets []int `json:"wpm_presets,omitempty"`
+	// Goal is a daily reading goal, shown in the status line and by
+	// `zippy stats`.
+	Goal goal `json:"goal,omitzero"`
+	// Journal is a file to append a line to after each session, like
+	// -journal.
+	Journal string `json:"journal,omitempty"`
+	// Vault is a notes folder, such as an Obsidian vault, to keep a note
+	// per document in, like -vault.
+	Vault string `json:"vault,omitempty"`
+	// Dictionary is where the define key looks words up, like -dictionary.
+	Dictionary string `json:"dictionary,omitempty"`
+	// Translate sets up the translate keys; see translateConfig.
+	Translate translateConfig `json:"translate,omitzero"`
+	// Pronunciations is a lexicon in the format of the CMU Pronouncing
+	// Dictionary to add to the built-in one that -ipa uses.
+	Pronunciations string `json:"pronunciations,omitempty"`
+	// Hooks are commands run on reading events; see hooks.
+	Hooks hooks `json:"hooks,omitzero"`
+	// Webhook is where to post a summary of each session when it ends.
+	Webhook webhookConfig `json:"webhook,omitzero"`
+	// Highlights sends bookmarked sentences to Readwise or a similar API.
+	Highlights highlightsConfig `json:"highlights,omitzero"`
+	// MediaKeys lets media keys control playback, like -media-keys.
+	MediaKeys bool `json:"media_keys,omitempty"`
+	// ShowStreak adds the reading streak to the summary shown before
+	// reading starts.
+	ShowStreak bool `json:"show_streak,omitempty"`
+	// Profiles are named bundles of reading options; see profile.
+	Profiles map[string]profile `json:"profiles,omitempty"`
+	// Keys rebinds actions, e.g. {"back": ["j"], "forward": ["k"]}.
+	Keys map[string][]string `json:"keys,omitempty"`
+}
+
+func configPath() (string, error) {
+	if path := os.Getenv(envPrefix + "CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "zippy", "config.json"), nil
+}
+
+// loadConfig reads the config file. A missing file is the same as an empty
+// one.
+func loadConfig() (config, error) {
+	var cfg config
+	path, err := configPath()
+	if err != nil {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// checkPresets validates the WPM presets from the config file, returning nil
+// for the defaults if there are none.
+func checkPresets(presets []int) ([]int, error) {
+	if len(presets) > 9 {
+		return nil, fmt.Errorf("wpm_presets has %d speeds; there are only 9 preset keys", len(presets))
+	}
+	for _, wpm := range presets {
+		if wpm <= 0 {
+			return nil, fmt.Errorf("wpm_presets: %d is not a speed", wpm)
+		}
+	}
+	if len(presets) == 0 {
+		return nil, nil
+	}
+	return presets, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxContextWords bounds how far the context pane looks for the ends of the
+// current sentence.
+const maxContextWords = 200
+
+// sentenceAround returns the words of the sentence the current frame is in,
+// as offsets from the current position.
+func (m model) sentenceAround() (first, last int) {
+	return m.sentenceBounds(0)
+}
+
+// sentenceBounds returns the words of the sentence the word at offset from
+// the current position is in, as offsets from the current position.
+func (m model) sentenceBounds(offset int) (first, last int) {
+	tok, ok := m.stream.Peek(offset)
+	if !ok {
+		return 0, -1
+	}
+	first = offset - tok.sentenceWord
+	if _, ok := m.stream.Peek(first); !ok {
+		// The start has dropped out of a lazy stream's history.
+		for first < offset {
+			if _, ok := m.stream.Peek(first + 1); ok {
+				first++
+				break
+			}
+			first++
+		}
+	}
+	last = offset
+	for i := offset + 1; i < offset+maxContextWords; i++ {
+		tok, ok := m.stream.Peek(i)
+		if !ok || tok.sentenceStart() {
+			break
+		}
+		last = i
+	}
+	return first, last
+}
+
+// sentence is the text of the sentence the current frame is in.
+func (m model) sentence() string {
+	return m.sentenceAt(m.stream.Pos())
+}
+
+// sentenceAt is the text of the sentence the word at pos is in, or empty if
+// the stream no longer has it.
+func (m model) sentenceAt(pos int) string {
+	first, last := m.sentenceBounds(pos - m.stream.Pos())
+	var words []string
+	for i := first; i <= last; i++ {
+		if tok, ok := m.stream.Peek(i); ok {
+			words = append(words, tok.text)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// contextPaneHeight is how many rows the context pane takes, including its
+// top rule, out of the rows available for content.
+func contextPaneHeight(contentHeight int) int {
+	if contentHeight < 6 {
+		return 0
+	}
+	return contentHeight / 3
+}
+
+// contextPane shows the current sentence wrapped to the screen, with the
+// words of the current frame highlighted, scrolled so that they are in view.
+func (m model) contextPane(height, frameLen int) string {
+	width := max(m.width-2, 1)
+	first, last := m.sentenceAround()
+	var (
+		lines  []string
+		line   []string
+		used   int
+		active int
+	)
+	for i := first; i <= last; i++ {
+		tok, _ := m.stream.Peek(i)
+		w := lipgloss.Width(tok.text)
+		if used > 0 && used+1+w > width {
+			lines = append(lines, strings.Join(line, " "))
+			line, used = nil, 0
+		}
+		word := m.theme.status().Render(tok.text)
+		if i >= 0 && i < frameLen {
+			word = m.theme.pivot().Render(tok.text)
+			active = len(lines)
+		}
+		if used > 0 {
+			used++
+		}
+		line = append(line, word)
+		used += w
+	}
+	if len(line) > 0 {
+		lines = append(lines, strings.Join(line, " "))
+	}
+
+	rows := height - 1
+	start := min(max(active-rows/2, 0), max(len(lines)-rows, 0))
+	lines = lines[start:min(start+rows, len(lines))]
+	for i, line := range lines {
+		lines[i] = truncate(line, width)
+	}
+	rule := m.theme.dim().Render(strings.Repeat("─", m.width))
+	body := m.theme.place(m.width, rows, lipgloss.Center, lipgloss.Top, strings.Join(lines, "\n"))
+	return rule + "\n" + body
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// frameStats measures how closely playback keeps to its schedule, for the
+// debug overlay toggled with D.
+type frameStats struct {
+	// scheduled and actual are how long the last frame was meant to last
+	// and how long it took to arrive.
+	scheduled time.Duration
+	actual    time.Duration
+	total     time.Duration
+	frames    int
+	// dropped counts frames that arrived a whole interval late, so that a
+	// frame's worth of reading time was lost.
+	dropped int
+	// inFlight counts the ticks scheduled but not yet handled. More than one
+	// means two tick chains are driving playback at once. It is shared by
+	// every copy of the model, and nil when not counted.
+	inFlight *atomic.Int64
+}
+
+// sent notes that a tick was scheduled.
+func (s frameStats) sent() {
+	if s.inFlight != nil {
+		s.inFlight.Add(1)
+	}
+}
+
+// record notes the arrival of a tick at now.
+func (s *frameStats) record(msg tickMsg, now time.Time) {
+	if msg.sent.IsZero() {
+		return
+	}
+	if s.inFlight != nil {
+		s.inFlight.Add(-1)
+	}
+	s.scheduled, s.actual = msg.interval, now.Sub(msg.sent)
+	s.total += s.actual
+	s.frames++
+	if msg.interval > 0 && s.actual >= 2*msg.interval {
+		s.dropped++
+	}
+}
+
+func (s frameStats) String() string {
+	queue := ""
+	if s.inFlight != nil {
+		queue = fmt.Sprintf("  queue %d", s.inFlight.Load())
+	}
+	if s.frames == 0 {
+		return "debug: no frames yet" + queue
+	}
+	return fmt.Sprintf("debug: scheduled %s  actual %s  mean %s  dropped %d/%d",
+		ms(s.scheduled), ms(s.actual), ms(s.total/time.Duration(s.frames)),
+		s.dropped, s.frames) + queue
+}
+
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
+// toggleDebug shows or hides the frame-timing overlay, starting the
+// measurements afresh.
+func (m *model) toggleDebug() {
+	m.debug = !m.debug
+	m.frameStats = frameStats{inFlight: m.frameStats.inFlight}
+}
+
+// withDebug draws the overlay over the top line of the word area.
+func (m model) withDebug(body string) string {
+	line := m.theme.status().Width(m.width).Render(truncate(m.frameStats.String(), m.width))
+	if _, rest, ok := strings.Cut(body, "\n"); ok {
+		return line + "\n" + rest
+	}
+	return line
+}
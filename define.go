@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// lookup is the overlay the define and translate keys open over the word,
+// showing what was found for text.
+type lookup struct {
+	text  string
+	lines []string
+	err   error
+	// missing is what to say if nothing was found.
+	missing string
+	// loading is set until the answer comes.
+	loading bool
+	// resume is set if playback was running, to carry on once the overlay
+	// is closed.
+	resume bool
+}
+
+type lookupMsg struct {
+	text  string
+	lines []string
+	err   error
+}
+
+// openLookup pauses on the current word and runs fn in the background to
+// find what the overlay shows for text.
+func (m *model) openLookup(text, missing string, fn func() ([]string, error)) tea.Cmd {
+	m.lookup = &lookup{text: text, missing: missing, loading: true, resume: m.running}
+	m.setRunning(false)
+	m.rewinding = false
+	return func() tea.Msg {
+		lines, err := fn()
+		return lookupMsg{text: text, lines: lines, err: err}
+	}
+}
+
+// define looks up the current word in the dictionary.
+func (m *model) define() tea.Cmd {
+	if m.dictionary == nil {
+		m.notice = `No dictionary; install WordNet or set "dictionary" in the config file`
+		return nil
+	}
+	tok, ok := m.stream.Current()
+	word := bareWord(tok.text)
+	if !ok || word == "" {
+		return nil
+	}
+	d := m.dictionary
+	return m.openLookup(word, "Not in the dictionary.", func() ([]string, error) {
+		senses, err := lookUp(d, word)
+		var lines []string
+		for _, s := range senses {
+			lines = append(lines, s.String())
+		}
+		return lines, err
+	})
+}
+
+func (m *model) handleLookup(msg lookupMsg) {
+	if m.lookup == nil || m.lookup.text != msg.text {
+		return
+	}
+	m.lookup.lines, m.lookup.err, m.lookup.loading = msg.lines, msg.err, false
+}
+
+// handleLookupKey closes the overlay, carrying on reading if it was, with
+// any key but quit, which asks first as it does while reading.
+func (m *model) handleLookupKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "ctrl+c" {
+		return tea.Quit
+	}
+	resume := m.lookup.resume
+	m.lookup = nil
+	if key.Matches(msg, m.keys().Quit) {
+		return m.quit()
+	}
+	if resume {
+		return m.togglePlay()
+	}
+	return nil
+}
+
+func (m model) lookupScreen() string {
+	var b strings.Builder
+	b.WriteString(m.theme.pivot().Render(m.lookup.text) + "\n\n")
+	switch l := m.lookup; {
+	case l.loading:
+		b.WriteString("Looking it up...")
+	case l.err != nil:
+		fmt.Fprintf(&b, "Could not look it up: %v", l.err)
+	case len(l.lines) == 0:
+		b.WriteString(l.missing)
+	default:
+		b.WriteString(strings.Join(l.lines, "\n"))
+	}
+	b.WriteString("\n\nPress any key to carry on.")
+	width := min(m.width-4, 72)
+	text := m.theme.text().Width(max(width, 1)).Render(b.String())
+	return m.theme.place(m.width, m.height, lipgloss.Center, lipgloss.Center, text)
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sense is one meaning of a word as a dictionary gives it.
+type sense struct {
+	pos   string
+	gloss string
+}
+
+// String gives the sense as "noun: gloss".
+func (s sense) String() string {
+	if s.pos == "" {
+		return s.gloss
+	}
+	return s.pos + ": " + s.gloss
+}
+
+// maxSenses is how many meanings a lookup returns at most.
+const maxSenses = 5
+
+// dictionary looks words up for the definition overlay and the Anki export.
+// A word it does not know has no senses; errors are for failing to look.
+type dictionary interface {
+	define(word string) ([]sense, error)
+}
+
+// wordnetDirs are where WordNet's database is commonly installed, tried
+// when the config file names no dictionary.
+var wordnetDirs = []string{
+	"/usr/share/wordnet",
+	"/usr/local/share/wordnet",
+	"/usr/local/WordNet-3.0/dict",
+	"/opt/homebrew/share/wordnet",
+}
+
+// newDictionary makes the dictionary that spec names: a URL containing
+// {word} to ask a web API, or a directory holding WordNet's index.* and
+// data.* files. With no spec it looks for an installed WordNet, and returns
+// nil if there is none.
+func newDictionary(spec string) (dictionary, error) {
+	if spec == "" {
+		for _, dir := range wordnetDirs {
+			if _, err := os.Stat(filepath.Join(dir, "index.noun")); err == nil {
+				return &wordnet{dir: dir}, nil
+			}
+		}
+		return nil, nil
+	}
+	if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+		if !strings.Contains(spec, "{word}") {
+			return nil, fmt.Errorf("dictionary URL %q needs {word} where the word goes", spec)
+		}
+		return webDictionary{url: spec, client: &http.Client{Timeout: 10 * time.Second}}, nil
+	}
+	dir := expandHome(spec)
+	if _, err := os.Stat(filepath.Join(dir, "index.noun")); err != nil {
+		return nil, fmt.Errorf("no WordNet database in %s: %w", dir, err)
+	}
+	return &wordnet{dir: dir}, nil
+}
+
+// lookUp tries the word as it is, in lower case, and then the forms it may
+// be an inflection of, returning the senses of the first one known.
+func lookUp(d dictionary, word string) ([]sense, error) {
+	tried := map[string]bool{}
+	for _, w := range append([]string{word}, lemmas(strings.ToLower(word))...) {
+		if tried[w] {
+			continue
+		}
+		tried[w] = true
+		senses, err := d.define(w)
+		if err != nil || len(senses) > 0 {
+			return senses, err
+		}
+	}
+	return nil, nil
+}
+
+// lemmas returns a word and the forms it may be an inflection of, going by
+// the commonest English endings only: "stories" gives "story" among others
+// and "running" gives "run".
+func lemmas(word string) []string {
+	forms := []string{word}
+	if base, ok := strings.CutSuffix(word, "'s"); ok {
+		forms = append(forms, base)
+		word = base
+	}
+	for _, suffix := range []string{"ies", "ing", "ed", "es", "s", "ly", "er", "est"} {
+		base, ok := strings.CutSuffix(word, suffix)
+		if !ok || base == "" {
+			continue
+		}
+		forms = append(forms, base, base+"e")
+		if suffix == "ies" {
+			forms = append(forms, base+"y")
+		}
+		if n := len(base); n >= 2 && base[n-1] == base[n-2] {
+			forms = append(forms, base[:n-1])
+		}
+	}
+	return forms
+}
+
+// wordnet reads the database files of Princeton WordNet.
+type wordnet struct {
+	dir string
+	// index holds each index file read so far by lemma, with the rest of
+	// the lemma's entry, so that a file is scanned once however many words
+	// are looked up.
+	mu    sync.Mutex
+	index map[string]map[string]string
+}
+
+var wordnetPOS = []struct{ file, name string }{
+	{"noun", "noun"}, {"verb", "verb"}, {"adj", "adjective"}, {"adv", "adverb"},
+}
+
+func (w *wordnet) define(word string) ([]sense, error) {
+	lemma := strings.ReplaceAll(strings.ToLower(word), " ", "_")
+	var senses []sense
+	for _, pos := range wordnetPOS {
+		offsets, err := w.offsets(pos.file, lemma)
+		if err != nil {
+			return nil, err
+		}
+		for _, off := range offsets {
+			if len(senses) == maxSenses {
+				return senses, nil
+			}
+			gloss, err := w.gloss(pos.file, off)
+			if err != nil {
+				return nil, err
+			}
+			senses = append(senses, sense{pos: pos.name, gloss: gloss})
+		}
+	}
+	return senses, nil
+}
+
+// offsets finds the lemma in an index file, whose lines go "lemma pos
+// synset_cnt p_cnt [ptr_symbol...] sense_cnt tagsense_cnt offset...", and
+// returns where its synsets are in the data file.
+func (w *wordnet) offsets(pos, lemma string) ([]int64, error) {
+	index, err := w.loadIndex(pos)
+	if err != nil {
+		return nil, err
+	}
+	rest, ok := index[lemma]
+	if !ok {
+		return nil, nil
+	}
+	fields := append([]string{lemma}, strings.Fields(rest)...)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("index.%s: bad entry for %q", pos, lemma)
+	}
+	count, err := strconv.Atoi(fields[2])
+	if err != nil || count > len(fields)-4 {
+		return nil, fmt.Errorf("index.%s: bad entry for %q", pos, lemma)
+	}
+	var offsets []int64
+	for _, s := range fields[len(fields)-count:] {
+		off, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("index.%s: bad entry for %q", pos, lemma)
+		}
+		offsets = append(offsets, off)
+	}
+	return offsets, nil
+}
+
+// loadIndex reads an index file the first time it is needed. A missing one
+// counts as empty. The license text at the top of the file is indented, so
+// it never matches a lemma.
+func (w *wordnet) loadIndex(pos string) (map[string]string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if index, ok := w.index[pos]; ok {
+		return index, nil
+	}
+	index := map[string]string{}
+	f, err := os.Open(filepath.Join(w.dir, "index."+pos))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		sc.Buffer(nil, 1<<20)
+		for sc.Scan() {
+			if lemma, rest, ok := strings.Cut(sc.Text(), " "); ok && lemma != "" {
+				index[lemma] = rest
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if w.index == nil {
+		w.index = map[string]map[string]string{}
+	}
+	w.index[pos] = index
+	return index, nil
+}
+
+// gloss reads the definition of the synset at off in a data file, leaving
+// out the examples that follow it.
+func (w *wordnet) gloss(pos string, off int64) (string, error) {
+	f, err := os.Open(filepath.Join(w.dir, "data."+pos))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(io.NewSectionReader(f, off, 1<<20)).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	_, gloss, ok := strings.Cut(line, " | ")
+	if !ok {
+		return "", fmt.Errorf("data.%s: no synset at %d", pos, off)
+	}
+	gloss, _, _ = strings.Cut(gloss, `; "`)
+	return strings.TrimSpace(gloss), nil
+}
+
+// webDictionary asks a web API, substituting the word for {word} in its
+// URL. Replies are read in the format of dictionaryapi.dev, or taken as the
+// definition if they are plain text.
+type webDictionary struct {
+	url    string
+	client *http.Client
+}
+
+func (d webDictionary) define(word string) ([]sense, error) {
+	resp, err := d.client.Get(strings.ReplaceAll(d.url, "{word}", url.PathEscape(word)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("dictionary: %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if kind, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); kind == "text/plain" {
+		if text := strings.TrimSpace(string(body)); text != "" {
+			return []sense{{gloss: text}}, nil
+		}
+		return nil, nil
+	}
+	var entries []struct {
+		Meanings []struct {
+			PartOfSpeech string `json:"partOfSpeech"`
+			Definitions  []struct {
+				Definition string `json:"definition"`
+			} `json:"definitions"`
+		} `json:"meanings"`
+	}
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("dictionary: unexpected reply: %w", err)
+	}
+	var senses []sense
+	for _, e := range entries {
+		for _, m := range e.Meanings {
+			for _, def := range m.Definitions {
+				if len(senses) == maxSenses {
+					return senses, nil
+				}
+				senses = append(senses, sense{pos: m.PartOfSpeech, gloss: def.Definition})
+			}
+		}
+	}
+	return senses, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWordNet(t *testing.T) {
+	dir := t.TempDir()
+	header := "  1 This software and database is being provided\n"
+	cat := "00000000 05 n 01 cat 0 001 @ 00000000 n 0000 | feline mammal usually having thick soft fur; \"cats purr\"\n"
+	run := "00000000 04 n 01 run 0 000 | a score in baseball\n"
+	// Synsets start with their own offset, of fixed width.
+	offCat, offRun := len(header), len(header)+len(cat)
+	cat = fmt.Sprintf("%08d", offCat) + cat[8:]
+	run = fmt.Sprintf("%08d", offRun) + run[8:]
+	files := map[string]string{
+		"data.noun":  header + cat + run,
+		"index.noun": header + fmt.Sprintf("cat n 1 1 @ 1 0 %08d\nodd n\nrun n 1 0 1 0 %08d\n", offCat, offRun),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, err := newDictionary(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	senses, err := lookUp(d, "Cats")
+	if err != nil || len(senses) != 1 || senses[0].String() != "noun: feline mammal usually having thick soft fur" {
+		t.Fatalf("expected cats to find cat without its example, got %v, %v", senses, err)
+	}
+	if senses, _ := lookUp(d, "running"); len(senses) != 1 || senses[0].gloss != "a score in baseball" {
+		t.Fatalf("expected running to find run, got %v", senses)
+	}
+	if senses, err := lookUp(d, "dog"); err != nil || len(senses) != 0 {
+		t.Fatalf("expected no senses for an unknown word, got %v, %v", senses, err)
+	}
+	if _, err := d.define("odd"); err == nil {
+		t.Fatal("expected a short index entry to be reported")
+	}
+	// The index is read once, so lookups go on without the file.
+	if err := os.Remove(filepath.Join(dir, "index.noun")); err != nil {
+		t.Fatal(err)
+	}
+	if senses, _ := lookUp(d, "cat"); len(senses) != 1 {
+		t.Fatalf("expected the index to be kept after the first lookup, got %v", senses)
+	}
+	if _, err := newDictionary(t.TempDir()); err == nil {
+		t.Fatal("expected a directory without WordNet to be rejected")
+	}
+}
+
+func TestWebDictionary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/en/cat":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `[{"word":"cat","meanings":[{"partOfSpeech":"noun","definitions":[{"definition":"A small feline."}]}]}]`)
+		case "/text/cat":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			fmt.Fprint(w, "a pet\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	d, err := newDictionary(srv.URL + "/en/{word}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if senses, err := lookUp(d, "cats"); err != nil || len(senses) != 1 || senses[0].String() != "noun: A small feline." {
+		t.Fatalf("unexpected senses %v, %v", senses, err)
+	}
+	d, _ = newDictionary(srv.URL + "/text/{word}")
+	if senses, _ := lookUp(d, "cat"); len(senses) != 1 || senses[0].String() != "a pet" {
+		t.Fatalf("expected a plain text reply to be the definition, got %v", senses)
+	}
+	if _, err := newDictionary(srv.URL + "/en/"); err == nil {
+		t.Fatal("expected a URL without {word} to be rejected")
+	}
+}
+
+type fakeDictionary map[string]string
+
+func (f fakeDictionary) define(word string) ([]sense, error) {
+	if gloss, ok := f[word]; ok {
+		return []sense{{pos: "noun", gloss: gloss}}, nil
+	}
+	return nil, nil
+}
+
+func TestDefineOverlay(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("The cat sat."), true), dictionary: fakeDictionary{"cat": "a small feline"}, width: 60, height: 10}
+	m.stream.Seek(1)
+	m = press(m, " ")
+	next, cmd := m.Update(keyMsg("d"))
+	m = next.(model)
+	if m.running || m.lookup == nil || !m.lookup.loading {
+		t.Fatal("expected d to pause and start looking the word up")
+	}
+	m, _ = step(t, m, cmd)
+	if view := m.View(); !strings.Contains(view, "noun: a small feline") {
+		t.Fatalf("expected the definition on screen, got %q", view)
+	}
+	next, cmd = m.Update(keyMsg("x"))
+	m = next.(model)
+	if m.lookup != nil || !m.running || cmd == nil || m.stream.Pos() != 1 {
+		t.Fatal("expected any key to close the overlay and carry on from the same word")
+	}
+}
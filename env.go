@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envPrefix starts the environment variables that stand in for options:
+// ZIPPY_WPM for -wpm, ZIPPY_START_AT for -start-at and so on. Options given
+// on the command line take precedence over them, and they over the config
+// file.
+const envPrefix = "ZIPPY_"
+
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnv sets the options of fs that were not given on the command line
+// from the environment. Empty variables are ignored.
+func applyEnv(fs *flag.FlagSet) error {
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		name := envName(f.Name)
+		v := os.Getenv(name)
+		if v == "" || given[f.Name] || err != nil {
+			return
+		}
+		if setErr := fs.Set(f.Name, v); setErr != nil {
+			err = fmt.Errorf("%s=%q: %v", name, v, setErr)
+		}
+	})
+	return err
+}
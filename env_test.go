@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyEnv(t *testing.T) {
+	fs := flag.NewFlagSet("read", flag.ContinueOnError)
+	wpm := fs.Int("wpm", 500, "")
+	theme := fs.String("theme", "", "")
+	lazy := fs.Bool("lazy", false, "")
+	start := fs.String("start-at", "", "")
+	t.Setenv("ZIPPY_WPM", "350")
+	t.Setenv("ZIPPY_THEME", "night")
+	t.Setenv("ZIPPY_LAZY", "1")
+	t.Setenv("ZIPPY_START_AT", "")
+	if err := fs.Parse([]string{"-wpm", "420"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyEnv(fs); err != nil {
+		t.Fatalf("applyEnv: %v", err)
+	}
+	if *wpm != 420 || *theme != "night" || !*lazy || *start != "" {
+		t.Fatalf("expected flags over the environment, got %d %q %v %q", *wpm, *theme, *lazy, *start)
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["theme"] || set["start-at"] {
+		t.Fatalf("expected options from the environment to count as given, got %v", set)
+	}
+
+	t.Setenv("ZIPPY_WPM", "fast")
+	if err := applyEnv(flag.NewFlagSet("plan", flag.ContinueOnError)); err != nil {
+		t.Fatalf("expected variables without a flag to be ignored, got %v", err)
+	}
+	fs = flag.NewFlagSet("read", flag.ContinueOnError)
+	fs.Int("wpm", 500, "")
+	if err := applyEnv(fs); err == nil {
+		t.Fatal("expected a bad value to be an error")
+	}
+}
+
+func TestConfigPathFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zippy.json")
+	t.Setenv("ZIPPY_CONFIG", path)
+	if got, err := configPath(); err != nil || got != path {
+		t.Fatalf("expected %s, got %s (%v)", path, got, err)
+	}
+}
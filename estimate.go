@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// runEstimate implements `zippy estimate`, printing how long documents take
+// to read with the given pacing, for sorting a reading queue in scripts.
+func runEstimate(args []string) int {
+	fs := flag.NewFlagSet("estimate", flag.ExitOnError)
+	var (
+		p           pacing
+		file        string
+		profileName string
+		seconds     bool
+	)
+	fs.StringVar(&file, "file", "", "path to input text; more files can follow the options")
+	p.register(fs)
+	fs.StringVar(&profileName, "profile", "", "take the pacing options from a profile in the config file; options given here take precedence")
+	fs.BoolVar(&seconds, "seconds", false, "print whole seconds instead of a duration such as 1h5m0s")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s estimate [options] [FILE...]\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Print how long the input takes to read, without starting playback. With")
+		fmt.Fprintln(os.Stderr, "several files, print a line for each followed by the total.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := applyEnv(fs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// As when reading, options given here win over the profile, and the
+	// profile over the rest of the config file.
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read config:", err)
+		return 1
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["wpm"] && cfg.WPM > 0 {
+		p.wpm = cfg.WPM
+	}
+	if profileName != "" {
+		pr, err := findProfile(profileName, cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		pr.applyPacing(&p, set)
+	}
+	if err := p.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	files := fs.Args()
+	if file != "" {
+		files = append([]string{file}, files...)
+	}
+	if len(files) == 0 {
+		text, err := readInput("")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Provide input via -file or stdin.")
+			fs.PrintDefaults()
+			return 1
+		}
+		fmt.Println(formatEstimate(buildPlan(tokenize(text), p).total, seconds))
+		return 0
+	}
+	return writeEstimates(os.Stdout, files, p, seconds)
+}
+
+// formatEstimate prints a reading time as a duration, or in whole seconds.
+func formatEstimate(d time.Duration, seconds bool) string {
+	if seconds {
+		return fmt.Sprint(int(d.Round(time.Second).Seconds()))
+	}
+	return d.Round(time.Second).String()
+}
+
+// writeEstimates writes the reading time of each file to w, named and
+// followed by the total when there are several. Files that cannot be read
+// are reported and left out, and make the exit status 1.
+func writeEstimates(w io.Writer, files []string, p pacing, seconds bool) int {
+	status := 0
+	var total time.Duration
+	for _, name := range files {
+		text, err := readInput(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+		d := buildPlan(tokenize(text), p).total
+		total += d
+		if len(files) == 1 {
+			fmt.Fprintln(w, formatEstimate(d, seconds))
+		} else {
+			fmt.Fprintf(w, "%s\t%s\n", formatEstimate(d, seconds), name)
+		}
+	}
+	if len(files) > 1 {
+		fmt.Fprintf(w, "%s\ttotal\n", formatEstimate(total, seconds))
+	}
+	return status
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimateTimes(t *testing.T) {
+	words := func(n int) string { return strings.Repeat("word ", n) }
+	for _, c := range []struct {
+		name string
+		text string
+		p    pacing
+		want time.Duration
+	}{
+		{"empty", "", pacing{wpm: 300, chunk: 1, skimWords: 3}, 0},
+		{"one word", "word", pacing{wpm: 600, chunk: 1, skimWords: 3}, 100 * time.Millisecond},
+		{"a minute", words(300), pacing{wpm: 300, chunk: 1, skimWords: 3}, time.Minute},
+		{"twice as fast", words(300), pacing{wpm: 600, chunk: 1, skimWords: 3}, 30 * time.Second},
+		{"chunks", words(300), pacing{wpm: 600, chunk: 3, skimWords: 3}, 30 * time.Second},
+		// Skimming shows two words a sentence, and the last word, at
+		// double speed.
+		{"skim", strings.Repeat("one two three four five six. ", 10), pacing{wpm: 600, chunk: 1, skim: true, skimWords: 2}, 21 * 50 * time.Millisecond},
+	} {
+		if got := buildPlan(tokenize(c.text), c.p).total; got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestFormatEstimate(t *testing.T) {
+	for _, c := range []struct {
+		d       time.Duration
+		seconds bool
+		want    string
+	}{
+		{0, false, "0s"},
+		{1500 * time.Millisecond, false, "2s"},
+		{65*time.Minute + 400*time.Millisecond, false, "1h5m0s"},
+		{65 * time.Minute, true, "3900"},
+		{400 * time.Millisecond, true, "0"},
+	} {
+		if got := formatEstimate(c.d, c.seconds); got != c.want {
+			t.Errorf("formatEstimate(%v, %t) = %q, want %q", c.d, c.seconds, got, c.want)
+		}
+	}
+}
+
+func TestWriteEstimates(t *testing.T) {
+	p := pacing{wpm: 300, chunk: 1, skimWords: 3}
+	short, long := writeTemp(t, strings.Repeat("word ", 150)), writeTemp(t, strings.Repeat("word ", 600))
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	for _, c := range []struct {
+		name   string
+		files  []string
+		want   string
+		status int
+	}{
+		{"one file", []string{short}, "30s\n", 0},
+		{"several", []string{short, long}, "30s\t" + short + "\n2m0s\t" + long + "\n2m30s\ttotal\n", 0},
+		{"unreadable", []string{missing, short}, "30s\t" + short + "\n30s\ttotal\n", 1},
+	} {
+		var b strings.Builder
+		if status := writeEstimates(&b, c.files, p, false); status != c.status || b.String() != c.want {
+			t.Errorf("%s: expected %q with status %d, got %q with %d", c.name, c.want, c.status, b.String(), status)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import "time"
+
+// eta estimates how long the rest of the document takes at the current
+// speed. For lazy input whose word count is not known yet it extrapolates
+// from how far through the input reading is.
+func (m model) eta() (time.Duration, bool) {
+	pos := m.stream.Pos()
+	known, total := m.stream.Total()
+	if !known {
+		f, ok := m.progress()
+		if !ok || f <= 0 {
+			return 0, false
+		}
+		total = int(float64(pos+1) / f)
+	}
+	remaining := max(total-pos, 0)
+	return time.Duration(remaining) * m.wordInterval(), true
+}
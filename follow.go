@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// followPoll is how often a followed file is checked for new text.
+const followPoll = 250 * time.Millisecond
+
+// followReader reads a file the way tail -f does: at the end of the file it
+// waits for more to be written instead of returning io.EOF. A file that
+// shrinks, as a rotated log does, is read again from the start.
+type followReader struct {
+	file   *os.File
+	closed chan struct{}
+	once   sync.Once
+}
+
+// followFile makes r wait for more text at its end. Only files can be
+// followed; anything else is returned as it is.
+func followFile(r io.ReadCloser) io.ReadCloser {
+	f, ok := r.(*os.File)
+	if !ok {
+		return r
+	}
+	return &followReader{file: f, closed: make(chan struct{})}
+}
+
+func (r *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.file.Read(p)
+		if n > 0 || err != io.EOF {
+			return n, err
+		}
+		r.rewindIfTruncated()
+		select {
+		case <-r.closed:
+			return 0, io.EOF
+		case <-time.After(followPoll):
+		}
+	}
+}
+
+func (r *followReader) rewindIfTruncated() {
+	info, err := r.file.Stat()
+	if err != nil {
+		return
+	}
+	if off, err := r.file.Seek(0, io.SeekCurrent); err == nil && info.Size() < off {
+		r.file.Seek(0, io.SeekStart)
+	}
+}
+
+// Close ends a Read that is waiting for more text.
+func (r *followReader) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return r.file.Close()
+}
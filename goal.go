@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// goal is a daily reading goal from the config file: so many words, so many
+// minutes of reading, or both. It is met once every part of it is.
+type goal struct {
+	Words   int `json:"words,omitempty"`
+	Minutes int `json:"minutes,omitempty"`
+}
+
+func (g goal) set() bool { return g.Words > 0 || g.Minutes > 0 }
+
+func (g goal) check() error {
+	if g.Words < 0 || g.Minutes < 0 {
+		return fmt.Errorf("goal: words and minutes must be positive")
+	}
+	return nil
+}
+
+// progress is how far words read in d go towards the goal, from 0 to 1.
+func (g goal) progress(words int, d time.Duration) float64 {
+	p := 1.0
+	if g.Words > 0 {
+		p = min(p, float64(words)/float64(g.Words))
+	}
+	if g.Minutes > 0 {
+		p = min(p, d.Minutes()/float64(g.Minutes))
+	}
+	return p
+}
+
+func (g goal) met(words int, d time.Duration) bool {
+	return g.set() && g.progress(words, d) >= 1
+}
+
+// describe says how far along the goal is, e.g. "3,200/5,000 words, 12/20
+// min".
+func (g goal) describe(words int, d time.Duration) string {
+	var parts []string
+	if g.Words > 0 {
+		parts = append(parts, fmt.Sprintf("%s/%s words", formatCount(words), formatCount(g.Words)))
+	}
+	if g.Minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d min", int(d.Minutes()), g.Minutes))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// readOn adds up the sessions started on the same day as now, in local time.
+func readOn(sessions []session, now time.Time) (int, time.Duration) {
+	var (
+		words int
+		d     time.Duration
+	)
+	today := now.Local().Format(time.DateOnly)
+	for _, s := range sessions {
+		if s.Start.Local().Format(time.DateOnly) == today {
+			words += s.Words
+			d += s.Duration
+		}
+	}
+	return words, d
+}
+
+// todayRead is what has been read today, this session included.
+func (m model) todayRead() (int, time.Duration) {
+	return m.goalWords + m.wordsRead, m.goalTime + m.playedFor()
+}
+
+// goalStatus is the daily goal's part of the status line.
+func (m model) goalStatus() string {
+	if !m.goal.set() {
+		return ""
+	}
+	words, d := m.todayRead()
+	if m.goal.met(words, d) {
+		return "Goal reached"
+	}
+	return fmt.Sprintf("Goal %d%%", int(m.goal.progress(words, d)*100))
+}
+
+// checkGoal celebrates reaching the daily goal, once.
+func (m *model) checkGoal() {
+	if m.goalMet || !m.goal.set() {
+		return
+	}
+	words, d := m.todayRead()
+	if m.goal.met(words, d) {
+		m.goalMet = true
+		m.notice = "Daily goal reached! " + m.goal.describe(words, d)
+	}
+}
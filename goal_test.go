@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoal(t *testing.T) {
+	g := goal{Words: 1000, Minutes: 10}
+	if p := g.progress(500, 10*time.Minute); p != 0.5 {
+		t.Fatalf("expected the words to hold the goal back at half, got %v", p)
+	}
+	if !g.met(1200, 11*time.Minute) || g.met(1200, 9*time.Minute) {
+		t.Fatal("expected the goal to need both words and minutes")
+	}
+	if got := g.describe(1200, 9*time.Minute+30*time.Second); got != "1,200/1,000 words, 9/10 min" {
+		t.Fatalf("unexpected description %q", got)
+	}
+	if (goal{}).met(1000, time.Hour) {
+		t.Fatal("expected no goal never to be met")
+	}
+}
+
+func TestGoalInStatusLine(t *testing.T) {
+	m := model{
+		stream:    newEagerStream(tokenize(strings.Repeat("word ", 20)), false),
+		width:     200,
+		height:    3,
+		pacing:    pacing{wpm: 300},
+		markA:     -1,
+		markB:     -1,
+		goal:      goal{Words: 100},
+		goalWords: 90,
+	}
+	if !strings.Contains(m.View(), "Goal 90%") {
+		t.Fatalf("expected the progress towards the goal:\n%s", m.View())
+	}
+	m = press(m, " ")
+	for range 10 {
+		next, _ := m.Update(tickMsg{})
+		m = next.(model)
+	}
+	if !m.goalMet || !strings.Contains(m.View(), "Daily goal reached! 100/100 words") || !strings.Contains(m.View(), "Goal reached") {
+		t.Fatalf("expected the goal to be celebrated:\n%s", m.View())
+	}
+	m = press(m, "+")
+	if strings.Contains(m.View(), "Daily goal reached!") {
+		t.Fatal("expected the celebration only once")
+	}
+}
+
+func TestGoalInStats(t *testing.T) {
+	now := time.Date(2024, 5, 10, 18, 0, 0, 0, time.Local)
+	sessions := []session{
+		{Start: time.Date(2024, 5, 9, 9, 0, 0, 0, time.Local), Words: 3000, Duration: 10 * time.Minute},
+		{Start: time.Date(2024, 5, 10, 9, 0, 0, 0, time.Local), Words: 800, Duration: 3 * time.Minute},
+	}
+	r := buildStatsReport(sessions, time.Time{}, now)
+	r.addGoal(goal{Words: 2000}, sessions, now)
+	if g := r.Goal; g == nil || g.TodayWords != 800 || g.Met || g.DaysMet != 1 || !r.Days[0].GoalMet {
+		t.Fatalf("unexpected goal progress %+v", r.Goal)
+	}
+	var b strings.Builder
+	r.write(&b)
+	for _, want := range []string{"Today's goal:   800/2,000 words", "Goal met on:    1 of 2 days", "2024-05-09  3,000  10m0s  300   met"} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("expected %q in the report:\n%s", want, b.String())
+		}
+	}
+}
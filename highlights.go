@@ -0,0 +1,124 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// readwiseURL is Readwise's API for creating highlights.
+const readwiseURL = "https://readwise.io/api/v2/highlights/"
+
+// highlightsConfig sends the sentences of bookmarks to Readwise, or to
+// another API taking highlights in the same format, set under "highlights"
+// in the config file.
+type highlightsConfig struct {
+	// Token is a Readwise access token, from readwise.io/access_token.
+	Token string `json:"token,omitempty"`
+	// URL and Headers are for an API other than Readwise's.
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+func (c highlightsConfig) set() bool {
+	return c.Token != "" || c.URL != ""
+}
+
+// readwiseHighlight is a highlight as Readwise's API takes it. Location is
+// the word number the bookmark is at.
+type readwiseHighlight struct {
+	Text         string `json:"text"`
+	Title        string `json:"title"`
+	SourceType   string `json:"source_type"`
+	Category     string `json:"category"`
+	Location     int    `json:"location"`
+	LocationType string `json:"location_type"`
+}
+
+// documentTitle names a document after its file, without the extension.
+func documentTitle(key string) string {
+	name := filepath.Base(key)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// newHighlights returns the bookmarks whose sentences are not in sent as
+// highlights, with the sentences' hashes. Sent sentences are kept by their
+// text rather than the bookmark's position, so that a bookmark which moves
+// when the file changes is not sent again, and one now in another sentence
+// is. Bookmarks whose sentence the stream no longer has are left for
+// another time.
+func (m model) newHighlights(sent []string) ([]readwiseHighlight, []string) {
+	var (
+		highlights []readwiseHighlight
+		hashes     []string
+	)
+	for _, pos := range m.bookmarks {
+		text := m.sentenceAt(pos)
+		if text == "" {
+			continue
+		}
+		hash := sentenceHash(text)
+		if slices.Contains(sent, hash) || slices.Contains(hashes, hash) {
+			continue
+		}
+		highlights = append(highlights, readwiseHighlight{
+			Text:         text,
+			Title:        documentTitle(m.docKey),
+			SourceType:   "zippy",
+			Category:     "books",
+			Location:     pos + 1,
+			LocationType: "order",
+		})
+		hashes = append(hashes, hash)
+	}
+	return highlights, hashes
+}
+
+// sentenceHash is a short hash of a sentence, to remember it as sent
+// without keeping the text.
+func sentenceHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// sendHighlights posts highlights to the API.
+func sendHighlights(cfg highlightsConfig, highlights []readwiseHighlight) error {
+	url := cfg.URL
+	if url == "" {
+		url = readwiseURL
+	}
+	headers := map[string]string{}
+	if cfg.Token != "" {
+		headers["Authorization"] = "Token " + cfg.Token
+	}
+	maps.Copy(headers, cfg.Headers)
+	return postJSON(url, headers, map[string][]readwiseHighlight{"highlights": highlights})
+}
+
+// pushHighlights sends the bookmarks of the session's document that have
+// not been sent yet, and remembers them as sent. It returns how many it
+// sent.
+func pushHighlights(cfg highlightsConfig, m model) (int, error) {
+	if m.docKey == "" || len(m.bookmarks) == 0 {
+		return 0, nil
+	}
+	store, err := loadDocStore()
+	if err != nil {
+		return 0, err
+	}
+	highlights, hashes := m.newHighlights(store.doc(m.docKey).HighlightsSent)
+	if len(highlights) == 0 {
+		return 0, nil
+	}
+	if err := sendHighlights(cfg, highlights); err != nil {
+		return 0, err
+	}
+	err = updateDoc(m.docKey, func(d *docState) {
+		d.HighlightsSent = append(d.HighlightsSent, hashes...)
+		slices.Sort(d.HighlightsSent)
+	})
+	return len(highlights), err
+}
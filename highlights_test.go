@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPushHighlights(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	var (
+		got    []readwiseHighlight
+		auth   string
+		status = http.StatusOK
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Highlights []readwiseHighlight `json:"highlights"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		got, auth = body.Highlights, r.Header.Get("Authorization")
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	cfg := highlightsConfig{Token: "secret", URL: srv.URL}
+
+	m := model{
+		stream:    newEagerStream(tokenize("The spice must flow. He who controls it controls the universe. Fear is the mind-killer."), true),
+		docKey:    "/books/dune.txt",
+		bookmarks: []int{2, 6},
+	}
+	m.stream.Seek(10)
+	if n, err := pushHighlights(cfg, m); err != nil || n != 2 {
+		t.Fatalf("expected 2 highlights sent, got %d, %v", n, err)
+	}
+	want := []readwiseHighlight{
+		{Text: "The spice must flow.", Title: "dune", SourceType: "zippy", Category: "books", Location: 3, LocationType: "order"},
+		{Text: "He who controls it controls the universe.", Title: "dune", SourceType: "zippy", Category: "books", Location: 7, LocationType: "order"},
+	}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] || auth != "Token secret" {
+		t.Fatalf("expected %+v with the token, got %+v and %q", want, got, auth)
+	}
+
+	got = nil
+	if n, _ := pushHighlights(cfg, m); n != 0 || got != nil {
+		t.Fatal("expected highlights already sent not to be sent again")
+	}
+
+	m.bookmarks = append(m.bookmarks, 12)
+	status = http.StatusUnauthorized
+	if _, err := pushHighlights(cfg, m); err == nil {
+		t.Fatal("expected a refused request to be reported")
+	}
+	status = http.StatusOK
+	if n, _ := pushHighlights(cfg, m); n != 1 || got[0].Text != "Fear is the mind-killer." {
+		t.Fatalf("expected the highlight that failed to be sent again, got %+v", got)
+	}
+
+	// A sentence added at the start moves the bookmarks along, but their
+	// sentences were sent already.
+	got = nil
+	m.stream = newEagerStream(tokenize("Dune. The spice must flow. He who controls it controls the universe. Fear is the mind-killer."), true)
+	m.bookmarks = []int{3, 7, 13}
+	m.stream.Seek(11)
+	if n, _ := pushHighlights(cfg, m); n != 0 || got != nil {
+		t.Fatalf("expected moved bookmarks not to be sent again, got %+v", got)
+	}
+}
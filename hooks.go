@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// hooks are shell commands run when things happen while reading, set under
+// "hooks" in the config file. They learn what happened from ZIPPY_HOOK_*
+// environment variables; see hookEnv.
+type hooks struct {
+	// Start runs when a session starts, before the first word is shown.
+	Start string `json:"start,omitempty"`
+	// Pause and Resume run when playback stops and starts.
+	Pause  string `json:"pause,omitempty"`
+	Resume string `json:"resume,omitempty"`
+	// Finish runs when the session ends, once zippy has left the screen.
+	Finish string `json:"finish,omitempty"`
+	// Bookmark runs when a bookmark is added.
+	Bookmark string `json:"bookmark,omitempty"`
+}
+
+func (h hooks) command(event string) string {
+	switch event {
+	case "start":
+		return h.Start
+	case "pause":
+		return h.Pause
+	case "resume":
+		return h.Resume
+	case "finish":
+		return h.Finish
+	case "bookmark":
+		return h.Bookmark
+	}
+	return ""
+}
+
+// hookEnv describes the session to a hook, adding extra to the variables
+// every event gets. They are kept apart from the ZIPPY_ variables that set
+// options (see envPrefix), so that a hook running zippy again does not
+// pick up the reader's speed or position as options.
+func (m model) hookEnv(event string, extra ...string) []string {
+	s := m.liveStatus()
+	env := append(os.Environ(),
+		"ZIPPY_HOOK_EVENT="+event,
+		"ZIPPY_HOOK_DOCUMENT="+m.docKey,
+		"ZIPPY_HOOK_POSITION="+strconv.Itoa(s.Position),
+		"ZIPPY_HOOK_TOTAL="+strconv.Itoa(s.Total),
+		"ZIPPY_HOOK_PERCENT="+strconv.Itoa(s.Percent),
+		"ZIPPY_HOOK_WPM="+strconv.Itoa(s.WPM),
+		"ZIPPY_HOOK_WORDS_READ="+strconv.Itoa(m.wordsRead),
+		"ZIPPY_HOOK_SECONDS_READ="+strconv.Itoa(int(m.playedFor().Seconds())),
+	)
+	if tok, ok := m.stream.Current(); ok {
+		env = append(env, "ZIPPY_HOOK_WORD="+tok.text)
+	}
+	return append(env, extra...)
+}
+
+// startHook starts the hook for an event without waiting for it, its output
+// discarded so that it cannot garble the screen. Nothing is run if the
+// event has no hook.
+func (m model) startHook(event string, extra ...string) error {
+	command := m.hooks.command(event)
+	if command == "" || m.stream == nil {
+		return nil
+	}
+	cmd := shellCommand(command)
+	cmd.Env = m.hookEnv(event, extra...)
+	cmd.Stdout, cmd.Stderr = io.Discard, io.Discard
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// runHook runs the hook for an event to the end, with the terminal as its
+// output, for the finish hook once the screen is given back.
+func (m model) runHook(event string, extra ...string) error {
+	command := m.hooks.command(event)
+	if command == "" || m.stream == nil {
+		return nil
+	}
+	cmd := shellCommand(command)
+	cmd.Env = m.hookEnv(event, extra...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// fireHooks starts the pause and resume hooks when the update from prev
+// stopped or started playback.
+func (m *model) fireHooks(prev model) {
+	if m.running == prev.running {
+		return
+	}
+	event := "pause"
+	if m.running {
+		event = "resume"
+	}
+	if err := m.startHook(event); err != nil {
+		m.notice = fmt.Sprintf("Could not run the %s hook: %v", event, err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks here are sh commands")
+	}
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	out := filepath.Join(t.TempDir(), "events")
+	log := `echo "$ZIPPY_HOOK_EVENT,$ZIPPY_HOOK_POSITION,$ZIPPY_HOOK_TOTAL,$ZIPPY_HOOK_WORD,$ZIPPY_HOOK_BOOKMARK$ZIPPY_HOOK_FINISHED" >> ` + out
+	m := model{
+		pacing: pacing{wpm: 300, chunk: 1},
+		stream: newEagerStream(tokenize("one two three"), true),
+		docKey: "/books/numbers.txt",
+		hooks:  hooks{Pause: log, Resume: log, Bookmark: log, Finish: log},
+	}
+	m = press(m, "l", "b")
+	waitForLines(t, out, 1)
+	m = press(m, " ")
+	waitForLines(t, out, 2)
+	m = press(m, " ")
+	lines := waitForLines(t, out, 3)
+	slices.Sort(lines)
+	want := []string{"bookmark,2,3,two,2", "pause,2,3,two,", "resume,2,3,two,"}
+	if !slices.Equal(lines, want) {
+		t.Fatalf("expected %q, got %q", want, lines)
+	}
+
+	if err := m.runHook("finish", "ZIPPY_HOOK_FINISHED=0"); err != nil {
+		t.Fatal(err)
+	}
+	if lines := waitForLines(t, out, 4); lines[3] != "finish,2,3,two,0" {
+		t.Fatalf("unexpected finish hook output %q", lines[3])
+	}
+	for _, v := range m.hookEnv("pause") {
+		if strings.HasPrefix(v, envName("wpm")+"=") {
+			t.Fatalf("expected hooks not to be given -wpm through %s", v)
+		}
+	}
+	m.hooks.Finish = "exit 3"
+	if err := m.runHook("finish"); err == nil {
+		t.Fatal("expected a failing hook to be reported")
+	}
+}
+
+// waitForLines waits for a hook started in the background to have written
+// n lines to path.
+func waitForLines(t *testing.T, path string, n int) []string {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		data, _ := os.ReadFile(path)
+		if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(data) > 0 && len(lines) >= n {
+			return lines
+		}
+	}
+	t.Fatalf("expected %d lines from the hooks", n)
+	return nil
+}
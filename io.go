@@ -5,7 +5,8 @@ import (
 	"io"
 	"os"
 	"strings"
-	"unicode"
+
+	"github.com/mattn/go-isatty"
 )
 
 func openInput(filePath string) (io.ReadCloser, error) {
@@ -17,13 +18,8 @@ func openInput(filePath string) (io.ReadCloser, error) {
 		return file, nil
 	}
 
-	info, err := os.Stdin.Stat()
-	if err != nil {
-		return nil, err
-	}
-
 	// If stdin is a terminal (not a pipe/file), treat it as "no input provided".
-	if info.Mode()&os.ModeCharDevice != 0 {
+	if isTerminal(os.Stdin) {
 		return nil, fmt.Errorf("no input provided")
 	}
 
@@ -39,13 +35,8 @@ func readInput(filePath string) (string, error) {
 		return string(data), nil
 	}
 
-	info, err := os.Stdin.Stat()
-	if err != nil {
-		return "", err
-	}
-
 	// If stdin is a terminal (not a pipe/file), treat it as "no input provided".
-	if info.Mode()&os.ModeCharDevice != 0 {
+	if isTerminal(os.Stdin) {
 		return "", fmt.Errorf("no input provided")
 	}
 
@@ -57,21 +48,25 @@ func readInput(filePath string) (string, error) {
 	return string(data), nil
 }
 
-func tokenize(text string) []string {
-	var tokens []string
-	var b strings.Builder
-	for _, r := range text {
-		if unicode.IsSpace(r) {
-			if b.Len() > 0 {
-				tokens = append(tokens, b.String())
-				b.Reset()
-			}
-			continue
+// isTerminal reports whether f is an interactive terminal rather than a pipe
+// or file. Checking for a character device is not enough: on Windows, NUL is
+// one too and consoles are recognised by their console mode, while MSYS and
+// Cygwin terminals such as mintty show up as named pipes.
+func isTerminal(f *os.File) bool {
+	fd := f.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+func tokenize(text string) []token {
+	var tokens []token
+	t := newTokenizer(strings.NewReader(text))
+	for {
+		tok, done, _ := t.next()
+		if tok.text != "" {
+			tokens = append(tokens, tok)
+		}
+		if done {
+			return tokens
 		}
-		b.WriteRune(r)
-	}
-	if b.Len() > 0 {
-		tokens = append(tokens, b.String())
 	}
-	return tokens
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPipedInputIsNotATerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if isTerminal(r) {
+		t.Fatal("expected a pipe not to count as a terminal")
+	}
+	go func() {
+		w.WriteString("piped words")
+		w.Close()
+	}()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+	text, err := readInput("")
+	if err != nil || text != "piped words" {
+		t.Fatalf("expected the piped text, got %q, %v", text, err)
+	}
+}
+
+func TestRedirectedFileIsNotATerminal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "text.txt")
+	if err := os.WriteFile(path, []byte("some words"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Fatal("expected a file not to count as a terminal")
+	}
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+	in, err := openInput("")
+	if err != nil {
+		t.Fatalf("expected redirected input to be read, got %v", err)
+	}
+	in.Close()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// journalLine describes a session for the reading journal, e.g.
+// "2024-05-01: read 6,200 words of foo.epub in 14m at 440 WPM".
+func journalLine(s session) string {
+	what := "piped text"
+	if s.Document != "" {
+		what = filepath.Base(s.Document)
+	}
+	line := fmt.Sprintf("%s: read %s words of %s in %s", s.Start.Local().Format(time.DateOnly), formatCount(s.Words), what, journalDuration(s.Duration))
+	if wpm := s.wpm(); wpm > 0 {
+		line += fmt.Sprintf(" at %d WPM", wpm)
+	}
+	return line
+}
+
+// journalDuration rounds a session's length the way one would write it down:
+// "14m", "1h5m", or seconds for short ones.
+func journalDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	s := d.Round(time.Minute).String()
+	return strings.TrimSuffix(s, "0s")
+}
+
+// appendJournal adds a line for the session to the journal at path, as a
+// list item if it is a markdown file.
+func appendJournal(path string, s session) error {
+	if s.Words == 0 {
+		return nil
+	}
+	line := journalLine(s)
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".md" || ext == ".markdown" {
+		line = "- " + line
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// expandHome replaces a leading ~ in a path from the config file with the
+// home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok || (rest != "" && rest[0] != '/' && rest[0] != filepath.Separator) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
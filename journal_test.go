@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	start := time.Date(2024, 5, 1, 20, 0, 0, 0, time.Local)
+	s := session{Document: "/books/foo.epub", Start: start, Words: 6200, Duration: 14*time.Minute + 5*time.Second}
+	if got := journalLine(s); got != "2024-05-01: read 6,200 words of foo.epub in 14m at 440 WPM" {
+		t.Fatalf("unexpected line %q", got)
+	}
+	if got := journalLine(session{Start: start, Words: 12, Duration: 3 * time.Second}); got != "2024-05-01: read 12 words of piped text in 3s" {
+		t.Fatalf("unexpected line for a short piped session %q", got)
+	}
+
+	dir := t.TempDir()
+	md, txt := filepath.Join(dir, "notes", "reading.md"), filepath.Join(dir, "reading.txt")
+	for range 2 {
+		if err := appendJournal(md, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	appendJournal(txt, s)
+	appendJournal(txt, session{Start: start})
+	data, _ := os.ReadFile(md)
+	want := "- 2024-05-01: read 6,200 words of foo.epub in 14m at 440 WPM\n"
+	if string(data) != want+want {
+		t.Fatalf("expected two list items, got %q", data)
+	}
+	if data, _ := os.ReadFile(txt); string(data) != want[2:] {
+		t.Fatalf("expected a plain line and nothing for an empty session, got %q", data)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+// maxJumps bounds the jumplist; the oldest entries are dropped first.
+const maxJumps = 100
+
+// jump runs a non-linear move and, if it went anywhere, remembers where it
+// started so that ctrl+o can return there.
+func (m *model) jump(move func()) {
+	from := m.stream.Pos()
+	move()
+	if m.stream.Pos() == from {
+		return
+	}
+	// Like vim, a new jump discards the entries that ctrl+o stepped back
+	// over.
+	m.jumps = append(m.jumps[:m.jumpIdx], from)
+	if len(m.jumps) > maxJumps {
+		m.jumps = m.jumps[len(m.jumps)-maxJumps:]
+	}
+	m.jumpIdx = len(m.jumps)
+}
+
+// jumpBack returns to where the last jump started.
+func (m *model) jumpBack() {
+	if m.jumpIdx == 0 {
+		return
+	}
+	if m.jumpIdx == len(m.jumps) {
+		// Remember the current position so that ctrl+i can come back to it.
+		m.jumps = append(m.jumps, m.stream.Pos())
+	}
+	m.jumpIdx--
+	m.stream.Seek(m.jumps[m.jumpIdx])
+}
+
+// jumpForward undoes jumpBack.
+func (m *model) jumpForward() {
+	if m.jumpIdx >= len(m.jumps)-1 {
+		return
+	}
+	m.jumpIdx++
+	m.stream.Seek(m.jumps[m.jumpIdx])
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds the reader's key bindings. Every action can be rebound in the
+// config file under "keys", by the names in keyMap.actions.
+type keyMap struct {
+	Quit             key.Binding
+	PlayPause        key.Binding
+	Faster           key.Binding
+	Slower           key.Binding
+	FasterFine       key.Binding
+	SlowerFine       key.Binding
+	Preset           key.Binding
+	Back             key.Binding
+	Forward          key.Binding
+	SkipBack         key.Binding
+	SkipForward      key.Binding
+	SentenceStart    key.Binding
+	SentenceBack     key.Binding
+	SentenceForward  key.Binding
+	ParagraphBack    key.Binding
+	ParagraphForward key.Binding
+	ChapterBack      key.Binding
+	ChapterForward   key.Binding
+	Rewind           key.Binding
+	Skim             key.Binding
+	Reticle          key.Binding
+	Theme            key.Binding
+	Profile          key.Binding
+	Neighbors        key.Binding
+	Sparkline        key.Binding
+	Context          key.Binding
+	Pronunciation    key.Binding
+	Text             key.Binding
+	Zen              key.Binding
+	Clauses          key.Binding
+	Percent          key.Binding
+	GoToWord         key.Binding
+	Search           key.Binding
+	SearchBack       key.Binding
+	NextMatch        key.Binding
+	PrevMatch        key.Binding
+	JumpBack         key.Binding
+	JumpForward      key.Binding
+	Bookmark         key.Binding
+	NextBookmark     key.Binding
+	Marks            key.Binding
+	Unknown          key.Binding
+	Define           key.Binding
+	ABLoop           key.Binding
+	Restart          key.Binding
+	Reload           key.Binding
+	Debug            key.Binding
+	Undo             key.Binding
+	Loop             key.Binding
+
+	// The translate keys do nothing until "translate" is set up in the
+	// config file.
+	Translate         key.Binding
+	TranslateSentence key.Binding
+}
+
+func newKeyMap() keyMap {
+	bind := func(desc string, keys ...string) key.Binding {
+		return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keys[0], desc))
+	}
+	return keyMap{
+		Quit:             bind("quit", "q", "ctrl+c"),
+		PlayPause:        bind("play/pause", " "),
+		Faster:           bind("speed up", "+", "=", "up"),
+		Slower:           bind("slow down", "-", "_", "down"),
+		FasterFine:       bind("speed up a little", "shift+up"),
+		SlowerFine:       bind("slow down a little", "shift+down"),
+		Preset:           bind("speed preset", "1", "2", "3", "4", "5", "6", "7", "8", "9"),
+		Back:             bind("step back", "h", "left"),
+		Forward:          bind("step forward", "l", "right"),
+		SkipBack:         bind("skip back", "pgup", "ctrl+b"),
+		SkipForward:      bind("skip forward", "pgdown", "ctrl+f"),
+		SentenceStart:    bind("start of sentence", "0", "^"),
+		SentenceBack:     bind("previous sentence", "("),
+		SentenceForward:  bind("next sentence", ")"),
+		ParagraphBack:    bind("previous paragraph", "{"),
+		ParagraphForward: bind("next paragraph", "}"),
+		ChapterBack:      bind("previous chapter", "["),
+		ChapterForward:   bind("next chapter", "]"),
+		Rewind:           bind("rewind", "<"),
+		Skim:             bind("skim", "s"),
+		Reticle:          bind("reticle", "|"),
+		Theme:            bind("next theme", "T"),
+		Profile:          bind("next profile", "P"),
+		Neighbors:        bind("neighbor words", "g"),
+		Sparkline:        bind("speed sparkline", "W"),
+		Context:          bind("context pane", "C"),
+		Pronunciation:    bind("pronunciation", "i"),
+		Text:             bind("full text", "v"),
+		Zen:              bind("zen mode", "z"),
+		Clauses:          bind("clauses", "c"),
+		Percent:          bind("jump to percentage", "%"),
+		GoToWord:         bind("go to word", ":"),
+		Search:           bind("search", "/"),
+		SearchBack:       bind("search backward", "?"),
+		NextMatch:        bind("next match", "n"),
+		PrevMatch:        bind("previous match", "N"),
+		JumpBack:         bind("jump back", "ctrl+o"),
+		JumpForward:      bind("jump forward", "tab"),
+		Bookmark:         bind("bookmark", "b"),
+		NextBookmark:     bind("next bookmark", "B"),
+		Marks:            bind("list bookmarks", "M"),
+		Unknown:          bind("flag unknown word", "f"),
+		Define:           bind("define word", "d"),
+		ABLoop:           bind("A-B loop", "a"),
+		Restart:          bind("restart", "r"),
+		Reload:           bind("reload file", "R"),
+		Debug:            bind("frame timings", "D"),
+		Undo:             bind("undo restart", "u"),
+		Loop:             bind("loop", "L"),
+
+		Translate:         bind("translate word", "y"),
+		TranslateSentence: bind("translate sentence", "Y"),
+	}
+}
+
+// defaultKeys is used by models that were not given a key map.
+var defaultKeys = newKeyMap()
+
+// namedBinding is an action with the name the config file knows it by.
+type namedBinding struct {
+	name    string
+	binding *key.Binding
+}
+
+// bindings lists every action, in the order they are listed in keyMap.
+func (k *keyMap) bindings() []namedBinding {
+	return []namedBinding{
+		{"quit", &k.Quit},
+		{"play_pause", &k.PlayPause},
+		{"faster", &k.Faster},
+		{"slower", &k.Slower},
+		{"faster_fine", &k.FasterFine},
+		{"slower_fine", &k.SlowerFine},
+		{"preset", &k.Preset},
+		{"back", &k.Back},
+		{"forward", &k.Forward},
+		{"skip_back", &k.SkipBack},
+		{"skip_forward", &k.SkipForward},
+		{"sentence_start", &k.SentenceStart},
+		{"sentence_back", &k.SentenceBack},
+		{"sentence_forward", &k.SentenceForward},
+		{"paragraph_back", &k.ParagraphBack},
+		{"paragraph_forward", &k.ParagraphForward},
+		{"chapter_back", &k.ChapterBack},
+		{"chapter_forward", &k.ChapterForward},
+		{"rewind", &k.Rewind},
+		{"skim", &k.Skim},
+		{"reticle", &k.Reticle},
+		{"theme", &k.Theme},
+		{"profile", &k.Profile},
+		{"neighbors", &k.Neighbors},
+		{"sparkline", &k.Sparkline},
+		{"context", &k.Context},
+		{"pronunciation", &k.Pronunciation},
+		{"text", &k.Text},
+		{"zen", &k.Zen},
+		{"clauses", &k.Clauses},
+		{"percent", &k.Percent},
+		{"go_to_word", &k.GoToWord},
+		{"search", &k.Search},
+		{"search_back", &k.SearchBack},
+		{"next_match", &k.NextMatch},
+		{"prev_match", &k.PrevMatch},
+		{"jump_back", &k.JumpBack},
+		{"jump_forward", &k.JumpForward},
+		{"bookmark", &k.Bookmark},
+		{"next_bookmark", &k.NextBookmark},
+		{"marks", &k.Marks},
+		{"unknown_word", &k.Unknown},
+		{"define", &k.Define},
+		{"ab_loop", &k.ABLoop},
+		{"restart", &k.Restart},
+		{"reload", &k.Reload},
+		{"debug", &k.Debug},
+		{"undo", &k.Undo},
+		{"loop", &k.Loop},
+		{"translate", &k.Translate},
+		{"translate_sentence", &k.TranslateSentence},
+	}
+}
+
+// actions names every binding for the config file.
+func (k *keyMap) actions() map[string]*key.Binding {
+	actions := map[string]*key.Binding{}
+	for _, b := range k.bindings() {
+		actions[b.name] = b.binding
+	}
+	return actions
+}
+
+// keyMapFrom applies the bindings from the config file on top of the
+// defaults. Keys are written the way bubbletea names them, e.g. "ctrl+f" or
+// "pgdown", with "space" for the space bar. An empty list unbinds an action.
+func keyMapFrom(bindings map[string][]string) (keyMap, error) {
+	k := newKeyMap()
+	actions := k.actions()
+	for name, keys := range bindings {
+		b, ok := actions[name]
+		if !ok {
+			return k, fmt.Errorf("unknown action %q in keys", name)
+		}
+		if len(keys) == 0 {
+			b.Unbind()
+			continue
+		}
+		keys = slices.Clone(keys)
+		for i, s := range keys {
+			if s == "space" {
+				keys[i] = " "
+			}
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(keys[0], b.Help().Desc)
+	}
+
+	// A key bound to two actions would only ever trigger one of them.
+	owner := map[string]string{}
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, s := range actions[name].Keys() {
+			if other, taken := owner[s]; taken {
+				return k, fmt.Errorf("key %q is bound to both %s and %s", keyName(s), other, name)
+			}
+			owner[s] = name
+		}
+	}
+	return k, nil
+}
+
+// keyName is how a key is shown in help.
+func keyName(s string) string {
+	if s == " " {
+		return "space"
+	}
+	return s
+}
+
+// helpKey is the first key of a binding, for the controls hint.
+func helpKey(b key.Binding) string {
+	if !b.Enabled() {
+		return ""
+	}
+	return keyName(b.Help().Key)
+}
+
+// keys returns the model's key map.
+func (m model) keys() *keyMap {
+	if m.keyMap != nil {
+		return m.keyMap
+	}
+	return &defaultKeys
+}
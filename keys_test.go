@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyMapFromConfig(t *testing.T) {
+	k, err := keyMapFrom(map[string][]string{
+		"back":       {"j"},
+		"forward":    {"k"},
+		"play_pause": {"p", "space"},
+		"loop":       {},
+	})
+	if err != nil {
+		t.Fatalf("keyMapFrom: %v", err)
+	}
+	m := model{stream: newEagerStream(tokenize("a b c d"), true), keyMap: &k}
+	m = press(m, "k", "k", "j", "l")
+	if m.stream.Pos() != 1 {
+		t.Fatalf("expected j/k to step and l to do nothing, got %d", m.stream.Pos())
+	}
+	if m = press(m, "p"); !m.running {
+		t.Fatal("expected p to start playback")
+	}
+	if got := m.controls(); !strings.HasPrefix(got, "p: play/pause") || !strings.Contains(got, "j/k: back/forward") || strings.Contains(got, "L: loop") {
+		t.Fatalf("expected the hint to follow the bindings, got %q", got)
+	}
+
+	if _, err := keyMapFrom(map[string][]string{"fly": {"f"}}); err == nil {
+		t.Fatal("expected an unknown action to be rejected")
+	}
+	if _, err := keyMapFrom(map[string][]string{"skim": {"h"}}); err == nil {
+		t.Fatal("expected a key bound twice to be rejected")
+	}
+}
+
+func TestWriteKeys(t *testing.T) {
+	k, err := keyMapFrom(map[string][]string{"play_pause": {"p", "space"}, "loop": {}})
+	if err != nil {
+		t.Fatalf("keyMapFrom: %v", err)
+	}
+	var b strings.Builder
+	writeKeys(&b, &k)
+	lines := strings.Split(b.String(), "\n")
+	if len(lines) != len(k.bindings())+2 || !strings.HasPrefix(lines[0], "ACTION") {
+		t.Fatalf("expected a header and a line per action, got %q", b.String())
+	}
+	for _, want := range [][]string{{"play_pause", "p space", "play/pause"}, {"loop", "unbound"}} {
+		found := false
+		for _, line := range lines {
+			if f := strings.Fields(line); len(f) > 0 && f[0] == want[0] {
+				found = strings.Contains(line, want[1]) && (len(want) < 3 || strings.Contains(line, want[2]))
+			}
+		}
+		if !found {
+			t.Errorf("expected %s bound to %q, got %q", want[0], want[1], b.String())
+		}
+	}
+
+	b.Reset()
+	writeKeysMarkdown(&b, &k)
+	if !strings.Contains(b.String(), "| `reticle` | `\\|` | reticle |") {
+		t.Fatalf("expected | to be escaped in markdown, got %q", b.String())
+	}
+}
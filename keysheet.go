@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// runKeys implements `zippy keys`: it prints the key bindings in effect,
+// with the config file's applied, as a reference card.
+func runKeys(args []string) int {
+	fs := flag.NewFlagSet("keys", flag.ExitOnError)
+	var markdown bool
+	fs.BoolVar(&markdown, "markdown", false, "print a markdown table instead of plain columns")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s keys [options]\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Print the key bindings, including those changed in the config file.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read config:", err)
+		return 1
+	}
+	keys, err := keyMapFrom(cfg.Keys)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Config:", err)
+		return 1
+	}
+	if markdown {
+		writeKeysMarkdown(os.Stdout, &keys)
+	} else {
+		writeKeys(os.Stdout, &keys)
+	}
+	return 0
+}
+
+// boundKeys lists the keys of a binding the way the config file writes
+// them, or "unbound".
+func boundKeys(b namedBinding) []string {
+	if !b.binding.Enabled() {
+		return []string{"unbound"}
+	}
+	var names []string
+	for _, s := range b.binding.Keys() {
+		names = append(names, keyName(s))
+	}
+	return names
+}
+
+// writeKeys prints one action per line: its config name, its keys separated
+// by spaces, and what it does.
+func writeKeys(w io.Writer, k *keyMap) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tKEYS\tDESCRIPTION")
+	for _, b := range k.bindings() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", b.name, strings.Join(boundKeys(b), " "), b.binding.Help().Desc)
+	}
+	tw.Flush()
+}
+
+// writeKeysMarkdown prints the same as writeKeys as a markdown table.
+func writeKeysMarkdown(w io.Writer, k *keyMap) {
+	fmt.Fprintln(w, "| Action | Keys | Description |")
+	fmt.Fprintln(w, "| --- | --- | --- |")
+	for _, b := range k.bindings() {
+		keys := boundKeys(b)
+		if b.binding.Enabled() {
+			for i, s := range keys {
+				keys[i] = "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+			}
+		}
+		fmt.Fprintf(w, "| `%s` | %s | %s |\n", b.name, strings.Join(keys, " "), b.binding.Help().Desc)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// docEntry is a document read before, as shown by `zippy list` and `zippy
+// history`.
+type docEntry struct {
+	path  string
+	state docState
+	now   time.Time
+}
+
+func (e docEntry) Title() string       { return filepath.Base(e.path) }
+func (e docEntry) FilterValue() string { return e.path }
+
+func (e docEntry) Description() string {
+	parts := []string{shortenHome(filepath.Dir(e.path))}
+	switch {
+	case e.state.Total > 0 && e.state.Position+1 >= e.state.Total:
+		parts = append(parts, "finished")
+	case e.state.Total > 0:
+		parts = append(parts, fmt.Sprintf("%d%%", (e.state.Position+1)*100/e.state.Total))
+	}
+	if !e.state.LastRead.IsZero() {
+		parts = append(parts, "read "+ago(e.now.Sub(e.state.LastRead)))
+	}
+	if wpm := e.state.averageWPM(); wpm > 0 {
+		parts = append(parts, fmt.Sprintf("%d WPM", wpm))
+	}
+	return strings.Join(parts, "  ·  ")
+}
+
+// docEntries returns the documents that have a saved position, most recently
+// read first.
+func docEntries(store *docStore, now time.Time) []docEntry {
+	return sortedEntries(store, now, func(path string, d *docState) bool { return d.Hash != "" })
+}
+
+// historyEntries returns the documents read before that are still there to
+// open again, most recently read first.
+func historyEntries(store *docStore, now time.Time) []docEntry {
+	return sortedEntries(store, now, func(path string, d *docState) bool {
+		if d.LastRead.IsZero() {
+			return false
+		}
+		_, err := os.Stat(path)
+		return err == nil
+	})
+}
+
+func sortedEntries(store *docStore, now time.Time, keep func(path string, d *docState) bool) []docEntry {
+	var entries []docEntry
+	for path, d := range store.Documents {
+		if keep(path, d) {
+			entries = append(entries, docEntry{path: path, state: *d, now: now})
+		}
+	}
+	slices.SortFunc(entries, func(a, b docEntry) int {
+		if c := b.state.LastRead.Compare(a.state.LastRead); c != 0 {
+			return c
+		}
+		return strings.Compare(a.path, b.path)
+	})
+	return entries
+}
+
+// ago describes a duration in the past coarsely, e.g. "3h ago".
+func ago(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// shortenHome replaces the home directory prefix of a path with ~.
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+// listModel is the `zippy list` picker.
+type listModel struct {
+	docs   list.Model
+	chosen string
+}
+
+func (m listModel) Init() tea.Cmd { return nil }
+
+func (m listModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.docs.SetSize(msg.Width, msg.Height)
+		return m, nil
+	case tea.KeyMsg:
+		if m.docs.FilterState() != list.Filtering {
+			switch msg.String() {
+			case "enter":
+				if e, ok := m.docs.SelectedItem().(docEntry); ok {
+					m.chosen = e.path
+				}
+				return m, tea.Quit
+			case "esc", "q", "ctrl+c":
+				return m, tea.Quit
+			}
+		}
+	}
+	var cmd tea.Cmd
+	m.docs, cmd = m.docs.Update(msg)
+	return m, cmd
+}
+
+func (m listModel) View() string { return m.docs.View() }
+
+// pickMode is how the document to read is picked when none is given.
+type pickMode int
+
+const (
+	pickNone pickMode = iota
+	// pickSaved offers the documents with a saved position, for `zippy
+	// list`.
+	pickSaved
+	// pickHistory offers those read lately, for `zippy history` and when
+	// zippy is run with nothing to read.
+	pickHistory
+)
+
+// entries lists the documents to pick from.
+func (p pickMode) entries(store *docStore, now time.Time) []docEntry {
+	if p == pickHistory {
+		return historyEntries(store, now)
+	}
+	return docEntries(store, now)
+}
+
+// hasHistory reports whether there is anything for pickHistory to offer.
+func hasHistory() bool {
+	store, err := loadDocStore()
+	return err == nil && len(historyEntries(store, time.Now())) > 0
+}
+
+// chooseDocument shows the documents to pick from and returns the one
+// picked to continue reading, or "" if none was.
+func chooseDocument(pick pickMode) (string, error) {
+	store, err := loadDocStore()
+	if err != nil {
+		return "", err
+	}
+	entries := pick.entries(store, time.Now())
+	if len(entries) == 0 {
+		if pick == pickHistory {
+			fmt.Println("Nothing read yet.")
+		} else {
+			fmt.Println("No saved reading positions yet.")
+		}
+		return "", nil
+	}
+	items := make([]list.Item, len(entries))
+	for i, e := range entries {
+		items[i] = e
+	}
+	docs := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	docs.Title = "Continue reading  (enter: open  /: filter  q: quit)"
+	if pick == pickHistory {
+		docs.Title = "Recently read  (enter: resume  /: filter  q: quit)"
+	}
+	docs.SetShowHelp(false)
+	docs.DisableQuitKeybindings()
+	final, err := tea.NewProgram(listModel{docs: docs}).Run()
+	if err != nil {
+		return "", err
+	}
+	return final.(listModel).chosen, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDocEntries(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &docStore{Documents: map[string]*docState{
+		"/books/old.txt":   {Position: 9, Hash: "a", Total: 40, LastRead: now.Add(-50 * time.Hour)},
+		"/books/new.txt":   {Position: 1, Hash: "b", LastRead: now.Add(-90 * time.Minute), WordsRead: 500, ReadTime: 2 * time.Minute},
+		"/books/marks.txt": {Bookmarks: []int{4}},
+	}}
+	entries := docEntries(store, now)
+	if len(entries) != 2 || entries[0].path != "/books/new.txt" || entries[1].path != "/books/old.txt" {
+		t.Fatalf("expected new.txt then old.txt, got %v", entries)
+	}
+	if got := entries[0].Description(); got != "/books  ·  read 1h ago  ·  250 WPM" {
+		t.Fatalf("unexpected description %q", got)
+	}
+	if got := entries[1].Description(); got != "/books  ·  25%  ·  read 2d ago" {
+		t.Fatalf("unexpected description %q", got)
+	}
+}
+
+func TestHistoryEntries(t *testing.T) {
+	dir := t.TempDir()
+	done, reading := filepath.Join(dir, "done.txt"), filepath.Join(dir, "reading.txt")
+	for _, path := range []string{done, reading} {
+		if err := os.WriteFile(path, []byte("words"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &docStore{Documents: map[string]*docState{
+		done:                            {Position: 39, Hash: "a", Total: 40, LastRead: now.Add(-time.Hour)},
+		reading:                         {Position: 9, Hash: "b", Total: 40, LastRead: now.Add(-2 * time.Hour)},
+		"/gone/deleted.txt":             {Position: 9, Hash: "c", Total: 40, LastRead: now},
+		filepath.Join(dir, "marks.txt"): {Bookmarks: []int{4}},
+	}}
+	entries := historyEntries(store, now)
+	if len(entries) != 2 || entries[0].path != done || entries[1].path != reading {
+		t.Fatalf("expected done.txt then reading.txt, got %v", entries)
+	}
+	if got := entries[0].Description(); !strings.HasSuffix(got, "finished  ·  read 1h ago") {
+		t.Fatalf("expected a finished document to say so, got %q", got)
+	}
+}
@@ -1,29 +1,234 @@
 package main
 
 import (
-	"flag"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
+// maxClauseWidth caps how many columns a clause frame may take up.
+const maxClauseWidth = 30
+
+// zenReveal is how long a key press shows the status line in zen mode.
+const zenReveal = 2 * time.Second
+
+// undoWindow is how long after a restart it can still be undone.
+const undoWindow = 5 * time.Second
+
+// skimSpeedup is how much faster than the chosen WPM skim mode plays.
+const skimSpeedup = 2
+
 const (
-	statusGray = "#777777"
-	pivotRed   = "#FF3B30"
+	limitPause = "pause"
+	limitQuit  = "quit"
 )
 
-type tickMsg struct{}
+// tickMsg advances playback. It carries the interval it was scheduled for
+// and when, for the debug overlay.
+type tickMsg struct {
+	interval time.Duration
+	sent     time.Time
+}
 
 type model struct {
+	pacing
+	theme theme
+	// themes are those the theme key cycles through; themeIdx is the one in
+	// use.
+	themes   []themeChoice
+	themeIdx int
+	// profiles are those the profile key cycles through; profileName is the
+	// one in use, if any.
+	profiles    []profileChoice
+	profileName string
+	// base is what switching profiles resets to first; without it a profile
+	// applies on top of the options in use.
+	base   *baseOptions
+	keyMap *keyMap
+	// clock is the wall clock unless a test sets one.
+	clock clock
+	// reticle marks the pivot column above and below the word.
+	reticle reticleMode
+	// showNeighbors shows the words before and after the frame dimly.
+	showNeighbors bool
+	// showSparkline draws the speed over the last few minutes in the
+	// status line.
+	showSparkline bool
+	// showContext adds a pane with the whole current sentence.
+	showContext bool
+	// showIPA writes the pronunciation of the word beneath it, where the
+	// lexicon knows it.
+	showIPA bool
+	lexicon lexicon
+	// debug shows frame timings over the word; see frameStats.
+	debug      bool
+	frameStats frameStats
+	// banner draws the word in large block letters where it fits.
+	banner bool
+	// boxWidth, if set, draws a rounded box this many columns wide around
+	// the word.
+	boxWidth int
+	// presets are the speeds for the preset keys, defaultPresets if nil.
+	presets []int
+	// wheelMode is what the mouse wheel does.
+	wheelMode wheelMode
+	// wordCase recases the words on screen.
+	wordCase caseMode
+	// align and vertical place the word on screen.
+	align    alignment
+	vertical lipgloss.Position
+
+	showText bool
+	text     textView
+
+	// zen hides the status line and progress bar; keys bring them back until
+	// revealUntil.
+	zen         bool
+	revealUntil time.Time
+
 	stream  stream
 	running bool
-	wpm     int
 	width   int
 	height  int
+	loop    bool
+
+	prompt promptKind
+	input  textinput.Model
+	// hits is the search preview for the query typed so far.
+	hits searchHits
+	// notice is a one-off message shown in the status line until the next
+	// key press.
+	notice string
+
+	lastSearch     string
+	searchBackward bool
+
+	// docKey identifies the input in the state store; it is empty for stdin.
+	docKey string
+	// reopen builds a fresh stream from the file, for reloading it; it is
+	// nil for stdin.
+	reopen reopenFunc
+	// watch is what to do when the file changes on disk; changedOnDisk is
+	// set once it has, until it is reloaded.
+	watch         watchMode
+	changedOnDisk bool
+	bookmarks     []int
+	unknown       []unknownWord
+	showMarks     bool
+	marks         list.Model
+
+	// jumps is the jumplist of positions that non-linear moves started from.
+	// jumpIdx is where ctrl+o and ctrl+i are in it; len(jumps) when at the
+	// newest position.
+	jumps   []int
+	jumpIdx int
+
+	// skipStep is how many words pgup/pgdown jump by.
+	skipStep int
+	// wpmStep and fineStep are how much the speed keys change the WPM by,
+	// unshifted and shifted; see speedStep.
+	wpmStep, fineStep int
+	// minWPM and maxWPM bound the speed; see wpmBounds.
+	minWPM, maxWPM int
+
+	// askResume is set while the user is being asked whether to resume at
+	// the saved position resumePos. resumeStale means the document changed
+	// since, so the place has to be looked for again by its resumeContext.
+	askResume     bool
+	resumePos     int
+	resumeTotal   int
+	resumeStale   bool
+	resumeContext []string
+
+	// preflight is the summary shown before reading starts, until space
+	// begins playback; nil once it is dismissed, or with -no-preflight.
+	preflight *preflight
+
+	// dictionary looks up the current word for the define key, and
+	// translator translates it or its sentence for the translate keys; each
+	// is nil if not set up. lookup is the overlay showing the answer.
+	dictionary dictionary
+	translator *translator
+	lookup     *lookup
+
+	// mirror is the web page following the display for -serve, or nil.
+	mirror *mirror
+	// status is where `zippy status` finds what is being read, or nil.
+	status *statusFile
+	// hooks are the commands run on reading events.
+	hooks hooks
+	// media shows playback to the system's media controls, or is nil.
+	media mediaPlayer
+
+	// seekOnLoad is where to jump to once the stream has its first word, if
+	// loadSeek is set.
+	loadSeek   bool
+	seekOnLoad startPoint
+
+	// undoPos is where playback was before the last restart, which u can
+	// return to until undoUntil.
+	undoPos   int
+	undoUntil time.Time
+
+	// rewinding plays backwards until the start of the previous sentence.
+	rewinding bool
+
+	// markA and markB bound the A-B repeat loop; -1 means unset.
+	markA int
+	markB int
+
+	// duration is the reading budget; zero means unlimited. elapsed counts
+	// only time spent playing, so pauses do not eat into the budget.
+	// budgetFrom and wordsFrom are the playing time and words read when
+	// the limits began; carrying on past a limit starts them again.
+	duration     time.Duration
+	elapsed      time.Duration
+	playingSince time.Time
+	budgetFrom   time.Duration
+	maxWords     int
+	wordsRead    int
+	wordsFrom    int
+	onLimit      string
+	// curve follows the speed over the session for the statistics store.
+	curve speedCurve
+	// timing logs the frames shown for -timing-log; nil without it.
+	timing *timingLog
+
+	// goal is the daily reading goal; goalWords and goalTime are what was
+	// read today before this session, and goalMet is set once it is reached.
+	goal      goal
+	goalWords int
+	goalTime  time.Duration
+	goalMet   bool
+	// limitReached holds a short notice once a reading limit stopped playback.
+	limitReached string
+
+	// breakEvery and breakWords call for a break after that long or that
+	// many words of reading without a pause. stretchStart is wordsRead when
+	// playback last started; stretch and stretchWords describe the stretch
+	// read when the break began.
+	breakEvery   time.Duration
+	breakWords   int
+	onBreak      bool
+	stretchStart int
+	stretch      time.Duration
+	stretchWords int
+
+	// noConfirm quits without asking first, for -yes. confirmQuit is set
+	// while asking; quitWasRunning is whether to carry on playing if the
+	// answer is no.
+	noConfirm      bool
+	confirmQuit    bool
+	quitWasRunning bool
 }
 
 func (m model) Init() tea.Cmd {
@@ -34,69 +239,388 @@ func (m model) Init() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		next tea.Model
+		cmd  tea.Cmd
+	)
+	if _, ok := msg.(tea.KeyMsg); ok && m.zen {
+		// Any key briefly brings back the status line.
+		m.revealUntil = m.now().Add(zenReveal)
+		next, cmd = m.update(msg)
+		cmd = tea.Batch(cmd, m.tick(zenReveal, func(time.Time) tea.Msg { return revealMsg{} }))
+	} else {
+		next, cmd = m.update(msg)
+	}
+	if nm, ok := next.(model); ok {
+		nm.fireHooks(m)
+		if ss, ok := nm.stream.(shortSeeker); ok && ss.seekedShort() {
+			nm.notice = "Still loading; this is as far as the input has come"
+		}
+		next = nm
+		if nm.mirror != nil {
+			nm.mirror.publish(nm.mirrorFrame())
+			nm.mirror.measure(nm.readingMetrics())
+		}
+		if nm.status != nil {
+			nm.status.write(nm.liveStatus(), nm.now())
+		}
+		if nm.media != nil {
+			nm.media.update(nm.mediaState())
+		}
+	}
+	return next, cmd
+}
+
+// revealMsg redraws the screen once the status line revealed in zen mode is
+// due to disappear again.
+type revealMsg struct{}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return m, m.handleMouse(msg)
+	case mediaMsg:
+		return m, m.handleMedia(msg)
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		m.notice = ""
+		if m.askResume {
+			return m, m.answerResume(msg)
+		}
+		if m.preflight != nil {
+			return m, m.handlePreflightKey(msg)
+		}
+		if m.onBreak {
+			return m, m.handleBreakKey(msg)
+		}
+		if m.lookup != nil {
+			return m, m.handleLookupKey(msg)
+		}
+		if m.confirmQuit {
+			return m, m.answerQuit(msg)
+		}
+		if m.prompt != promptNone {
+			return m, m.handlePromptKey(msg)
+		}
+		if m.showMarks {
+			return m, m.handleMarksKey(msg)
+		}
+		if m.showText {
+			return m, m.handleTextKey(msg)
+		}
+		k := m.keys()
+		switch {
+		case msg.String() == "ctrl+c":
 			return m, tea.Quit
-		case " ":
-			m.running = !m.running
+		case key.Matches(msg, k.Quit):
+			return m, m.quit()
+		case key.Matches(msg, k.PlayPause):
+			return m, m.togglePlay()
+		case key.Matches(msg, k.Preset):
+			return m, m.usePreset(msg.String())
+		case key.Matches(msg, k.Faster, k.Slower, k.FasterFine, k.SlowerFine):
+			step := m.speedStep(key.Matches(msg, k.FasterFine, k.SlowerFine))
+			if key.Matches(msg, k.Slower, k.SlowerFine) {
+				step = -step
+			}
+			m.adjustWPM(step)
 			if m.running {
-				return m, tickCmd(m.wordInterval())
+				return m, m.tickCmd(m.frameInterval())
 			}
 			return m, nil
-		case "+", "=", "up":
-			m.adjustWPM(25)
+		case key.Matches(msg, k.Forward):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			return m, m.stream.Next()
+		case key.Matches(msg, k.Back):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			m.stream.Prev()
+			return m, nil
+		case key.Matches(msg, k.Loop):
+			if m.stream == nil || !m.stream.SupportsRestart() {
+				return m, nil
+			}
+			m.loop = !m.loop
+			return m, nil
+		case key.Matches(msg, k.Rewind):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			if m.rewinding {
+				m.rewinding = false
+				m.setRunning(false)
+				return m, nil
+			}
+			m.rewinding = true
+			m.setRunning(true)
+			return m, m.tickCmd(m.frameInterval())
+		case key.Matches(msg, k.Clauses):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			m.clauses = !m.clauses
 			if m.running {
-				return m, tickCmd(m.wordInterval())
+				return m, m.tickCmd(m.frameInterval())
 			}
 			return m, nil
-		case "-", "_", "down":
-			m.adjustWPM(-25)
+		case key.Matches(msg, k.Reload):
+			return m, m.reloadFile()
+		case key.Matches(msg, k.Debug):
+			m.toggleDebug()
+			return m, nil
+		case key.Matches(msg, k.Theme):
+			m.cycleTheme()
+			return m, nil
+		case key.Matches(msg, k.Profile):
+			m.cycleProfile()
+			return m, nil
+		case key.Matches(msg, k.Reticle):
+			m.reticle = m.reticle.next()
+			return m, nil
+		case key.Matches(msg, k.Neighbors):
+			m.showNeighbors = !m.showNeighbors
+			return m, nil
+		case key.Matches(msg, k.Sparkline):
+			m.showSparkline = !m.showSparkline
+			return m, nil
+		case key.Matches(msg, k.Text):
+			if m.stream == nil || !m.stream.SupportsSeek() || m.width == 0 {
+				return m, nil
+			}
+			m.setRunning(false)
+			m.rewinding = false
+			m.openText()
+			return m, nil
+		case key.Matches(msg, k.Zen):
+			m.zen = !m.zen
+			m.revealUntil = time.Time{}
+			return m, nil
+		case key.Matches(msg, k.Context):
+			m.showContext = !m.showContext
+			return m, nil
+		case key.Matches(msg, k.Pronunciation):
+			m.showIPA = !m.showIPA
+			return m, nil
+		case key.Matches(msg, k.Skim):
+			m.skim = !m.skim
 			if m.running {
-				return m, tickCmd(m.wordInterval())
+				return m, m.tickCmd(m.frameInterval())
 			}
 			return m, nil
-		case "right", "l":
+		case key.Matches(msg, k.SkipBack, k.SkipForward):
 			if m.stream == nil || !m.stream.SupportsSeek() {
 				return m, nil
 			}
-			m.stream.Next()
+			step := m.skipStep
+			if key.Matches(msg, k.SkipBack) {
+				step = -step
+			}
+			m.stream.Seek(m.stream.Pos() + step)
 			return m, nil
-		case "left", "h":
+		case key.Matches(msg, k.SentenceStart):
 			if m.stream == nil || !m.stream.SupportsSeek() {
 				return m, nil
 			}
-			m.stream.Prev()
+			m.sentenceStart()
+			return m, nil
+		case key.Matches(msg, k.SentenceBack, k.SentenceForward):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			if key.Matches(msg, k.SentenceBack) {
+				m.sentenceBack()
+			} else {
+				m.sentenceForward()
+			}
+			return m, nil
+		case key.Matches(msg, k.ParagraphBack, k.ParagraphForward):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			if key.Matches(msg, k.ParagraphBack) {
+				m.paragraphBack()
+			} else {
+				m.paragraphForward()
+			}
+			return m, nil
+		case key.Matches(msg, k.ChapterBack, k.ChapterForward):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			if key.Matches(msg, k.ChapterBack) {
+				m.chapterBack()
+			} else {
+				m.chapterForward()
+			}
+			return m, nil
+		case key.Matches(msg, k.Percent):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			return m, m.openPrompt(promptPercent)
+		case key.Matches(msg, k.Search, k.SearchBack):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			if key.Matches(msg, k.Search) {
+				return m, m.openPrompt(promptSearch)
+			}
+			return m, m.openPrompt(promptSearchBack)
+		case key.Matches(msg, k.NextMatch, k.PrevMatch):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			m.jump(func() { m.repeatSearch(key.Matches(msg, k.PrevMatch)) })
 			return m, nil
-		case "r":
-			// Restart is only available for file input; stdin cannot be replayed.
+		case key.Matches(msg, k.Bookmark):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			m.toggleBookmark()
+			return m, nil
+		case key.Matches(msg, k.Unknown):
+			if m.stream == nil {
+				return m, nil
+			}
+			m.toggleUnknown()
+			return m, nil
+		case key.Matches(msg, k.Define):
+			if m.stream == nil {
+				return m, nil
+			}
+			return m, m.define()
+		case key.Matches(msg, k.Translate, k.TranslateSentence):
+			if m.stream == nil {
+				return m, nil
+			}
+			return m, m.translate(key.Matches(msg, k.TranslateSentence))
+		case key.Matches(msg, k.NextBookmark):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			m.jump(m.nextBookmark)
+			return m, nil
+		case key.Matches(msg, k.JumpBack, k.JumpForward):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			if key.Matches(msg, k.JumpBack) {
+				m.jumpBack()
+			} else {
+				m.jumpForward()
+			}
+			return m, nil
+		case key.Matches(msg, k.Marks):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			m.openMarks()
+			return m, nil
+		case key.Matches(msg, k.GoToWord):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			return m, m.openPrompt(promptWord)
+		case key.Matches(msg, k.Undo):
+			if m.stream == nil || m.now().After(m.undoUntil) {
+				return m, nil
+			}
+			m.stream.Seek(m.undoPos)
+			m.undoUntil = time.Time{}
+			m.notice = fmt.Sprintf("Back at word %d", m.undoPos+1)
+			return m, nil
+		case key.Matches(msg, k.ABLoop):
+			if m.stream == nil || !m.stream.SupportsSeek() {
+				return m, nil
+			}
+			m.cycleMarks()
+			return m, nil
+		case key.Matches(msg, k.Restart):
+			// Restart is not available for lazily streamed stdin unless it is
+			// spooled, since a pipe cannot be replayed.
 			if m.stream == nil || !m.stream.SupportsRestart() {
 				return m, nil
 			}
+			if seeksAnywhere(m.stream) {
+				m.undoPos = m.stream.Pos()
+				m.undoUntil = m.now().Add(undoWindow)
+				m.notice = fmt.Sprintf("Restarted; press u within %s to undo", undoWindow)
+			} else {
+				m.notice = "Restarted; undo is not supported for this input with -lazy"
+			}
 			cmd := m.stream.Restart()
 			if m.running && cmd == nil {
-				return m, tickCmd(m.wordInterval())
+				return m, m.tickCmd(m.frameInterval())
 			}
 			return m, cmd
 		}
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		if m.showMarks {
+			m.sizeMarks()
+		}
+		if m.showText {
+			m.text.viewport.Width, m.text.viewport.Height = m.width, m.textHeight()
+		}
 		return m, nil
 	case tickMsg:
+		m.frameStats.record(msg, m.now())
 		if !m.running {
 			return m, nil
 		}
-		if m.stream == nil || !m.stream.CanAdvance() {
-			m.running = false
+		m.timing.close(m.now())
+		m.curve.record(m.playedFor(), m.wordsRead)
+		if m.budgetExhausted() {
+			return m, m.stopAtLimit("Time is up")
+		}
+		if m.maxWords > 0 && m.wordsRead-m.wordsFrom >= m.maxWords {
+			return m, m.stopAtLimit(fmt.Sprintf("Read %d words", m.maxWords))
+		}
+		if m.breakDue() {
+			m.takeBreak()
+			return m, nil
+		}
+		if m.stream == nil {
+			m.setRunning(false)
+			return m, nil
+		}
+		if m.rewinding {
+			return m, m.rewindStep()
+		}
+		if m.markB >= 0 && m.stream.Pos() >= m.markB {
+			m.wordsRead++
+			m.stream.Seek(m.markA)
+			return m, m.tickCmd(m.frameInterval())
+		}
+		if !m.canAdvance() {
+			if m.loop && m.stream.SupportsRestart() {
+				m.wordsRead++
+				if cmd := m.stream.Restart(); cmd != nil {
+					return m, cmd
+				}
+				return m, m.tickCmd(m.frameInterval())
+			}
+			m.setRunning(false)
 			return m, nil
 		}
-		cmd := m.stream.Next()
+		m.wordsRead += len(m.frame())
+		m.checkGoal()
+		cmd := m.advance()
 		if cmd != nil {
 			return m, cmd
 		}
-		return m, tickCmd(m.wordInterval())
+		return m, m.tickCmd(m.frameInterval())
+	case placeMsg:
+		m.placeFound(msg)
+		return m, nil
+	case lookupMsg:
+		m.handleLookup(msg)
+		return m, nil
+	case fileChangedMsg:
+		return m, m.fileChanged()
 	case tokenMsg:
 		if m.stream == nil {
 			return m, nil
@@ -105,17 +629,36 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if cmd != nil {
 			return m, cmd
 		}
+		if _, ok := m.stream.Current(); ok && m.loadSeek {
+			m.loadSeek = false
+			m.startAt(m.seekOnLoad)
+		}
+		if m.running && m.skipCurrent() && m.stream.CanAdvance() {
+			if cmd := m.advance(); cmd != nil {
+				return m, cmd
+			}
+		}
 		if m.running {
 			if _, ok := m.stream.Current(); ok {
-				return m, tickCmd(m.wordInterval())
+				return m, m.tickCmd(m.frameInterval())
 			}
 			if !m.stream.CanAdvance() {
-				m.running = false
+				m.setRunning(false)
 			}
 		}
 		return m, nil
 	}
 
+	if m.prompt != promptNone {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	if m.showMarks {
+		var cmd tea.Cmd
+		m.marks, cmd = m.marks.Update(msg)
+		return m, cmd
+	}
 	return m, nil
 }
 
@@ -126,8 +669,20 @@ func (m model) View() string {
 	if err := m.stream.Err(); err != nil {
 		return fmt.Sprintf("Error: %v", err)
 	}
-	word, ok := m.stream.Current()
-	if !ok {
+	if m.askResume && m.width > 0 && m.height > 0 {
+		return m.theme.place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.theme.text().Render(m.resumeQuestion()))
+	}
+	if m.preflight != nil && m.width > 0 && m.height > 0 {
+		return m.preflightScreen()
+	}
+	if m.onBreak && m.width > 0 && m.height > 0 {
+		return m.breakScreen()
+	}
+	if m.lookup != nil && m.width > 0 && m.height > 0 {
+		return m.lookupScreen()
+	}
+	frame := m.frame()
+	if len(frame) == 0 {
 		if !m.stream.CanAdvance() {
 			return "No words to display."
 		}
@@ -137,68 +692,549 @@ func (m model) View() string {
 		return "Loading..."
 	}
 
+	if m.showText {
+		return m.text.viewport.View() + "\n" + m.theme.status().Width(m.width).Render(truncate(m.textStatus(), m.width))
+	}
+
+	hideStatus := m.statusHidden()
+
 	contentHeight := m.height
-	if contentHeight > 1 {
+	if contentHeight > 1 && !hideStatus {
+		contentHeight--
+	}
+	showProgress := m.showProgress() && !hideStatus
+	if showProgress {
 		contentHeight--
 	}
 
-	block := formatWord(word, m.width)
-	body := lipgloss.Place(m.width, contentHeight, lipgloss.Left, lipgloss.Center, block)
+	paneHeight := 0
+	if m.showContext {
+		paneHeight = contextPaneHeight(contentHeight)
+		contentHeight -= paneHeight
+	}
 
+	var prev, next string
+	if m.showNeighbors {
+		prev, next = m.neighbors(len(frame))
+	}
+	wordWidth, wordHeight := m.width, contentHeight
+	boxed := m.boxFits(contentHeight)
+	if boxed {
+		wordWidth, wordHeight = m.boxWidth-2, contentHeight-2
+	}
+	word := m.wordCase.apply(frameText(frame))
+	prev, next = m.wordCase.apply(prev), m.wordCase.apply(next)
+	block, pivotCol := formatWord(word, prev, next, wordWidth, m.align, m.theme)
+	if m.banner {
+		if big, col, ok := formatBanner(word, wordWidth, wordHeight, m.align, m.theme); ok {
+			block, pivotCol = big, col
+		}
+	}
+	hPos := lipgloss.Left
+	switch {
+	case boxed:
+		block, hPos = m.boxed(block, pivotCol, wordWidth), lipgloss.Center
+	case m.reticle != reticleOff && contentHeight >= 3:
+		above, below := m.reticleLines(pivotCol)
+		block = above + "\n" + block + "\n" + below
+	}
+	if m.showIPA && lipgloss.Height(block) < contentHeight {
+		col, width := pivotCol, lipgloss.Width(block)
+		if boxed {
+			col++
+		} else {
+			// The block is left-aligned, so the line can run past the end
+			// of a short word without moving it.
+			width = max(width, wordWidth)
+		}
+		block += "\n" + m.pronunciationLine(frame, col, width)
+	}
+	body := m.theme.place(m.width, contentHeight, hPos, m.vertical, block)
+	if m.prompt.isSearch() {
+		body = m.theme.place(m.width, contentHeight, lipgloss.Center, lipgloss.Center, m.searchPreview())
+	}
+	if m.showMarks {
+		body = m.theme.place(m.width, contentHeight, lipgloss.Center, lipgloss.Center, m.overlay(m.marks.View()))
+	}
+	if m.debug && contentHeight >= 3 {
+		body = m.withDebug(body)
+	}
+	if paneHeight > 0 {
+		body += "\n" + m.contextPane(paneHeight, len(frame))
+	}
+
+	if hideStatus {
+		return body
+	}
+	statusLine := m.theme.status().Width(m.width).Render(truncate(m.statusText(), m.width))
+
+	if showProgress {
+		return body + "\n" + m.progressBar() + "\n" + statusLine
+	}
+	if contentHeight < m.height {
+		return body + "\n" + statusLine
+	}
+	return body
+}
+
+// statusHidden reports whether zen mode hides everything but the word, as it
+// does unless a key was just pressed or a prompt is open.
+func (m model) statusHidden() bool {
+	return m.zen && m.now().After(m.revealUntil) && m.prompt == promptNone && !m.confirmQuit
+}
+
+// statusText is the status line: the reading state, followed by the key hint.
+func (m model) statusText() string {
 	total := "?"
 	if known, count := m.stream.Total(); known {
 		total = fmt.Sprintf("%d", count)
 	}
-	controls := "space: play/pause  +/-: speed"
+	status := fmt.Sprintf("WPM %d  %d/%s", m.wpm, m.stream.Pos()+1, total)
+	if eta, ok := m.eta(); ok {
+		status += fmt.Sprintf("  %s to go", eta.Round(time.Second))
+	}
+	if played := m.playedFor(); played > 0 {
+		status += fmt.Sprintf("  %s read", played.Round(time.Second))
+		if wpm, ok := m.effectiveWPM(); ok {
+			status += fmt.Sprintf(" at %d WPM", wpm)
+		}
+		if spark := m.sparkline(); m.showSparkline && spark != "" {
+			status += " " + spark
+		}
+	}
+	if g := m.goalStatus(); g != "" {
+		status += "  " + g
+	}
+	status += "  " + m.controls()
+	if m.duration > 0 {
+		status = fmt.Sprintf("%s left  %s", m.remaining().Round(time.Second), status)
+	}
+	if tok, ok := m.stream.Current(); ok && tok.chapter != "" {
+		status = tok.chapter + "  " + status
+	}
+	if m.loop {
+		status = "Loop  " + status
+	}
+	if m.skim {
+		status = "Skim  " + status
+	}
+	if m.rewinding {
+		status = "Rewind  " + status
+	}
+	if m.clauses {
+		status = "Clauses  " + status
+	}
+	switch {
+	case m.markB >= 0:
+		status = fmt.Sprintf("A-B %d-%d  %s", m.markA+1, m.markB+1, status)
+	case m.markA >= 0:
+		status = fmt.Sprintf("A %d  %s", m.markA+1, status)
+	}
+	if m.limitReached != "" {
+		status = m.limitReached + "  " + status
+	}
+	if m.changedOnDisk {
+		status = fmt.Sprintf("File changed, %s to reload  %s", helpKey(m.keys().Reload), status)
+	}
+	if m.notice != "" {
+		status = m.notice + "  " + status
+	}
+	if m.prompt != promptNone {
+		status = m.promptLine()
+	}
+	if m.confirmQuit {
+		status = m.quitQuestion()
+	}
+	return status
+}
+
+// controlHint is an entry of the key hint at the end of the status line,
+// naming the keys of its bindings joined by sep.
+type controlHint struct {
+	desc     string
+	sep      string
+	bindings []key.Binding
+}
+
+// bound returns the hint's bindings that have keys.
+func (h controlHint) bound() []key.Binding {
+	var bound []key.Binding
+	for _, b := range h.bindings {
+		if helpKey(b) != "" {
+			bound = append(bound, b)
+		}
+	}
+	return bound
+}
+
+func (h controlHint) String() string {
+	var keys []string
+	for _, b := range h.bound() {
+		keys = append(keys, helpKey(b))
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	return strings.Join(keys, h.sep) + ": " + h.desc
+}
+
+// controlHints follow the key map, so that rebound keys show up.
+func (m model) controlHints() []controlHint {
+	k := m.keys()
+	var hints []controlHint
+	hint := func(desc, sep string, bindings ...key.Binding) {
+		hints = append(hints, controlHint{desc: desc, sep: sep, bindings: bindings})
+	}
+	hint("play/pause", "", k.PlayPause)
+	hint("speed", "/", k.Faster, k.Slower)
+	hint("skim", "", k.Skim)
 	if m.stream.SupportsSeek() {
-		controls += "  h/l: back/forward"
+		hint("clauses", "", k.Clauses)
+		hint("back/forward", "/", k.Back, k.Forward)
+		hint("sentence", "/", k.SentenceBack, k.SentenceForward)
+		hint("paragraph", "/", k.ParagraphBack, k.ParagraphForward)
+		hint("chapter", "/", k.ChapterBack, k.ChapterForward)
+		hint("rewind", "", k.Rewind)
+		hint("jump", "", k.Percent)
+		hint("go to word", "", k.GoToWord)
+		hint("search", "", k.Search, k.SearchBack)
+		hint("bookmarks", "/", k.Bookmark, k.NextBookmark, k.Marks)
+		hint("unknown word", "", k.Unknown)
+		if m.dictionary != nil {
+			hint("define", "", k.Define)
+		}
+		if m.translator != nil {
+			hint("translate", "/", k.Translate, k.TranslateSentence)
+		}
+		hint("A-B loop", "", k.ABLoop)
 	}
 	if m.stream.SupportsRestart() {
-		controls += "  r: restart"
+		hint("restart", "", k.Restart)
+		hint("loop", "", k.Loop)
 	}
-	controls += "  q: quit"
-	status := fmt.Sprintf("WPM %d  %d/%s  %s", m.wpm, m.stream.Pos()+1, total, controls)
-	statusLine := lipgloss.NewStyle().Foreground(lipgloss.Color(statusGray)).Render(truncate(status, m.width))
+	hint("quit", "", k.Quit)
+	return hints
+}
 
-	if contentHeight < m.height {
-		return body + "\n" + statusLine
+// controls is the key hint at the end of the status line.
+func (m model) controls() string {
+	var hints []string
+	for _, h := range m.controlHints() {
+		if s := h.String(); s != "" {
+			hints = append(hints, s)
+		}
 	}
-	return body
+	return strings.Join(hints, "  ")
 }
 
 func (m model) wordInterval() time.Duration {
 	if m.wpm <= 0 {
 		return time.Second
 	}
-	return time.Minute / time.Duration(m.wpm)
+	interval := time.Minute / time.Duration(m.wpm)
+	if m.skim {
+		interval /= skimSpeedup
+	}
+	return interval
+}
+
+// frameInterval is how long the current frame stays on screen, scaled by the
+// number of words it holds.
+func (m model) frameInterval() time.Duration {
+	return m.wordInterval() * time.Duration(max(len(m.frame()), 1))
+}
+
+// canAdvance reports whether there is anything left after the current frame.
+func (m model) canAdvance() bool {
+	if !m.stream.CanAdvance() {
+		return false
+	}
+	n := len(m.frame())
+	if n <= 1 {
+		return true
+	}
+	_, ok := m.stream.Peek(n)
+	return ok
+}
+
+// frame returns the words displayed together starting at the current
+// position. Streams that cannot look ahead always yield single words.
+func (m model) frame() []token {
+	tok, ok := m.stream.Current()
+	if !ok {
+		return nil
+	}
+	frame := []token{tok}
+	if !m.clauses {
+		for len(frame) < m.chunk {
+			next, ok := m.stream.Peek(len(frame))
+			if !ok {
+				break
+			}
+			frame = append(frame, next)
+		}
+		return frame
+	}
+	limit := maxClauseWidth
+	if m.width > 0 {
+		limit = min(limit, m.width/2)
+	}
+	width := len([]rune(tok.text))
+	for !endsClause(tok.text) {
+		next, ok := m.stream.Peek(len(frame))
+		if !ok || next.sentenceStart() {
+			break
+		}
+		width += 1 + len([]rune(next.text))
+		if width > limit {
+			break
+		}
+		frame = append(frame, next)
+		tok = next
+	}
+	return frame
+}
+
+func frameText(frame []token) string {
+	words := make([]string, len(frame))
+	for i, tok := range frame {
+		words[i] = tok.text
+	}
+	return strings.Join(words, " ")
 }
 
-func tickCmd(interval time.Duration) tea.Cmd {
-	return tea.Tick(interval, func(time.Time) tea.Msg {
-		return tickMsg{}
+// advance moves to the next word worth displaying. Streams that fetch words
+// asynchronously return a command instead; skipped words are then dealt with
+// once the token arrives.
+func (m *model) advance() tea.Cmd {
+	var cmd tea.Cmd
+	for range len(m.frame()) {
+		if cmd = m.stream.Next(); cmd != nil {
+			return cmd
+		}
+	}
+	for cmd == nil && m.skipCurrent() && m.stream.CanAdvance() {
+		cmd = m.stream.Next()
+	}
+	return cmd
+}
+
+// skipCurrent reports whether skim mode hides the current word.
+func (m model) skipCurrent() bool {
+	if !m.skim {
+		return false
+	}
+	tok, ok := m.stream.Current()
+	return ok && tok.sentenceWord >= m.skimWords
+}
+
+// rewindStep moves one word backwards, pausing once the start of a sentence
+// is reached.
+func (m *model) rewindStep() tea.Cmd {
+	if m.stream.Pos() > 0 {
+		m.stream.Prev()
+		m.wordsRead++
+		if tok, ok := m.stream.Current(); ok && !tok.sentenceStart() {
+			return m.tickCmd(m.wordInterval())
+		}
+	}
+	m.rewinding = false
+	m.setRunning(false)
+	return nil
+}
+
+// cycleMarks steps through setting A, setting B and clearing the A-B loop.
+func (m *model) cycleMarks() {
+	pos := m.stream.Pos()
+	switch {
+	case m.markA < 0:
+		m.markA = pos
+	case m.markB < 0:
+		m.markA, m.markB = min(m.markA, pos), max(m.markA, pos)
+	default:
+		m.markA, m.markB = -1, -1
+	}
+}
+
+// togglePlay starts or pauses playback, stopping a rewind.
+func (m *model) togglePlay() tea.Cmd {
+	m.rewinding = false
+	m.setRunning(!m.running)
+	if m.running {
+		return m.tickCmd(m.frameInterval())
+	}
+	return nil
+}
+
+// setRunning starts or pauses playback, keeping the elapsed reading time
+// in sync so that only time spent playing counts towards -duration.
+func (m *model) setRunning(running bool) {
+	if running == m.running {
+		return
+	}
+	now := m.now()
+	if running {
+		if m.limitReached != "" {
+			m.limitReached = ""
+			m.budgetFrom, m.wordsFrom = m.elapsed, m.wordsRead
+		}
+		m.playingSince = now
+		m.stretchStart = m.wordsRead
+	} else {
+		m.elapsed += now.Sub(m.playingSince)
+		m.timing.close(now)
+	}
+	m.running = running
+}
+
+func (m model) playedFor() time.Duration {
+	if m.running {
+		return m.elapsed + m.since(m.playingSince)
+	}
+	return m.elapsed
+}
+
+func (m model) remaining() time.Duration {
+	return max(m.duration-(m.playedFor()-m.budgetFrom), 0)
+}
+
+func (m model) budgetExhausted() bool {
+	return m.duration > 0 && m.remaining() == 0
+}
+
+// stopAtLimit pauses playback, or quits when -on-limit=quit was requested.
+// Playing on afterwards grants the same time or words again.
+func (m *model) stopAtLimit(notice string) tea.Cmd {
+	m.setRunning(false)
+	m.limitReached = notice
+	if m.onLimit == limitQuit {
+		return tea.Quit
+	}
+	return nil
+}
+
+func (m model) summary() string {
+	s := fmt.Sprintf("Read %d words in %s", m.wordsRead, m.playedFor().Round(time.Second))
+	if wpm, ok := m.effectiveWPM(); ok {
+		s += fmt.Sprintf(" (%d WPM)", wpm)
+	}
+	return s + "."
+}
+
+// minMeasured is how long playback has to run before the measured speed
+// means anything.
+const minMeasured = 5 * time.Second
+
+// effectiveWPM is the speed actually read at while playing, which falls short
+// of the target once pacing slows some frames down.
+func (m model) effectiveWPM() (int, bool) {
+	played := m.playedFor()
+	if played < minMeasured {
+		return 0, false
+	}
+	return int(float64(m.wordsRead) / played.Minutes()), true
+}
+
+func (m model) tickCmd(interval time.Duration) tea.Cmd {
+	m.frameStats.sent()
+	msg := tickMsg{interval: interval, sent: m.now()}
+	if m.running {
+		m.timing.show(m.frame(), m.stream.Pos(), msg.sent, interval)
+	}
+	return m.tick(interval, func(time.Time) tea.Msg {
+		return msg
 	})
 }
 
-func (m *model) adjustWPM(delta int) {
-	m.wpm += delta
-	if m.wpm < 50 {
-		m.wpm = 50
+// defaultPresets are the speeds the preset keys, 1 to 9, switch to.
+var defaultPresets = []int{250, 300, 350, 400, 500, 600, 700, 800, 900}
+
+// usePreset switches to the speed for the preset key s, which picks a preset
+// by its place among the preset binding's keys.
+func (m *model) usePreset(s string) tea.Cmd {
+	presets := m.presets
+	if presets == nil {
+		presets = defaultPresets
+	}
+	i := slices.Index(m.keys().Preset.Keys(), s)
+	if i < 0 || i >= len(presets) {
+		return nil
+	}
+	m.wpm = presets[i]
+	m.adjustWPM(0)
+	m.notice = fmt.Sprintf("%d WPM", m.wpm)
+	if m.running {
+		return m.tickCmd(m.frameInterval())
+	}
+	return nil
+}
+
+const (
+	defaultWPMStep  = 25
+	defaultFineStep = 5
+)
+
+// speedStep is how much the speed keys change the WPM by, or their shifted
+// variants if fine.
+func (m model) speedStep(fine bool) int {
+	if fine {
+		if m.fineStep > 0 {
+			return m.fineStep
+		}
+		return defaultFineStep
+	}
+	if m.wpmStep > 0 {
+		return m.wpmStep
+	}
+	return defaultWPMStep
+}
+
+// Default bounds for the speed keys.
+const (
+	defaultMinWPM = 50
+	defaultMaxWPM = 1200
+)
+
+// wpmBounds are the slowest and fastest the speed can be changed to.
+func (m model) wpmBounds() (lo, hi int) {
+	lo, hi = defaultMinWPM, defaultMaxWPM
+	if m.minWPM > 0 {
+		lo = m.minWPM
+	}
+	if m.maxWPM > 0 {
+		hi = m.maxWPM
 	}
-	if m.wpm > 1200 {
-		m.wpm = 1200
+	return lo, hi
+}
+
+// clampWPM brings a starting speed within the bounds, with a notice saying
+// so when it had to.
+func clampWPM(wpm, lo, hi int) (int, string) {
+	clamped := min(max(wpm, lo), hi)
+	if clamped == wpm {
+		return wpm, ""
 	}
+	return clamped, fmt.Sprintf("%d WPM is outside the bounds of %d to %d; reading at %d WPM", wpm, lo, hi, clamped)
+}
+
+func (m *model) adjustWPM(delta int) {
+	lo, hi := m.wpmBounds()
+	m.wpm = min(max(m.wpm+delta, lo), hi)
 }
 
-func formatWord(word string, width int) string {
+// formatWord lays out a frame with its pivot letter highlighted, returning
+// the line and the column the pivot ends up in. prev and next, if given, are
+// shown dimly to either side where they fit.
+func formatWord(word, prev, next string, width int, align alignment, t theme) (string, int) {
 	if width <= 0 {
-		return word
+		return word, 0
 	}
 	runes := []rune(word)
 	if len(runes) == 0 {
-		return ""
+		return "", 0
 	}
 
-	pivot := pivotIndex(len(runes))
+	pivot := framePivot(runes)
 	if pivot >= len(runes) {
 		pivot = len(runes) - 1
 	}
@@ -207,17 +1243,40 @@ func formatWord(word string, width int) string {
 	pivotRune := string(runes[pivot])
 	rightRunes := runes[pivot+1:]
 
-	pivotStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(pivotRed)).Bold(true)
-
 	left := string(leftRunes)
 	right := string(rightRunes)
 
-	center := width / 2
-	leftPad := max(center-lipgloss.Width(left), 0)
+	leftPad := max(align.pivotColumn(width, lipgloss.Width(left))-lipgloss.Width(left), 0)
 
-	padding := strings.Repeat(" ", leftPad)
-	line := padding + left + pivotStyle.Render(pivotRune) + right
-	return line
+	before := t.text().Render(strings.Repeat(" ", leftPad))
+	if w := lipgloss.Width(prev); prev != "" && w+neighborGap <= leftPad {
+		before = t.text().Render(strings.Repeat(" ", leftPad-w-neighborGap)) +
+			t.dim().Render(prev) + t.text().Render(strings.Repeat(" ", neighborGap))
+	}
+	after := ""
+	end := leftPad + lipgloss.Width(word)
+	if next != "" && end+neighborGap+lipgloss.Width(next) <= width {
+		after = t.text().Render(strings.Repeat(" ", neighborGap)) + t.dim().Render(next)
+	}
+	line := before + t.text().Render(left) + t.pivot().Render(pivotRune) + t.text().Render(right) + after
+	return truncate(line, width), leftPad + lipgloss.Width(left)
+}
+
+// framePivot places the pivot inside the word nearest the middle of a
+// multi-word frame, falling back to pivotIndex for single words.
+func framePivot(runes []rune) int {
+	center := len(runes) / 2
+	start := 0
+	for i, r := range runes {
+		if r != ' ' {
+			continue
+		}
+		if i >= center {
+			return start + pivotIndex(i-start)
+		}
+		start = i + 1
+	}
+	return start + pivotIndex(len(runes)-start)
 }
 
 func pivotIndex(length int) int {
@@ -235,57 +1294,18 @@ func pivotIndex(length int) int {
 	}
 }
 
+// truncate fits s into width terminal columns, ending it with an ellipsis if
+// anything was cut. It measures display width and leaves ANSI escapes intact,
+// so styled text keeps its closing reset.
 func truncate(s string, width int) string {
 	if width <= 0 {
 		return ""
 	}
-	if lipgloss.Width(s) <= width {
-		return s
-	}
-	runes := []rune(s)
-	if len(runes) <= width {
-		return s
-	}
-	return string(runes[:width])
+	return ansi.Truncate(s, width, ellipsis)
 }
 
+const ellipsis = "…"
+
 func main() {
-	var (
-		wpm  int
-		file string
-		lazy bool
-	)
-	flag.IntVar(&wpm, "wpm", 500, "starting words per minute")
-	flag.StringVar(&file, "file", "", "path to input text")
-	flag.BoolVar(&lazy, "lazy", false, "stream tokens lazily without buffering; disables back/forward")
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
-		fmt.Fprintln(os.Stderr, "Input can be provided via -file or by piping text into stdin.")
-		fmt.Fprintln(os.Stderr)
-		flag.PrintDefaults()
-	}
-	flag.Parse()
-	if wpm <= 0 {
-		fmt.Fprintln(os.Stderr, "WPM must be greater than 0.")
-		flag.PrintDefaults()
-		os.Exit(1)
-	}
-
-	stream, err := buildStream(lazy, file)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		if initErr, ok := err.(streamInitError); ok && initErr.showUsage {
-			flag.PrintDefaults()
-		}
-		os.Exit(1)
-	}
-
-	p := tea.NewProgram(model{
-		wpm:    wpm,
-		stream: stream,
-	})
-	if _, err := p.Run(); err != nil {
-		fmt.Fprintln(os.Stderr, "Error:", err)
-		os.Exit(1)
-	}
+	os.Exit(runMain(func() int { return run(os.Args[1:]) }))
 }
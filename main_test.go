@@ -0,0 +1,716 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestClauseFrames(t *testing.T) {
+	m := model{
+		stream: newEagerStream(tokenize("When it rains, we stay in. Then we go"), false),
+		pacing: pacing{clauses: true},
+	}
+	var got []string
+	for {
+		got = append(got, frameText(m.frame()))
+		if !m.canAdvance() {
+			break
+		}
+		m.advance()
+	}
+	want := []string{"When it rains,", "we stay in.", "Then we go"}
+	if len(got) != len(want) {
+		t.Fatalf("expected frames %q, got %q", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected frames %q, got %q", want, got)
+		}
+	}
+}
+
+func TestFixedChunkFrames(t *testing.T) {
+	m := model{
+		stream: newEagerStream(tokenize("one two three four five"), false),
+		pacing: pacing{chunk: 2},
+	}
+	want := []string{"one two", "three four", "five"}
+	for i, w := range want {
+		if got := frameText(m.frame()); got != w {
+			t.Fatalf("frame %d: expected %q, got %q", i, w, got)
+		}
+		if i < len(want)-1 {
+			if !m.canAdvance() {
+				t.Fatalf("frame %d: expected to advance", i)
+			}
+			m.advance()
+		}
+	}
+	if m.canAdvance() {
+		t.Fatal("expected no frames after the last one")
+	}
+	if got := m.frameInterval(); got != m.wordInterval() {
+		t.Fatalf("expected a single-word frame to last one word interval, got %v", got)
+	}
+}
+
+func TestFramePivotUsesMiddleWord(t *testing.T) {
+	runes := []rune("the quick brown")
+	if got := framePivot(runes); runes[got] != 'u' {
+		t.Fatalf("expected pivot on 'u' of quick, got %q", runes[got])
+	}
+}
+
+func press(m model, keys ...string) model {
+	for _, k := range keys {
+		next, _ := m.Update(keyMsg(k))
+		m = next.(model)
+	}
+	return m
+}
+
+func TestPercentPromptSeeks(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c d e f g h i j k"), false)}
+	m = press(m, "%", "5", "0", "enter")
+	if m.prompt != promptNone {
+		t.Fatal("expected prompt to close after enter")
+	}
+	if m.stream.Pos() != 5 {
+		t.Fatalf("expected position 5, got %d", m.stream.Pos())
+	}
+	m = press(m, "%", "2", "0", "%")
+	if m.prompt != promptNone || m.stream.Pos() != 2 {
+		t.Fatalf("expected %% to submit and move to 2, got %d", m.stream.Pos())
+	}
+	m = press(m, "%", "9", "esc")
+	if m.stream.Pos() != 2 {
+		t.Fatalf("expected escape to leave position alone, got %d", m.stream.Pos())
+	}
+}
+
+func TestWordPromptSeeks(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c d e"), false)}
+	m = press(m, ":", "4", "enter")
+	if m.stream.Pos() != 3 {
+		t.Fatalf("expected position 3, got %d", m.stream.Pos())
+	}
+}
+
+func TestSentenceNavigation(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("One two. Three four five. Six."), false)}
+	m = press(m, ")")
+	if m.stream.Pos() != 2 {
+		t.Fatalf("expected next sentence at 2, got %d", m.stream.Pos())
+	}
+	m = press(m, ")", ")")
+	if m.stream.Pos() != 5 {
+		t.Fatalf("expected to stay on last sentence at 5, got %d", m.stream.Pos())
+	}
+	m.stream.Seek(4)
+	m = press(m, "(")
+	if m.stream.Pos() != 2 {
+		t.Fatalf("expected start of current sentence at 2, got %d", m.stream.Pos())
+	}
+	m = press(m, "(")
+	if m.stream.Pos() != 0 {
+		t.Fatalf("expected previous sentence at 0, got %d", m.stream.Pos())
+	}
+	m.stream.Seek(4)
+	m = press(m, "0", "^")
+	if m.stream.Pos() != 2 {
+		t.Fatalf("expected 0 and ^ to stay at the sentence start 2, got %d", m.stream.Pos())
+	}
+}
+
+func TestUndoRestart(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c d"), true)}
+	m.stream.Seek(2)
+	m = press(m, "r")
+	if m.stream.Pos() != 0 {
+		t.Fatalf("expected restart to go to 0, got %d", m.stream.Pos())
+	}
+	m = press(m, "u")
+	if m.stream.Pos() != 2 {
+		t.Fatalf("expected undo to return to 2, got %d", m.stream.Pos())
+	}
+	m.stream.Seek(3)
+	m = press(m, "u")
+	if m.stream.Pos() != 3 {
+		t.Fatalf("expected a second undo to do nothing, got %d", m.stream.Pos())
+	}
+}
+
+func TestRestartWithoutUndoOnUnseekableLazyInput(t *testing.T) {
+	path := writeTemp(t, "a b c")
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Behind a NopCloser the stream cannot tell it is a file.
+	s := newLazyStream(io.NopCloser(f), path)
+	t.Cleanup(func() { f.Close(); s.Close() })
+	m := model{stream: s}
+	m = press(m, "r")
+	if !m.undoUntil.IsZero() || !strings.Contains(m.notice, "not supported") {
+		t.Fatalf("expected no undo to be offered, got %q", m.notice)
+	}
+}
+
+func TestStartAt(t *testing.T) {
+	for _, tc := range []struct {
+		flag string
+		want int
+	}{
+		{"3", 2},
+		{"50%", 4},
+		{"Chapter two", 5},
+	} {
+		p, err := parseStartAt(tc.flag)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.flag, err)
+		}
+		m := model{stream: newEagerStream(tokenize("Chapter one. a b c. Chapter two. d e"), true)}
+		m.startAt(p)
+		if m.stream.Pos() != tc.want {
+			t.Errorf("-start-at %q: expected word %d, got %d", tc.flag, tc.want, m.stream.Pos())
+		}
+	}
+	for _, bad := range []string{"0", "120%", "..."} {
+		if _, err := parseStartAt(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestSkipStep(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c d e f g h i j k l"), false), skipStep: 5}
+	m = press(m, "pgdown")
+	if m.stream.Pos() != 5 {
+		t.Fatalf("expected pgdown to skip to 5, got %d", m.stream.Pos())
+	}
+	m = press(m, "ctrl+f", "ctrl+f")
+	if m.stream.Pos() != 11 {
+		t.Fatalf("expected to stop at the last word, got %d", m.stream.Pos())
+	}
+	m = press(m, "pgup", "ctrl+b", "ctrl+b")
+	if m.stream.Pos() != 0 {
+		t.Fatalf("expected to stop at the first word, got %d", m.stream.Pos())
+	}
+}
+
+func TestJumplist(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c d e f g h i j"), false)}
+	m = press(m, ":", "5", "enter", "l", "%", "1", "0", "0", "enter")
+	if m.stream.Pos() != 9 {
+		t.Fatalf("expected to end at 9, got %d", m.stream.Pos())
+	}
+	m = press(m, "ctrl+o")
+	if m.stream.Pos() != 5 {
+		t.Fatalf("expected ctrl+o to return to 5, got %d", m.stream.Pos())
+	}
+	m = press(m, "ctrl+o", "ctrl+o")
+	if m.stream.Pos() != 0 {
+		t.Fatalf("expected ctrl+o to stop at 0, got %d", m.stream.Pos())
+	}
+	m = press(m, "tab", "tab", "tab")
+	if m.stream.Pos() != 9 {
+		t.Fatalf("expected tab to return to 9, got %d", m.stream.Pos())
+	}
+	m = press(m, "ctrl+o", ":", "2", "enter", "tab")
+	if m.stream.Pos() != 1 || len(m.jumps) != 2 {
+		t.Fatalf("expected a new jump to drop the forward history, got %d %v", m.stream.Pos(), m.jumps)
+	}
+}
+
+func TestMouseSeeking(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c d e f g h i j k"), false), width: 21, height: 5}
+	click := func(m model, msg tea.MouseMsg) model {
+		next, _ := m.Update(msg)
+		return next.(model)
+	}
+	m = click(m, tea.MouseMsg{Button: tea.MouseButtonWheelDown})
+	m = click(m, tea.MouseMsg{Button: tea.MouseButtonWheelDown})
+	m = click(m, tea.MouseMsg{Button: tea.MouseButtonWheelUp})
+	if m.stream.Pos() != 1 {
+		t.Fatalf("expected the wheel to step to 1, got %d", m.stream.Pos())
+	}
+	m = click(m, tea.MouseMsg{X: 10, Y: 4, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if m.stream.Pos() != 1 {
+		t.Fatalf("expected clicks off the progress bar to be ignored, got %d", m.stream.Pos())
+	}
+	m = click(m, tea.MouseMsg{X: 10, Y: 3, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if m.stream.Pos() != 5 {
+		t.Fatalf("expected a click halfway along to seek to 5, got %d", m.stream.Pos())
+	}
+	m = click(m, tea.MouseMsg{X: 20, Y: 3, Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion})
+	if m.stream.Pos() != 10 {
+		t.Fatalf("expected dragging to the end to seek to 10, got %d", m.stream.Pos())
+	}
+	if m = press(m, "ctrl+o"); m.stream.Pos() != 1 {
+		t.Fatalf("expected the click to be recorded as a jump, got %d", m.stream.Pos())
+	}
+}
+
+func TestMouseClicks(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c d e f g h i j k"), false), width: 200, height: 5, pacing: pacing{wpm: 300}, markA: -1, markB: -1}
+	click := func(m model, x, y int) model {
+		next, _ := m.Update(tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+		return next.(model)
+	}
+	if m = click(m, 5, 1); !m.running {
+		t.Fatal("expected a click on the word to start playback")
+	}
+	if m = click(m, 5, 1); m.running {
+		t.Fatal("expected a second click to pause")
+	}
+	status := m.statusText()
+	if m = click(m, strings.Index(status, "h/l")+2, 4); m.stream.Pos() != 1 {
+		t.Fatalf("expected a click on l to step forward, got %d", m.stream.Pos())
+	}
+	if m = click(m, strings.Index(status, ": back/forward")+3, 4); m.stream.Pos() != 0 {
+		t.Fatalf("expected a click on the description to step back, got %d", m.stream.Pos())
+	}
+	if m = click(m, strings.Index(status, "space")+1, 4); !m.running {
+		t.Fatal("expected a click on space to start playback")
+	}
+
+	m.wheelMode = wheelSpeed
+	next, _ := m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelUp})
+	if m = next.(model); m.wpm != 325 {
+		t.Fatalf("expected the wheel to speed up to 325, got %d", m.wpm)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c d e"), false), width: 8, height: 5}
+	m.stream.Seek(2)
+	if f, ok := m.progress(); !ok || f != 0.5 {
+		t.Fatalf("expected progress 0.5, got %v ok=%v", f, ok)
+	}
+	if got := ansi.Strip(m.progressBar()); got != "━━━━────" {
+		t.Fatalf("unexpected bar %q", got)
+	}
+	lines := strings.Split(m.View(), "\n")
+	if len(lines) != 5 || ansi.Strip(lines[3]) != "━━━━────" {
+		t.Fatalf("expected the bar above the status line, got %q", lines)
+	}
+
+	lazy := model{stream: newLazyStream(io.NopCloser(strings.NewReader("a b c")), ""), width: 8, height: 5}
+	if lazy.showProgress() {
+		t.Fatal("expected no progress bar while the length of piped input is unknown")
+	}
+}
+
+func TestReticle(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("hello"), false), width: 11, height: 7}
+	m = press(m, "|")
+	lines := strings.Split(ansi.Strip(m.View()), "\n")
+	var word int
+	for i, l := range lines {
+		if strings.Contains(l, "hello") {
+			word = i
+		}
+	}
+	col := strings.Index(lines[word], "hello") + 1
+	if word == 0 || []rune(lines[word-1])[col] != '│' || []rune(lines[word+1])[col] != '│' {
+		t.Fatalf("expected ticks around the pivot column %d, got %q", col, lines)
+	}
+	m = press(m, "|")
+	lines = strings.Split(ansi.Strip(m.View()), "\n")
+	if got := lines[word-1]; got != "───┬───────" {
+		t.Fatalf("expected a rule above the word, got %q", got)
+	}
+	if m = press(m, "|"); m.reticle != reticleOff {
+		t.Fatalf("expected | to cycle back to off, got %v", m.reticle)
+	}
+}
+
+func TestPivotColumnIsFixed(t *testing.T) {
+	for _, word := range []string{"a", "word", "reading", "extraordinarily"} {
+		line, col := formatWord(word, "", "", 60, alignORP, theme{})
+		if col != 20 {
+			t.Errorf("%q: expected the pivot at column 20, got %d", word, col)
+		}
+		runes := []rune(ansi.Strip(line))
+		if pivot := []rune(word)[pivotIndex(len(word))]; runes[col] != pivot {
+			t.Errorf("%q: expected %q at column %d, got %q", word, pivot, col, runes[col])
+		}
+	}
+}
+
+func TestPlacement(t *testing.T) {
+	_, col := formatWord("reading", "", "", 60, alignCenter, theme{})
+	if col != 30 {
+		t.Errorf("center: expected the pivot at column 30, got %d", col)
+	}
+	line, col := formatWord("reading", "", "", 60, alignLeft, theme{})
+	if !strings.HasPrefix(ansi.Strip(line), "  reading") || col != leftMargin+pivotIndex(len("reading")) {
+		t.Errorf("left: expected the word at the margin, got %q with the pivot at %d", ansi.Strip(line), col)
+	}
+	if _, err := parseAlign("right"); err == nil {
+		t.Error("expected an unknown alignment to be rejected")
+	}
+	if parseVertical(1.5) == nil {
+		t.Error("expected -vertical 1.5 to be rejected")
+	}
+
+	m := model{stream: newEagerStream(tokenize("word"), false), width: 20, height: 6, vertical: 1}
+	lines := strings.Split(ansi.Strip(m.View()), "\n")
+	if !strings.Contains(lines[len(lines)-3], "word") {
+		t.Fatalf("expected the word at the bottom, got %q", lines)
+	}
+}
+
+func TestWordCase(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("Reading quickly"), false), width: 30, height: 3, wordCase: caseUpper}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "READING") {
+		t.Fatalf("expected the word in upper case, got %q", view)
+	}
+	if tok, _ := m.stream.Current(); tok.text != "Reading" {
+		t.Fatalf("expected the token to keep its casing, got %q", tok.text)
+	}
+	if got := caseSmallCaps.apply("Quiz!"); got != "Qᴜɪᴢ!" {
+		t.Fatalf("expected small caps, got %q", got)
+	}
+	if got := caseLower.apply("ÉTÉ"); got != "été" {
+		t.Fatalf("expected lower case, got %q", got)
+	}
+}
+
+func TestBox(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("reading"), false), width: 20, height: 6, vertical: 0.5, boxWidth: 14, reticle: reticleTicks}
+	lines := strings.Split(ansi.Strip(m.View()), "\n")
+	want := []string{
+		"   ╭────┬───────╮   ",
+		"   │  reading   │   ",
+		"   ╰────┴───────╯   ",
+	}
+	for i, w := range want {
+		if lines[i] != w {
+			t.Fatalf("line %d: expected %q, got %q", i, w, lines[i])
+		}
+	}
+	// A box wider than the screen is left out.
+	m.width = 12
+	if strings.Contains(m.View(), "╭") {
+		t.Fatal("expected no box when it does not fit")
+	}
+}
+
+func TestNeighborWords(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("the quick brown fox"), false), width: 40, height: 3}
+	m.stream.Seek(1)
+	m = press(m, "g")
+	line := strings.Split(ansi.Strip(m.View()), "\n")[0]
+	if want := "      the   quick   brown"; !strings.HasPrefix(line, want) {
+		t.Fatalf("expected %q, got %q", want, line)
+	}
+	// Words that do not fit beside the frame are left out.
+	line, _ = formatWord("quick", "extraordinarily", "", 20, alignORP, theme{})
+	if strings.Contains(line, "extra") {
+		t.Fatalf("expected the long neighbor to be dropped, got %q", line)
+	}
+}
+
+func TestContextPane(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("First one. The quick brown fox jumps. Next."), false), width: 20, height: 13}
+	m.stream.Seek(4)
+	m = press(m, "C")
+	lines := strings.Split(ansi.Strip(m.View()), "\n")
+	if len(lines) != 13 {
+		t.Fatalf("expected the view to fill the screen, got %d lines", len(lines))
+	}
+	pane := strings.Join(lines[8:11], "\n")
+	if !strings.HasPrefix(lines[8], "────") || !strings.Contains(pane, "The quick brown") || !strings.Contains(pane, "jumps.") {
+		t.Fatalf("expected the pane to show the sentence, got %q", lines)
+	}
+	if strings.Contains(pane, "First") || strings.Contains(pane, "Next") {
+		t.Fatalf("expected only the current sentence, got %q", pane)
+	}
+}
+
+func TestTextView(t *testing.T) {
+	words := strings.Repeat("alpha beta gamma delta ", 40)
+	m := model{stream: newEagerStream(tokenize(words), false), width: 24, height: 7}
+	m.stream.Seek(80)
+	m = press(m, "v")
+	if !m.showText || !strings.Contains(ansi.Strip(m.View()), "Full text") {
+		t.Fatal("expected v to open the full text")
+	}
+	m = press(m, "v")
+	if m.showText || m.stream.Pos() != 80 {
+		t.Fatalf("expected to stay at 80 without scrolling, got %d", m.stream.Pos())
+	}
+	m = press(m, "v", "j", "j", "esc")
+	if m.showText || m.stream.Pos() <= 80 || m.stream.Pos() > 96 {
+		t.Fatalf("expected to resume a couple of lines further on, got %d", m.stream.Pos())
+	}
+	if m = press(m, "ctrl+o"); m.stream.Pos() != 80 {
+		t.Fatalf("expected ctrl+o to return to 80, got %d", m.stream.Pos())
+	}
+}
+
+func TestQuitFromTextView(t *testing.T) {
+	words := strings.Repeat("alpha beta gamma delta ", 40)
+	m := model{stream: newEagerStream(tokenize(words), false), width: 24, height: 7}
+	m.stream.Seek(80)
+	m = press(m, "v")
+	if _, cmd := m.Update(keyMsg("ctrl+c")); cmd == nil {
+		t.Fatal("expected ctrl+c to quit from the full text")
+	} else if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Fatal("expected ctrl+c to quit from the full text")
+	}
+	m = press(m, "q")
+	if !m.confirmQuit || !strings.Contains(ansi.Strip(m.View()), "Quit at word 81") {
+		t.Fatalf("expected q to ask before quitting, got %q", ansi.Strip(m.View()))
+	}
+	if m = press(m, "n"); m.confirmQuit || !m.showText {
+		t.Fatal("expected n to go back to the full text")
+	}
+}
+
+func TestZenMode(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c"), false), width: 30, height: 5}
+	m = press(m, "z")
+	if view := ansi.Strip(m.View()); strings.Contains(view, "WPM") || strings.Count(view, "\n") != 4 {
+		t.Fatalf("expected only the word in zen mode, got %q", view)
+	}
+	m = press(m, "l")
+	if !strings.Contains(ansi.Strip(m.View()), "WPM") {
+		t.Fatal("expected a key to reveal the status line")
+	}
+	m.revealUntil = time.Now().Add(-time.Second)
+	if strings.Contains(ansi.Strip(m.View()), "WPM") {
+		t.Fatal("expected the status line to hide again")
+	}
+}
+
+func TestETA(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize(strings.Repeat("word ", 301)), false), pacing: pacing{wpm: 300}, width: 200, height: 3}
+	m.stream.Seek(1)
+	if eta, ok := m.eta(); !ok || eta != time.Minute {
+		t.Fatalf("expected a minute to go, got %v ok=%v", eta, ok)
+	}
+	m = press(m, "+", "+", "+", "+")
+	if eta, _ := m.eta(); eta != 45*time.Second {
+		t.Fatalf("expected 45s at 400 WPM, got %v", eta)
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "45s to go") {
+		t.Fatal("expected the ETA in the status line")
+	}
+}
+
+func TestEffectiveWPM(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c"), false), width: 200, height: 3}
+	m.elapsed, m.wordsRead = 2*time.Minute, 500
+	if wpm, ok := m.effectiveWPM(); !ok || wpm != 250 {
+		t.Fatalf("expected 250 WPM, got %d ok=%v", wpm, ok)
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "2m0s read at 250 WPM") {
+		t.Fatal("expected elapsed time and measured speed in the status line")
+	}
+	if got := m.summary(); got != "Read 500 words in 2m0s (250 WPM)." {
+		t.Fatalf("unexpected summary %q", got)
+	}
+	m.elapsed = time.Second
+	if _, ok := m.effectiveWPM(); ok {
+		t.Fatal("expected no measurement after a second")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	styled := "\x1b[31mred\x1b[0m words"
+	got := truncate(styled, 6)
+	if ansi.Strip(got) != "red w…" || !strings.Contains(got, "\x1b[0m") {
+		t.Fatalf("expected the escapes kept and an ellipsis, got %q", got)
+	}
+	if got := truncate("日本語のテキスト", 7); ansi.StringWidth(got) > 7 {
+		t.Fatalf("expected at most 7 columns, got %q", got)
+	}
+	if got := truncate("short", 10); got != "short" {
+		t.Fatalf("expected no change, got %q", got)
+	}
+}
+
+func TestWPMPresets(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c"), false), width: 80, height: 3, pacing: pacing{wpm: 500}}
+	if m = press(m, "1"); m.wpm != 250 || !strings.Contains(m.View(), "250 WPM") {
+		t.Fatalf("expected preset 1 to switch to 250 WPM, got %d", m.wpm)
+	}
+	m.presets = []int{200, 2000}
+	if m = press(m, "2"); m.wpm != 1200 {
+		t.Fatalf("expected the preset to be clamped to 1200, got %d", m.wpm)
+	}
+	if m = press(m, "3"); m.wpm != 1200 {
+		t.Fatalf("expected a key without a preset to do nothing, got %d", m.wpm)
+	}
+	if _, err := checkPresets([]int{300, 0}); err == nil {
+		t.Fatal("expected a zero preset to be rejected")
+	}
+}
+
+func TestWPMStep(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c"), false), pacing: pacing{wpm: 500}}
+	if m = press(m, "+", "shift+down"); m.wpm != 520 {
+		t.Fatalf("expected the default steps to reach 520, got %d", m.wpm)
+	}
+	m.wpmStep, m.fineStep = 50, 1
+	if m = press(m, "-", "shift+up"); m.wpm != 471 {
+		t.Fatalf("expected the configured steps to reach 471, got %d", m.wpm)
+	}
+}
+
+func TestWPMBounds(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c"), false), pacing: pacing{wpm: 1200}}
+	if m = press(m, "+"); m.wpm != 1200 {
+		t.Fatalf("expected the default bound of 1200, got %d", m.wpm)
+	}
+	m.minWPM, m.maxWPM = 10, 2000
+	if m = press(m, "+"); m.wpm != 1225 {
+		t.Fatalf("expected to go past 1200, got %d", m.wpm)
+	}
+	m.wpm = 30
+	if m = press(m, "-", "-"); m.wpm != 10 {
+		t.Fatalf("expected to stop at the minimum of 10, got %d", m.wpm)
+	}
+}
+
+func TestClampWPM(t *testing.T) {
+	for _, c := range []struct {
+		wpm, want int
+		notice    bool
+	}{{300, 300, false}, {50, 50, false}, {20, 50, true}, {1500, 1200, true}} {
+		got, notice := clampWPM(c.wpm, 50, 1200)
+		if got != c.want || (notice != "") != c.notice {
+			t.Errorf("clampWPM(%d) = %d, %q; want %d", c.wpm, got, notice, c.want)
+		}
+	}
+}
+
+func TestBreakReminder(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c d e f g h"), false), width: 80, height: 10, breakWords: 3, markA: -1, markB: -1}
+	m = press(m, " ")
+	for range 4 {
+		next, _ := m.Update(tickMsg{})
+		m = next.(model)
+	}
+	if !m.onBreak || m.running || m.stream.Pos() != 3 {
+		t.Fatalf("expected a break after 3 words, got break %v, running %v at %d", m.onBreak, m.running, m.stream.Pos())
+	}
+	if view := m.View(); !strings.Contains(view, "Take a break") || !strings.Contains(view, "3 words") {
+		t.Fatalf("expected the break screen, got %q", view)
+	}
+	if m = press(m, "x"); m.onBreak || !m.running {
+		t.Fatal("expected a key to end the break and carry on")
+	}
+	// The count starts over after the break.
+	next, _ := m.Update(tickMsg{})
+	if m = next.(model); m.onBreak {
+		t.Fatal("expected no break right after carrying on")
+	}
+}
+
+func TestQuitConfirmation(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c d e"), false), width: 80, height: 3, docKey: "doc", markA: -1, markB: -1}
+	quit := func(m model, key string) (model, bool) {
+		next, cmd := m.Update(keyMsg(key))
+		return next.(model), cmd != nil && cmd() == tea.Quit()
+	}
+	if _, quits := quit(m, "q"); !quits {
+		t.Fatal("expected q at the start to quit straight away")
+	}
+	m.stream.Seek(2)
+	m, quits := quit(m, "q")
+	if quits || !strings.Contains(m.View(), "Quit at word 3 (50%)? Position saved.") {
+		t.Fatalf("expected to be asked first, got %q", m.View())
+	}
+	if m, quits = quit(m, "n"); quits || m.confirmQuit {
+		t.Fatal("expected n to go back to reading")
+	}
+	m, _ = quit(m, "q")
+	if _, quits = quit(m, "y"); !quits {
+		t.Fatal("expected y to quit")
+	}
+	m.onBreak = true
+	if m, quits = quit(m, "q"); quits || !m.confirmQuit || m.onBreak {
+		t.Fatal("expected q on the break screen to ask first")
+	}
+	if m, quits = quit(m, "n"); quits || m.running {
+		t.Fatal("expected n to go back to reading, paused")
+	}
+	m.lookup = &lookup{text: "b"}
+	if m, quits = quit(m, "q"); quits || !m.confirmQuit || m.lookup != nil {
+		t.Fatal("expected q over a definition to ask first")
+	}
+	if m, quits = quit(m, "n"); quits || m.confirmQuit {
+		t.Fatal("expected n to go back to reading")
+	}
+	m.lookup = &lookup{text: "b"}
+	if _, quits = quit(m, "ctrl+c"); !quits {
+		t.Fatal("expected ctrl+c over a definition to quit")
+	}
+	m.preflight = &preflight{}
+	if m, quits = quit(m, "q"); quits || !m.confirmQuit || m.preflight != nil {
+		t.Fatal("expected q on the summary screen to ask first once past the start")
+	}
+	m, _ = quit(m, "n")
+	m.askResume = true
+	if m, quits = quit(m, "q"); quits || !m.confirmQuit || m.askResume {
+		t.Fatal("expected q at the resume question to ask first once past the start")
+	}
+	m, _ = quit(m, "n")
+	m.askResume = true
+	if _, quits = quit(m, "ctrl+c"); !quits {
+		t.Fatal("expected ctrl+c at the resume question to quit")
+	}
+	m.askResume = false
+	m.confirmQuit, m.noConfirm = false, true
+	if _, quits = quit(m, "q"); !quits {
+		t.Fatal("expected -yes to quit without asking")
+	}
+}
+
+func TestDebugOverlay(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c d e"), false), width: 80, height: 10, markA: -1, markB: -1}
+	m = press(m, "D")
+	if !m.debug || !strings.Contains(m.View(), "debug: no frames yet") {
+		t.Fatalf("expected the debug overlay, got %q", m.View())
+	}
+
+	now := time.Now()
+	m.frameStats.record(tickMsg{interval: 100 * time.Millisecond, sent: now.Add(-250 * time.Millisecond)}, now)
+	m.frameStats.record(tickMsg{interval: 100 * time.Millisecond, sent: now.Add(-100 * time.Millisecond)}, now)
+	if s := m.frameStats; s.frames != 2 || s.dropped != 1 || s.scheduled != 100*time.Millisecond {
+		t.Fatalf("expected one of two frames dropped, got %+v", s)
+	}
+	if !strings.Contains(m.View(), "scheduled 100.0ms") || !strings.Contains(m.View(), "dropped 1/2") {
+		t.Fatalf("expected frame timings, got %q", m.View())
+	}
+
+	m = press(m, "D")
+	if strings.Contains(m.View(), "debug:") {
+		t.Fatal("expected D to hide the overlay")
+	}
+}
+
+func TestDebugQueueCountsTicksPerModel(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a b c"), false), frameStats: frameStats{inFlight: new(atomic.Int64)}}
+	other := model{stream: newEagerStream(tokenize("a b c"), false), frameStats: frameStats{inFlight: new(atomic.Int64)}}
+	m.tickCmd(time.Second)
+	m.tickCmd(time.Second)
+	other.tickCmd(time.Second)
+	m.frameStats.record(tickMsg{interval: time.Second, sent: time.Now()}, time.Now())
+	if got := m.frameStats.String(); !strings.HasSuffix(got, "queue 1") {
+		t.Fatalf("expected one tick left in flight, got %q", got)
+	}
+	if got := other.frameStats.inFlight.Load(); got != 1 {
+		t.Fatalf("expected the other model to count its own tick, got %d", got)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !darwin || !cgo
+
+package main
+
+// runMain runs f; only macOS needs the main thread kept for itself.
+func runMain(f func() int) int {
+	return f()
+}
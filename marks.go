@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// snippetWords is how many words of context a bookmark entry shows after the
+// bookmarked word.
+const snippetWords = 8
+
+type bookmarkItem struct {
+	pos     int
+	snippet string
+}
+
+func (i bookmarkItem) Title() string       { return fmt.Sprintf("Word %d", i.pos+1) }
+func (i bookmarkItem) Description() string { return i.snippet }
+func (i bookmarkItem) FilterValue() string { return i.snippet }
+
+// openMarks shows the bookmark list overlay.
+func (m *model) openMarks() {
+	if len(m.bookmarks) == 0 {
+		m.notice = "No bookmarks"
+		return
+	}
+	items := make([]list.Item, len(m.bookmarks))
+	selected := 0
+	for i, b := range m.bookmarks {
+		items[i] = bookmarkItem{pos: b, snippet: m.snippet(b)}
+		if b <= m.stream.Pos() {
+			selected = i
+		}
+	}
+	m.marks = list.New(items, list.NewDefaultDelegate(), 0, 0)
+	m.marks.Title = "Bookmarks  (enter: jump  d: delete  esc: close)"
+	m.marks.SetShowHelp(false)
+	m.marks.DisableQuitKeybindings()
+	m.marks.Select(selected)
+	m.sizeMarks()
+	m.showMarks = true
+}
+
+func (m *model) sizeMarks() {
+	// Leave room for the overlay's border and padding and the status line.
+	m.marks.SetSize(m.overlayWidth()-2, max(m.height-3, 5))
+}
+
+// handleMarksKey drives the overlay: enter jumps to the selected bookmark and
+// d deletes it.
+func (m *model) handleMarksKey(msg tea.KeyMsg) tea.Cmd {
+	if m.marks.FilterState() != list.Filtering {
+		if key.Matches(msg, m.keys().Marks) {
+			m.showMarks = false
+			return nil
+		}
+		switch msg.String() {
+		case "esc", "q":
+			m.showMarks = false
+			return nil
+		case "enter":
+			if item, ok := m.marks.SelectedItem().(bookmarkItem); ok {
+				m.jump(func() { m.stream.Seek(item.pos) })
+			}
+			m.showMarks = false
+			return nil
+		case "d", "x":
+			item, ok := m.marks.SelectedItem().(bookmarkItem)
+			if !ok {
+				return nil
+			}
+			if i, found := slices.BinarySearch(m.bookmarks, item.pos); found {
+				m.bookmarks = slices.Delete(m.bookmarks, i, i+1)
+				m.saveBookmarks()
+			}
+			m.marks.RemoveItem(m.marks.Index())
+			if len(m.marks.Items()) == 0 {
+				m.showMarks = false
+			}
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	m.marks, cmd = m.marks.Update(msg)
+	return cmd
+}
+
+// snippet returns the bookmarked word followed by a little of the text after
+// it.
+func (m model) snippet(pos int) string {
+	var words []string
+	offset := pos - m.stream.Pos()
+	for i := range snippetWords {
+		tok, ok := m.stream.Peek(offset + i)
+		if !ok {
+			break
+		}
+		words = append(words, tok.text)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mediaAction is what a media key or a media player controller asks for.
+type mediaAction int
+
+const (
+	mediaPlay mediaAction = iota
+	mediaPause
+	mediaPlayPause
+	mediaNext
+	mediaPrevious
+	// mediaSeek moves by offset; mediaSetPosition moves to it.
+	mediaSeek
+	mediaSetPosition
+)
+
+// mediaMsg carries a media action into the program.
+type mediaMsg struct {
+	action mediaAction
+	offset time.Duration
+}
+
+// mediaState is what the media controls are told about playback. Positions
+// are in reading time at the current speed, as players count in time.
+type mediaState struct {
+	Playing  bool
+	Title    string
+	Position time.Duration
+	Length   time.Duration
+}
+
+// mediaPlayer shows zippy to the system's media controls; see
+// startMediaKeys.
+type mediaPlayer interface {
+	update(mediaState)
+	close()
+}
+
+// wordsIn is how many words take d to read at the current speed.
+func (m model) wordsIn(d time.Duration) int {
+	return int(d.Minutes() * float64(m.wpm))
+}
+
+func (m model) mediaState() mediaState {
+	s := mediaState{Playing: m.running, Title: "stdin"}
+	if m.docKey != "" {
+		s.Title = filepath.Base(m.docKey)
+	}
+	if m.stream == nil || m.wpm <= 0 {
+		return s
+	}
+	perWord := time.Minute / time.Duration(m.wpm)
+	s.Position = time.Duration(m.stream.Pos()) * perWord
+	if known, total := m.stream.Total(); known {
+		s.Length = time.Duration(total) * perWord
+	}
+	return s
+}
+
+// handleMedia carries out a media action. Play also starts reading from the
+// summary shown first, as space does; while a question or overlay is open,
+// actions are ignored.
+func (m *model) handleMedia(msg mediaMsg) tea.Cmd {
+	if m.stream == nil || m.askResume || m.onBreak || m.lookup != nil || m.confirmQuit || m.prompt != promptNone {
+		return nil
+	}
+	switch msg.action {
+	case mediaPlay, mediaPause, mediaPlayPause:
+		if (msg.action == mediaPlay && m.running) || (msg.action == mediaPause && !m.running) {
+			return nil
+		}
+		m.preflight = nil
+		return m.togglePlay()
+	}
+	if m.preflight != nil || !m.stream.SupportsSeek() {
+		return nil
+	}
+	switch msg.action {
+	case mediaNext:
+		m.sentenceForward()
+	case mediaPrevious:
+		m.sentenceBack()
+	case mediaSeek:
+		m.stream.Seek(max(m.stream.Pos()+m.wordsIn(msg.offset), 0))
+	case mediaSetPosition:
+		m.stream.Seek(max(m.wordsIn(msg.offset), 0))
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+//go:build cgo
+
+package main
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework Foundation -framework MediaPlayer
+
+#import <Foundation/Foundation.h>
+#import <MediaPlayer/MediaPlayer.h>
+#include <dispatch/dispatch.h>
+#include <stdlib.h>
+#include <string.h>
+#include <unistd.h>
+
+enum {
+	zippyPlay,
+	zippyPause,
+	zippyPlayPause,
+	zippyNext,
+	zippyPrevious,
+	zippySetPosition,
+};
+
+// zippyMediaFD is the pipe the handlers write commands to for Go to read,
+// which spares exporting a Go function to call back, and with it keeping
+// these definitions out of the preamble.
+static int zippyMediaFD = -1;
+
+static void zippySend(char command, double seconds) {
+	char buf[1 + sizeof seconds];
+	buf[0] = command;
+	memcpy(buf + 1, &seconds, sizeof seconds);
+	write(zippyMediaFD, buf, sizeof buf);
+}
+
+static void zippyHandle(MPRemoteCommand *command, char name) {
+	command.enabled = YES;
+	[command addTargetWithHandler:^MPRemoteCommandHandlerStatus(MPRemoteCommandEvent *event) {
+		zippySend(name, 0);
+		return MPRemoteCommandHandlerStatusSuccess;
+	}];
+}
+
+static void zippyMediaStart(int fd) {
+	zippyMediaFD = fd;
+	dispatch_sync(dispatch_get_main_queue(), ^{
+		MPRemoteCommandCenter *center = [MPRemoteCommandCenter sharedCommandCenter];
+		zippyHandle(center.playCommand, zippyPlay);
+		zippyHandle(center.pauseCommand, zippyPause);
+		zippyHandle(center.stopCommand, zippyPause);
+		zippyHandle(center.togglePlayPauseCommand, zippyPlayPause);
+		zippyHandle(center.nextTrackCommand, zippyNext);
+		zippyHandle(center.previousTrackCommand, zippyPrevious);
+		center.changePlaybackPositionCommand.enabled = YES;
+		[center.changePlaybackPositionCommand addTargetWithHandler:^MPRemoteCommandHandlerStatus(MPRemoteCommandEvent *event) {
+			zippySend(zippySetPosition, ((MPChangePlaybackPositionCommandEvent *)event).positionTime);
+			return MPRemoteCommandHandlerStatusSuccess;
+		}];
+	});
+}
+
+static void zippyMediaUpdate(int playing, const char *title, double position, double length) {
+	NSString *name = [NSString stringWithUTF8String:title];
+	dispatch_async(dispatch_get_main_queue(), ^{
+		NSMutableDictionary *info = [NSMutableDictionary dictionary];
+		info[MPMediaItemPropertyTitle] = name;
+		info[MPNowPlayingInfoPropertyElapsedPlaybackTime] = @(position);
+		info[MPNowPlayingInfoPropertyPlaybackRate] = @(playing ? 1.0 : 0.0);
+		if (length > 0) {
+			info[MPMediaItemPropertyPlaybackDuration] = @(length);
+		}
+		MPNowPlayingInfoCenter *center = [MPNowPlayingInfoCenter defaultCenter];
+		center.nowPlayingInfo = info;
+		center.playbackState = playing ? MPNowPlayingPlaybackStatePlaying : MPNowPlayingPlaybackStatePaused;
+	});
+}
+
+static void zippyMediaStop(void) {
+	dispatch_sync(dispatch_get_main_queue(), ^{
+		MPRemoteCommandCenter *center = [MPRemoteCommandCenter sharedCommandCenter];
+		for (MPRemoteCommand *command in @[center.playCommand, center.pauseCommand, center.stopCommand, center.togglePlayPauseCommand, center.nextTrackCommand, center.previousTrackCommand, center.changePlaybackPositionCommand]) {
+			[command removeTarget:nil];
+		}
+		MPNowPlayingInfoCenter *info = [MPNowPlayingInfoCenter defaultCenter];
+		info.nowPlayingInfo = nil;
+		info.playbackState = MPNowPlayingPlaybackStateStopped;
+	});
+	zippyMediaFD = -1;
+}
+*/
+import "C"
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+	"unsafe"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The remote command handlers and the now playing info live on the main
+// queue, which only the main thread serves; zippy runs on other threads
+// while the main one waits in dispatch_main. See runMain.
+func init() {
+	runtime.LockOSThread()
+}
+
+// runMain runs f off the main thread, which is left to serve the main
+// queue, and exits with its status.
+func runMain(f func() int) int {
+	go func() { os.Exit(f()) }()
+	C.dispatch_main()
+	return 0
+}
+
+// nowPlaying is zippy in the Now Playing controls of macOS, which is what
+// media keys, headsets and Control Center control there.
+type nowPlaying struct {
+	r, w *os.File
+
+	mu   sync.Mutex
+	last mediaState
+	// at is when last was told, to tell a seek from the position the
+	// system already counts on from there.
+	at time.Time
+}
+
+// startMediaKeys takes the remote commands of MPRemoteCommandCenter,
+// sending what they ask for to the program. Next and previous move by a
+// sentence and the position slider moves by reading time, as with MPRIS.
+func startMediaKeys(send func(tea.Msg)) (mediaPlayer, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	p := &nowPlaying{r: r, w: w}
+	C.zippyMediaStart(C.int(w.Fd()))
+	go p.listen(send)
+	return p, nil
+}
+
+// listen reads the commands the handlers write, a byte naming the command
+// followed by a position in seconds, until the pipe is closed.
+func (p *nowPlaying) listen(send func(tea.Msg)) {
+	var buf [9]byte
+	for {
+		if _, err := io.ReadFull(p.r, buf[:]); err != nil {
+			return
+		}
+		seconds := math.Float64frombits(binary.NativeEndian.Uint64(buf[1:]))
+		msg := mediaMsg{offset: time.Duration(seconds * float64(time.Second))}
+		switch buf[0] {
+		case C.zippyPlay:
+			msg.action = mediaPlay
+		case C.zippyPause:
+			msg.action = mediaPause
+		case C.zippyPlayPause:
+			msg.action = mediaPlayPause
+		case C.zippyNext:
+			msg.action = mediaNext
+		case C.zippyPrevious:
+			msg.action = mediaPrevious
+		case C.zippySetPosition:
+			msg.action = mediaSetPosition
+		default:
+			continue
+		}
+		send(msg)
+	}
+}
+
+// update tells the system about playback when the status or the document
+// changes, or the position jumps; while playing it counts the position on
+// by itself.
+func (p *nowPlaying) update(s mediaState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	expected := p.last.Position
+	if p.last.Playing {
+		expected += now.Sub(p.at)
+	}
+	if s.Playing == p.last.Playing && s.Title == p.last.Title && s.Length == p.last.Length && (s.Position-expected).Abs() < time.Second && !p.at.IsZero() {
+		return
+	}
+	title := C.CString(s.Title)
+	defer C.free(unsafe.Pointer(title))
+	playing := 0
+	if s.Playing {
+		playing = 1
+	}
+	C.zippyMediaUpdate(C.int(playing), title, C.double(s.Position.Seconds()), C.double(s.Length.Seconds()))
+	p.last, p.at = s, now
+}
+
+func (p *nowPlaying) close() {
+	C.zippyMediaStop()
+	p.w.Close()
+	p.r.Close()
+}
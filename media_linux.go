@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+const (
+	mprisPath   = "/org/mpris/MediaPlayer2"
+	mprisRoot   = "org.mpris.MediaPlayer2"
+	mprisPlayer = "org.mpris.MediaPlayer2.Player"
+	// mprisTrack names the document being read, the only "track" there is.
+	mprisTrack = dbus.ObjectPath("/org/mpris/MediaPlayer2/zippy/document")
+)
+
+// mprisMethodNames maps the Go methods of mprisPlayerMethods that are named
+// differently on the bus.
+var mprisMethodNames = map[string]string{"SeekBy": "Seek"}
+
+// mpris is zippy as an MPRIS media player on the session bus, which is what
+// media keys, desktop widgets and playerctl control on Linux.
+type mpris struct {
+	conn  *dbus.Conn
+	props *prop.Properties
+	send  func(tea.Msg)
+
+	mu   sync.Mutex
+	last mediaState
+}
+
+// startMediaKeys registers zippy on the session bus as
+// org.mpris.MediaPlayer2.zippy, or with an instance suffix if another zippy
+// has the name, sending what it is asked to do to the program.
+func startMediaKeys(send func(tea.Msg)) (mediaPlayer, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+	p := &mpris{conn: conn, send: send}
+	if err := p.export(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	for _, name := range []string{"org.mpris.MediaPlayer2.zippy", fmt.Sprintf("org.mpris.MediaPlayer2.zippy.instance%d", os.Getpid())} {
+		reply, err := conn.RequestName(name, dbus.NameFlagDoNotQueue)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if reply == dbus.RequestNameReplyPrimaryOwner {
+			return p, nil
+		}
+	}
+	conn.Close()
+	return nil, fmt.Errorf("the MPRIS name is taken")
+}
+
+func (p *mpris) export() error {
+	if err := p.conn.Export(mprisRootMethods{}, mprisPath, mprisRoot); err != nil {
+		return err
+	}
+	if err := p.conn.ExportWithMap(mprisPlayerMethods{p}, mprisMethodNames, mprisPath, mprisPlayer); err != nil {
+		return err
+	}
+	props, err := prop.Export(p.conn, mprisPath, prop.Map{
+		mprisRoot: {
+			"Identity":            {Value: "zippy"},
+			"CanQuit":             {Value: false},
+			"CanRaise":            {Value: false},
+			"HasTrackList":        {Value: false},
+			"SupportedUriSchemes": {Value: []string{}},
+			"SupportedMimeTypes":  {Value: []string{}},
+		},
+		mprisPlayer: {
+			"PlaybackStatus": {Value: "Paused", Emit: prop.EmitTrue},
+			"Metadata":       {Value: p.metadata(mediaState{}), Emit: prop.EmitTrue},
+			"Position":       {Value: int64(0), Emit: prop.EmitFalse},
+			"Rate":           {Value: 1.0},
+			"MinimumRate":    {Value: 1.0},
+			"MaximumRate":    {Value: 1.0},
+			"Volume":         {Value: 1.0},
+			"CanGoNext":      {Value: true},
+			"CanGoPrevious":  {Value: true},
+			"CanPlay":        {Value: true},
+			"CanPause":       {Value: true},
+			"CanSeek":        {Value: true},
+			"CanControl":     {Value: true},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	p.props = props
+	playerMethods := introspect.Methods(mprisPlayerMethods{p})
+	for i, method := range playerMethods {
+		if name, ok := mprisMethodNames[method.Name]; ok {
+			playerMethods[i].Name = name
+		}
+	}
+	node := &introspect.Node{
+		Name: mprisPath,
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{Name: mprisRoot, Methods: introspect.Methods(mprisRootMethods{}), Properties: props.Introspection(mprisRoot)},
+			{Name: mprisPlayer, Methods: playerMethods, Properties: props.Introspection(mprisPlayer)},
+		},
+	}
+	return p.conn.Export(introspect.NewIntrospectable(node), mprisPath, "org.freedesktop.DBus.Introspectable")
+}
+
+func (p *mpris) metadata(s mediaState) map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(mprisTrack),
+		"mpris:length":  dbus.MakeVariant(s.Length.Microseconds()),
+		"xesam:title":   dbus.MakeVariant(s.Title),
+	}
+}
+
+// update tells the bus about playback, signalling only when the status or
+// the document changes; players read the position when they need it.
+func (p *mpris) update(s mediaState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.props.SetMust(mprisPlayer, "Position", s.Position.Microseconds())
+	if s.Playing != p.last.Playing {
+		status := "Paused"
+		if s.Playing {
+			status = "Playing"
+		}
+		p.props.SetMust(mprisPlayer, "PlaybackStatus", status)
+	}
+	if s.Title != p.last.Title || s.Length != p.last.Length {
+		p.props.SetMust(mprisPlayer, "Metadata", p.metadata(s))
+	}
+	p.last = s
+}
+
+func (p *mpris) close() {
+	p.conn.Close()
+}
+
+// mprisRootMethods are the methods of org.mpris.MediaPlayer2, which zippy
+// does not support.
+type mprisRootMethods struct{}
+
+func (mprisRootMethods) Raise() *dbus.Error { return nil }
+func (mprisRootMethods) Quit() *dbus.Error  { return nil }
+
+// mprisPlayerMethods are the methods of org.mpris.MediaPlayer2.Player, with
+// Stop pausing, Next and Previous moving by a sentence, and offsets in
+// microseconds of reading time.
+type mprisPlayerMethods struct{ p *mpris }
+
+func (m mprisPlayerMethods) do(action mediaAction, offset int64) *dbus.Error {
+	m.p.send(mediaMsg{action: action, offset: time.Duration(offset) * time.Microsecond})
+	return nil
+}
+
+func (m mprisPlayerMethods) Play() *dbus.Error      { return m.do(mediaPlay, 0) }
+func (m mprisPlayerMethods) Pause() *dbus.Error     { return m.do(mediaPause, 0) }
+func (m mprisPlayerMethods) Stop() *dbus.Error      { return m.do(mediaPause, 0) }
+func (m mprisPlayerMethods) PlayPause() *dbus.Error { return m.do(mediaPlayPause, 0) }
+func (m mprisPlayerMethods) Next() *dbus.Error      { return m.do(mediaNext, 0) }
+func (m mprisPlayerMethods) Previous() *dbus.Error  { return m.do(mediaPrevious, 0) }
+
+// SeekBy is exported as Seek, a name go vet keeps for io.Seeker.
+func (m mprisPlayerMethods) SeekBy(offset int64) *dbus.Error {
+	return m.do(mediaSeek, offset)
+}
+
+func (m mprisPlayerMethods) SetPosition(track dbus.ObjectPath, position int64) *dbus.Error {
+	if track != mprisTrack {
+		return nil
+	}
+	return m.do(mediaSetPosition, position)
+}
+
+func (m mprisPlayerMethods) OpenUri(uri string) *dbus.Error { return nil }
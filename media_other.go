@@ -0,0 +1,16 @@
+//go:build !linux && !(darwin && cgo)
+
+package main
+
+import (
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startMediaKeys is implemented on Linux, through MPRIS, and on macOS when
+// built with cgo, through the Now Playing controls. Elsewhere the error only
+// costs the media keys; reading goes on without them.
+func startMediaKeys(send func(tea.Msg)) (mediaPlayer, error) {
+	return nil, errors.New("media keys are only supported on Linux and macOS")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMediaActions(t *testing.T) {
+	m := model{
+		pacing:    pacing{wpm: 120, chunk: 1},
+		stream:    newEagerStream(tokenize("One two three. Four five six. Seven eight nine ten."), true),
+		preflight: &preflight{},
+		docKey:    "/books/numbers.txt",
+	}
+	m.handleMedia(mediaMsg{action: mediaPause})
+	if m.running || m.preflight == nil {
+		t.Fatal("expected pause to leave a paused session alone")
+	}
+	m.handleMedia(mediaMsg{action: mediaPlay})
+	if !m.running || m.preflight != nil {
+		t.Fatal("expected play to start reading from the summary")
+	}
+	m.handleMedia(mediaMsg{action: mediaPlay})
+	if !m.running {
+		t.Fatal("expected play to keep playing")
+	}
+	m.handleMedia(mediaMsg{action: mediaPlayPause})
+	if m.running {
+		t.Fatal("expected play/pause to pause")
+	}
+
+	m.handleMedia(mediaMsg{action: mediaNext})
+	if m.stream.Pos() != 3 {
+		t.Fatalf("expected next to go to the next sentence, got word %d", m.stream.Pos())
+	}
+	// At 120 WPM, a word is half a second.
+	m.handleMedia(mediaMsg{action: mediaSeek, offset: 2 * time.Second})
+	if m.stream.Pos() != 7 {
+		t.Fatalf("expected seeking 2s to skip 4 words, got word %d", m.stream.Pos())
+	}
+	m.handleMedia(mediaMsg{action: mediaSetPosition, offset: time.Second})
+	if m.stream.Pos() != 2 {
+		t.Fatalf("expected setting the position to 1s to go to word 2, got word %d", m.stream.Pos())
+	}
+	if s := m.mediaState(); s.Title != "numbers.txt" || s.Position != time.Second || s.Length != 5*time.Second {
+		t.Fatalf("unexpected media state %+v", s)
+	}
+
+	m.prompt = promptSearch
+	m.handleMedia(mediaMsg{action: mediaPlay})
+	if m.running {
+		t.Fatal("expected media keys to wait while a prompt is open")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// readingMetrics is what /metrics reports about the session.
+type readingMetrics struct {
+	Document     string
+	Words        int
+	WPM          int
+	EffectiveWPM int
+	Playing      bool
+	Reading      time.Duration
+	Position     int
+	Total        int
+}
+
+func (m model) readingMetrics() readingMetrics {
+	r := readingMetrics{Document: "stdin", Words: m.wordsRead, WPM: m.wpm, Playing: m.running, Reading: m.playedFor()}
+	if m.docKey != "" {
+		r.Document = filepath.Base(m.docKey)
+	}
+	r.EffectiveWPM, _ = m.effectiveWPM()
+	if m.stream != nil {
+		r.Position = m.stream.Pos() + 1
+		if known, total := m.stream.Total(); known {
+			r.Total = total
+		}
+	}
+	return r
+}
+
+// measure records the latest metrics, counting the pauses between them.
+func (mr *mirror) measure(r readingMetrics) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	if mr.metrics.Playing && !r.Playing {
+		mr.pauses++
+	}
+	mr.metrics = r
+}
+
+// writeMetrics writes the metrics in Prometheus's text format.
+func (mr *mirror) writeMetrics(w io.Writer, now time.Time) {
+	mr.mu.Lock()
+	r, pauses := mr.metrics, mr.pauses
+	mr.mu.Unlock()
+	metric := func(name, kind, help string, value float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, kind, name, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	playing := 0.0
+	if r.Playing {
+		playing = 1
+	}
+	fmt.Fprintf(w, "# HELP zippy_info The document being read.\n# TYPE zippy_info gauge\nzippy_info{document=\"%s\"} 1\n", escapeLabel(r.Document))
+	metric("zippy_words_displayed_total", "counter", "Words read while playing.", float64(r.Words))
+	metric("zippy_pauses_total", "counter", "Times playback was paused.", float64(pauses))
+	metric("zippy_reading_seconds_total", "counter", "Time spent playing.", r.Reading.Seconds())
+	metric("zippy_session_duration_seconds", "gauge", "Time since the session started.", now.Sub(mr.started).Round(time.Millisecond).Seconds())
+	metric("zippy_wpm", "gauge", "The speed set.", float64(r.WPM))
+	metric("zippy_effective_wpm", "gauge", "The speed read at so far, counting only time spent playing.", float64(r.EffectiveWPM))
+	metric("zippy_playing", "gauge", "1 while playing, 0 while paused.", playing)
+	metric("zippy_position_words", "gauge", "The word reached, counting from 1.", float64(r.Position))
+	if r.Total > 0 {
+		metric("zippy_document_words", "gauge", "The words in the document.", float64(r.Total))
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value as the Prometheus text format wants:
+// backslashes, double quotes and newlines only, leaving the rest as UTF-8.
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	start := time.Now()
+	mr := newMirror(start)
+	m := model{pacing: pacing{wpm: 300, chunk: 1}, stream: newEagerStream(tokenize("Hello wide world"), true), mirror: mr, docKey: "/books/moby.txt"}
+	m = press(m, " ", " ")
+
+	rec := httptest.NewRecorder()
+	mr.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if kind := rec.Header().Get("Content-Type"); !strings.HasPrefix(kind, "text/plain") {
+		t.Fatalf("expected text, got %s", kind)
+	}
+	body := rec.Body.String()
+	for _, line := range []string{
+		`zippy_info{document="moby.txt"} 1`,
+		"zippy_pauses_total 1",
+		"zippy_wpm 300",
+		"zippy_playing 0",
+		"zippy_position_words 1",
+		"zippy_document_words 3",
+		"# TYPE zippy_words_displayed_total counter",
+		"# TYPE zippy_session_duration_seconds gauge",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected %q in:\n%s", line, body)
+		}
+	}
+
+	var out strings.Builder
+	mr.writeMetrics(&out, start.Add(90*time.Second))
+	if !strings.Contains(out.String(), "zippy_session_duration_seconds 90\n") {
+		t.Fatalf("expected the session to have lasted 90 seconds:\n%s", out.String())
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	for v, want := range map[string]string{
+		"moby.txt":           "moby.txt",
+		"Les Misérables":     "Les Misérables",
+		`say "hi"`:           `say \"hi\"`,
+		`C:\books`:           `C:\\books`,
+		"two\nlines":         `two\nlines`,
+		"tab\tand\u00a0nbsp": "tab\tand\u00a0nbsp",
+	} {
+		if got := escapeLabel(v); got != want {
+			t.Errorf("escapeLabel(%q) = %q, want %q", v, got, want)
+		}
+	}
+}
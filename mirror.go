@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// mirrorFrame is what the web mirror shows: the words on screen with their
+// pivot letter, and where the reader is.
+type mirrorFrame struct {
+	Word     string `json:"word"`
+	Pivot    int    `json:"pivot"`
+	Position int    `json:"position"`
+	Total    int    `json:"total,omitempty"`
+	WPM      int    `json:"wpm"`
+	Playing  bool   `json:"playing"`
+}
+
+func (m model) mirrorFrame() mirrorFrame {
+	if m.stream == nil {
+		return mirrorFrame{}
+	}
+	f := mirrorFrame{Position: m.stream.Pos() + 1, WPM: m.wpm, Playing: m.running}
+	if known, total := m.stream.Total(); known {
+		f.Total = total
+	}
+	if frame := m.frame(); len(frame) > 0 {
+		f.Word = m.wordCase.apply(frameText(frame))
+		runes := []rune(f.Word)
+		f.Pivot = min(framePivot(runes), len(runes)-1)
+	}
+	return f
+}
+
+// mirror serves a web page that follows the terminal display, for -serve,
+// and metrics about the session for Prometheus. The page listens for frames
+// as server-sent events.
+type mirror struct {
+	mu sync.Mutex
+	// last is the latest frame, sent to clients as soon as they connect.
+	last    []byte
+	clients map[chan []byte]bool
+
+	started time.Time
+	metrics readingMetrics
+	pauses  int
+}
+
+func newMirror(started time.Time) *mirror {
+	return &mirror{clients: map[chan []byte]bool{}, started: started}
+}
+
+// publish sends a frame to every client, unless it is the one they already
+// have. A client that has not taken the previous frame yet only gets the
+// newest.
+func (mr *mirror) publish(f mirrorFrame) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return
+	}
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	if bytes.Equal(data, mr.last) {
+		return
+	}
+	mr.last = data
+	for c := range mr.clients {
+		select {
+		case <-c:
+		default:
+		}
+		c <- data
+	}
+}
+
+func (mr *mirror) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, mirrorPage)
+	case "/events":
+		mr.events(w, r)
+	case "/metrics":
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		mr.writeMetrics(w, time.Now())
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (mr *mirror) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	c := make(chan []byte, 1)
+	mr.mu.Lock()
+	mr.clients[c] = true
+	if mr.last != nil {
+		c <- mr.last
+	}
+	mr.mu.Unlock()
+	defer func() {
+		mr.mu.Lock()
+		delete(mr.clients, c)
+		mr.mu.Unlock()
+	}()
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data := <-c:
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// serveAddr keeps the mirror to this machine when -serve gives only a port,
+// as in ":8080"; other machines can reach it only when the address names a
+// host to listen on, such as 0.0.0.0:8080.
+func serveAddr(addr string) string {
+	if host, port, err := net.SplitHostPort(addr); err == nil && host == "" {
+		return net.JoinHostPort("localhost", port)
+	}
+	return addr
+}
+
+// mirrorURL is where to point a browser for the mirror listening on addr,
+// naming localhost when it listens on every interface.
+func mirrorURL(addr net.Addr) string {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return "http://" + addr.String()
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// mirrorPage shows the word large with the pivot letter a third of the way
+// across, as the terminal does by default, and the position beneath it.
+const mirrorPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>zippy</title>
+<style>
+  html, body { height: 100%; margin: 0; background: #1a1a1a; color: #e8e8e8; }
+  body { display: flex; flex-direction: column; justify-content: center; font-family: monospace; }
+  #word { display: grid; grid-template-columns: 1fr auto 2fr; font-size: 8vw; white-space: pre; }
+  #left { text-align: right; }
+  #pivot { color: #ff5f5f; font-weight: bold; }
+  #status { position: fixed; bottom: 1em; width: 100%; text-align: center; color: #808080; }
+</style>
+</head>
+<body>
+<div id="word"><span id="left"></span><span id="pivot"></span><span id="right"></span></div>
+<div id="status">Waiting for zippy...</div>
+<script>
+  const $ = (id) => document.getElementById(id);
+  const events = new EventSource("/events");
+  events.onmessage = (e) => {
+    const f = JSON.parse(e.data);
+    const chars = Array.from(f.word);
+    $("left").textContent = chars.slice(0, f.pivot).join("");
+    $("pivot").textContent = chars.slice(f.pivot, f.pivot + 1).join("");
+    $("right").textContent = chars.slice(f.pivot + 1).join("");
+    $("status").textContent = f.position + "/" + (f.total || "?") + "  " + f.wpm + " WPM" + (f.playing ? "" : "  Paused");
+  };
+  events.onerror = () => { $("status").textContent = "Disconnected"; };
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMirror(t *testing.T) {
+	mr := newMirror(time.Now())
+	srv := httptest.NewServer(mr)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if kind := resp.Header.Get("Content-Type"); !strings.HasPrefix(kind, "text/html") {
+		t.Fatalf("expected the page at /, got %s", kind)
+	}
+
+	resp, err = http.Get(srv.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	frames := make(chan mirrorFrame)
+	go func() {
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			if data, ok := strings.CutPrefix(sc.Text(), "data: "); ok {
+				var f mirrorFrame
+				json.Unmarshal([]byte(data), &f)
+				frames <- f
+			}
+		}
+	}()
+	next := func() mirrorFrame {
+		select {
+		case f := <-frames:
+			return f
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected a frame")
+			return mirrorFrame{}
+		}
+	}
+
+	m := model{pacing: pacing{wpm: 300, chunk: 1}, stream: newEagerStream(tokenize("Hello wide world"), true), mirror: mr}
+	for connected := false; !connected; time.Sleep(time.Millisecond) {
+		mr.mu.Lock()
+		connected = len(mr.clients) == 1
+		mr.mu.Unlock()
+	}
+	m = press(m, "l")
+	if f := next(); f.Word != "wide" || f.Pivot != 1 || f.Position != 2 || f.Total != 3 || f.WPM != 300 {
+		t.Fatalf("unexpected frame %+v", f)
+	}
+	m = press(m, "l")
+	if f := next(); f.Word != "world" {
+		t.Fatalf("expected the mirror to follow the display, got %+v", f)
+	}
+}
+
+func TestServeAddr(t *testing.T) {
+	for addr, want := range map[string]string{
+		":8080":          "localhost:8080",
+		"0.0.0.0:8080":   "0.0.0.0:8080",
+		"10.0.0.5:8080":  "10.0.0.5:8080",
+		"localhost:9000": "localhost:9000",
+		"[::]:8080":      "[::]:8080",
+	} {
+		if got := serveAddr(addr); got != want {
+			t.Errorf("serveAddr(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// wheelMode is what the mouse wheel does: step through the words or change
+// the speed.
+type wheelMode int
+
+const (
+	wheelSeek wheelMode = iota
+	wheelSpeed
+)
+
+var wheelNames = []string{"seek", "speed"}
+
+func (w wheelMode) String() string { return wheelNames[w] }
+
+func parseWheel(s string) (wheelMode, error) {
+	for i, name := range wheelNames {
+		if s == name {
+			return wheelMode(i), nil
+		}
+	}
+	return wheelSeek, fmt.Errorf("unknown wheel mode %q; use seek or speed", s)
+}
+
+// handleMouse handles the wheel, which steps a word per notch or changes the
+// speed, and clicks: on a key in the status line hint to press it, along the
+// progress bar to seek, and anywhere else to play or pause.
+func (m *model) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.showText {
+		var cmd tea.Cmd
+		m.text.viewport, cmd = m.text.viewport.Update(msg)
+		return cmd
+	}
+	if m.stream == nil || m.askResume || m.preflight != nil || m.onBreak || m.lookup != nil || m.prompt != promptNone || m.showMarks {
+		return nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		return m.wheel(1)
+	case tea.MouseButtonWheelDown:
+		return m.wheel(-1)
+	case tea.MouseButtonLeft:
+		press := msg.Action == tea.MouseActionPress
+		if press && m.height >= 2 && msg.Y == m.height-1 && !m.statusHidden() {
+			if b, ok := m.controlAt(msg.X); ok {
+				return m.pressKey(b.Keys()[0])
+			}
+		}
+		if m.height >= 2 && m.width >= 2 && msg.Y == m.seekRow() {
+			if !m.stream.SupportsSeek() {
+				return nil
+			}
+			pct := float64(min(max(msg.X, 0), m.width-1)) * 100 / float64(m.width-1)
+			switch msg.Action {
+			case tea.MouseActionPress:
+				m.jump(func() { m.seekPercent(pct) })
+			case tea.MouseActionMotion:
+				m.seekPercent(pct)
+			}
+			return nil
+		}
+		if press {
+			return m.togglePlay()
+		}
+	}
+	return nil
+}
+
+// wheel handles a notch of the mouse wheel, up being 1.
+func (m *model) wheel(dir int) tea.Cmd {
+	if m.wheelMode == wheelSpeed {
+		m.adjustWPM(m.speedStep(false) * dir)
+		if m.running {
+			return m.tickCmd(m.frameInterval())
+		}
+		return nil
+	}
+	if !m.stream.SupportsSeek() {
+		return nil
+	}
+	if dir > 0 {
+		m.stream.Prev()
+		return nil
+	}
+	return m.stream.Next()
+}
+
+// controlAt returns the binding whose hint is at column x of the status line:
+// the key clicked on, or the first of the hint's keys for its description.
+func (m model) controlAt(x int) (key.Binding, bool) {
+	if m.prompt != promptNone {
+		return key.Binding{}, false
+	}
+	col := ansi.StringWidth(m.statusText()) - ansi.StringWidth(m.controls())
+	for _, h := range m.controlHints() {
+		bound := h.bound()
+		if len(bound) == 0 {
+			continue
+		}
+		for i, b := range bound {
+			if i > 0 {
+				col += ansi.StringWidth(h.sep)
+			}
+			w := ansi.StringWidth(helpKey(b))
+			if x >= col && x < col+w {
+				return b, true
+			}
+			col += w
+		}
+		w := ansi.StringWidth(": " + h.desc)
+		if x >= col && x < col+w {
+			return bound[0], true
+		}
+		col += w + 2
+	}
+	return key.Binding{}, false
+}
+
+// pressKey acts as if the key named s had been pressed.
+func (m *model) pressKey(s string) tea.Cmd {
+	next, cmd := m.update(keyMsg(s))
+	*m = next.(model)
+	return cmd
+}
+
+// keyMsg is the key press bubbletea sends for the key named s, as keys are
+// named in bindings.
+func keyMsg(s string) tea.KeyMsg {
+	alt := false
+	if rest, ok := strings.CutPrefix(s, "alt+"); ok && rest != "" {
+		alt, s = true, rest
+	}
+	for t := tea.KeyType(-128); t < 128; t++ {
+		if t != tea.KeyRunes && (tea.Key{Type: t}).String() == s {
+			return tea.KeyMsg{Type: t, Alt: alt}
+		}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s), Alt: alt}
+}
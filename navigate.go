@@ -0,0 +1,110 @@
+package main
+
+// Structural navigation works by looking around the current position with
+// Peek, so it is available on any stream that supports seeking.
+
+// sentenceStart moves to the first word of the current sentence.
+func (m *model) sentenceStart() {
+	if tok, ok := m.stream.Current(); ok {
+		m.stream.Seek(m.stream.Pos() - tok.sentenceWord)
+	}
+}
+
+// sentenceBack moves to the start of the current sentence, or to the start
+// of the previous one when already at a sentence start.
+func (m *model) sentenceBack() {
+	tok, ok := m.stream.Current()
+	if !ok {
+		return
+	}
+	pos := m.stream.Pos()
+	if !tok.sentenceStart() {
+		m.stream.Seek(pos - tok.sentenceWord)
+		return
+	}
+	prev, ok := m.stream.Peek(-1)
+	if !ok {
+		return
+	}
+	m.stream.Seek(pos - 1 - prev.sentenceWord)
+}
+
+// sentenceForward moves to the start of the next sentence.
+func (m *model) sentenceForward() {
+	m.seekForward(token.sentenceStart)
+}
+
+// paragraphBack moves to the start of the current paragraph, or to the start
+// of the previous one when already at a paragraph start.
+func (m *model) paragraphBack() {
+	m.seekBackward(isParagraphStart)
+}
+
+// paragraphForward moves to the start of the next paragraph.
+func (m *model) paragraphForward() {
+	m.seekForward(isParagraphStart)
+}
+
+func isParagraphStart(t token) bool {
+	return t.paragraphStart
+}
+
+// chapterBack moves to the start of the current chapter, or to the start of
+// the previous one when already at a heading.
+func (m *model) chapterBack() {
+	m.seekBackward(isHeading)
+}
+
+// chapterForward moves to the next heading.
+func (m *model) chapterForward() {
+	m.seekForward(isHeading)
+}
+
+func isHeading(t token) bool {
+	return t.heading
+}
+
+// seekBackward moves to the closest earlier word matching want, staying put
+// if there is none.
+func (m *model) seekBackward(want func(token) bool) {
+	for i := -1; ; i-- {
+		tok, ok := m.stream.Peek(i)
+		if !ok {
+			return
+		}
+		if want(tok) {
+			m.stream.Seek(m.stream.Pos() + i)
+			return
+		}
+	}
+}
+
+// seekForward moves to the next word matching want, staying put if there is
+// none.
+func (m *model) seekForward(want func(token) bool) {
+	for i := 1; ; i++ {
+		tok, ok := m.stream.Peek(i)
+		if !ok {
+			return
+		}
+		if want(tok) {
+			m.stream.Seek(m.stream.Pos() + i)
+			return
+		}
+	}
+}
+
+// neighborGap is the space between the frame and the words shown beside it.
+const neighborGap = 3
+
+// neighbors returns the words just before and after a frame of the given
+// size at the current position.
+func (m model) neighbors(frameLen int) (prev, next string) {
+	if tok, ok := m.stream.Peek(-1); ok {
+		prev = tok.text
+	}
+	if tok, ok := m.stream.Peek(frameLen); ok {
+		next = tok.text
+	}
+	return prev, next
+}
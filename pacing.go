@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"flag"
+)
+
+// pacing holds the options that decide what each frame shows and how long
+// it stays on screen. It is shared by the reader and the subcommands that
+// estimate reading time without launching the TUI.
+type pacing struct {
+	wpm       int
+	chunk     int
+	clauses   bool
+	skim      bool
+	skimWords int
+}
+
+func (p *pacing) register(fs *flag.FlagSet) {
+	fs.IntVar(&p.wpm, "wpm", 500, "starting words per minute")
+	fs.BoolVar(&p.skim, "skim", false, "start in skim mode, showing only the first words of each sentence")
+	fs.IntVar(&p.skimWords, "skim-words", 3, "words to show per sentence in skim mode")
+	fs.IntVar(&p.chunk, "chunk", 1, "number of words to show per frame (not available with -lazy)")
+	fs.BoolVar(&p.clauses, "clauses", false, "show a clause or sentence fragment per frame instead of single words")
+}
+
+func (p pacing) validate() error {
+	switch {
+	case p.wpm <= 0:
+		return errors.New("WPM must be greater than 0.")
+	case p.skimWords <= 0:
+		return errors.New("Skim words must be greater than 0.")
+	case p.chunk <= 0:
+		return errors.New("Chunk must be greater than 0.")
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import "fmt"
+
+// alignment is how frames are placed horizontally.
+type alignment int
+
+const (
+	// alignORP anchors the pivot letter a third of the way across, at the
+	// optimal recognition point.
+	alignORP alignment = iota
+	// alignCenter anchors the pivot letter in the middle of the screen.
+	alignCenter
+	// alignLeft starts every frame at the left margin, so the pivot moves.
+	alignLeft
+)
+
+var alignNames = []string{"orp", "center", "left"}
+
+func (a alignment) String() string { return alignNames[a] }
+
+func parseAlign(s string) (alignment, error) {
+	for i, name := range alignNames {
+		if s == name {
+			return alignment(i), nil
+		}
+	}
+	return alignORP, fmt.Errorf("unknown alignment %q; use orp, center or left", s)
+}
+
+// leftMargin is where frames start with -align left.
+const leftMargin = 2
+
+// pivotColumn is where the pivot letter goes, given how wide the part of the
+// frame before it is.
+func (a alignment) pivotColumn(width, before int) int {
+	switch a {
+	case alignCenter:
+		return width / 2
+	case alignLeft:
+		return leftMargin + before
+	}
+	return width / 3
+}
+
+// parseVertical checks -vertical, the fraction of the way down the screen
+// the word is placed at.
+func parseVertical(v float64) error {
+	if v < 0 || v > 1 {
+		return fmt.Errorf("-vertical must be between 0 (top) and 1 (bottom)")
+	}
+	return nil
+}
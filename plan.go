@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// plan is the frame schedule the reader would play for a document.
+type plan struct {
+	words  int
+	frames int
+	total  time.Duration
+	// durations counts frames by how long they stay on screen.
+	durations map[time.Duration]int
+}
+
+func buildPlan(words []token, p pacing) plan {
+	result := plan{words: len(words), durations: map[time.Duration]int{}}
+	if len(words) == 0 {
+		return result
+	}
+	m := model{pacing: p, stream: newEagerStream(words, false)}
+	for {
+		d := m.frameInterval()
+		result.frames++
+		result.total += d
+		result.durations[d]++
+		if !m.canAdvance() {
+			return result
+		}
+		m.advance()
+	}
+}
+
+func (p plan) write(w io.Writer, wpm int) {
+	fmt.Fprintf(w, "Words:         %d\n", p.words)
+	fmt.Fprintf(w, "Frames:        %d\n", p.frames)
+	fmt.Fprintf(w, "Reading time:  %s at %d WPM\n", p.total.Round(time.Second), wpm)
+	if p.frames == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Frame durations:")
+	keys := make([]time.Duration, 0, len(p.durations))
+	most := 0
+	for d, n := range p.durations {
+		keys = append(keys, d)
+		most = max(most, n)
+	}
+	slices.Sort(keys)
+	const barWidth = 40
+	for _, d := range keys {
+		n := p.durations[d]
+		bar := strings.Repeat("#", max(n*barWidth/most, 1))
+		fmt.Fprintf(w, "  %8s  %-*s %d\n", d.Round(time.Millisecond), barWidth, bar, n)
+	}
+}
+
+// runPlan implements `zippy plan`, printing the schedule for a document
+// without starting the TUI.
+func runPlan(args []string) int {
+	fs := flag.NewFlagSet("plan", flag.ExitOnError)
+	var (
+		p    pacing
+		file string
+	)
+	fs.StringVar(&file, "file", "", "path to input text")
+	p.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s plan [options]\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Print the reading schedule for the input without starting playback.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := applyEnv(fs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := p.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	text, err := readInput(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Provide input via -file or stdin.")
+		fs.PrintDefaults()
+		return 1
+	}
+	buildPlan(tokenize(text), p).write(os.Stdout, p.wpm)
+	return 0
+}
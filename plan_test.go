@@ -0,0 +1,19 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildPlanChunks(t *testing.T) {
+	p := buildPlan(tokenize("one two three four five"), pacing{wpm: 600, chunk: 2, skimWords: 3})
+	if p.words != 5 || p.frames != 3 {
+		t.Fatalf("expected 5 words in 3 frames, got %d in %d", p.words, p.frames)
+	}
+	if p.total != 500*time.Millisecond {
+		t.Fatalf("expected 500ms total, got %v", p.total)
+	}
+	if p.durations[200*time.Millisecond] != 2 || p.durations[100*time.Millisecond] != 1 {
+		t.Fatalf("unexpected histogram %v", p.durations)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// preflight is what the summary shown before reading starts says about the
+// document; the word count and reading time come from the stream, so that
+// they follow the speed keys.
+type preflight struct {
+	title    string
+	language string
+	// streak is the reading streak, shown if the config file asks for it.
+	streak int
+}
+
+// preflightTitleWords is how far into the document a heading still counts
+// as its title.
+const preflightTitleWords = 100
+
+// newPreflight sums up a buffered document named name.
+func newPreflight(words []token, name string) *preflight {
+	p := &preflight{language: detectLanguage(words)}
+	for _, w := range words[:min(len(words), preflightTitleWords)] {
+		if w.heading && w.chapter != "" {
+			p.title = w.chapter
+			break
+		}
+	}
+	if p.title == "" && name != "" {
+		p.title = filepath.Base(name)
+	}
+	return p
+}
+
+// languageWords are a few of the commonest words of each language
+// detectLanguage knows, ones rarely seen in the others.
+var languageWords = []struct {
+	name  string
+	words []string
+}{
+	{"English", []string{"the", "and", "of", "to", "is", "that", "with", "was", "for", "it"}},
+	{"French", []string{"le", "la", "les", "et", "des", "est", "une", "dans", "pour", "qui"}},
+	{"German", []string{"der", "die", "und", "das", "ist", "nicht", "ein", "mit", "sich", "auf"}},
+	{"Spanish", []string{"el", "los", "las", "y", "del", "por", "una", "con", "para", "que"}},
+	{"Italian", []string{"il", "di", "che", "della", "per", "non", "sono", "gli", "una", "nel"}},
+	{"Portuguese", []string{"os", "do", "da", "em", "não", "uma", "com", "para", "que", "dos"}},
+	{"Dutch", []string{"de", "het", "een", "van", "en", "niet", "ik", "dat", "zijn", "voor"}},
+}
+
+// detectSample is how many words detectLanguage looks at.
+const detectSample = 1000
+
+// detectLanguage guesses the language of the text from its commonest words,
+// or returns "" when none stands out.
+func detectLanguage(words []token) string {
+	counts := make([]int, len(languageWords))
+	for _, w := range words[:min(len(words), detectSample)] {
+		word := strings.ToLower(strings.TrimFunc(w.text, func(r rune) bool { return !unicode.IsLetter(r) }))
+		for i, l := range languageWords {
+			for _, common := range l.words {
+				if word == common {
+					counts[i]++
+				}
+			}
+		}
+	}
+	best, second := -1, 0
+	for i, n := range counts {
+		switch {
+		case best < 0 || n > counts[best]:
+			if best >= 0 {
+				second = counts[best]
+			}
+			best = i
+		case n > second:
+			second = n
+		}
+	}
+	// A handful of hits, well clear of the runner-up, makes a guess.
+	if best < 0 || counts[best] < 5 || counts[best] < second*3/2 {
+		return ""
+	}
+	return languageWords[best].name
+}
+
+// handlePreflightKey starts reading from the summary screen. The speed can
+// be set first, and esc leaves it for the reader, paused. Quit asks first as
+// it does while reading, since -resume or -start-at may have moved on.
+func (m *model) handlePreflightKey(msg tea.KeyMsg) tea.Cmd {
+	k := m.keys()
+	switch {
+	case msg.String() == "ctrl+c":
+		return tea.Quit
+	case key.Matches(msg, k.Quit):
+		m.preflight = nil
+		return m.quit()
+	case key.Matches(msg, k.Faster, k.Slower, k.FasterFine, k.SlowerFine):
+		step := m.speedStep(key.Matches(msg, k.FasterFine, k.SlowerFine))
+		if key.Matches(msg, k.Slower, k.SlowerFine) {
+			step = -step
+		}
+		m.adjustWPM(step)
+	case key.Matches(msg, k.PlayPause):
+		m.preflight = nil
+		return m.togglePlay()
+	case msg.String() == "esc":
+		m.preflight = nil
+	}
+	return nil
+}
+
+func (m model) preflightScreen() string {
+	var b strings.Builder
+	if m.preflight.title != "" {
+		b.WriteString(m.preflight.title + "\n\n")
+	}
+	_, total := m.stream.Total()
+	fmt.Fprintf(&b, "%s words", formatCount(total))
+	if eta, ok := m.eta(); ok {
+		fmt.Fprintf(&b, ", about %s at %d WPM", eta.Round(time.Second), m.wpm)
+	}
+	if pos := m.stream.Pos(); pos > 0 {
+		fmt.Fprintf(&b, "\nStarting at word %s", formatCount(pos+1))
+	}
+	language := m.preflight.language
+	if language == "" {
+		language = "unknown"
+	}
+	b.WriteString("\nLanguage: " + language)
+	if m.preflight.streak > 0 {
+		b.WriteString("\nReading streak: " + dayCount(m.preflight.streak))
+	}
+	k := m.keys()
+	fmt.Fprintf(&b, "\n\nPress %s to begin, %s/%s to change the speed.", helpKey(k.PlayPause), helpKey(k.Faster), helpKey(k.Slower))
+	return m.theme.place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.theme.text().Render(b.String()))
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"The cat sat on the mat and looked at the dog. It was the first time that the dog was quiet, and the cat was glad of it.", "English"},
+		{"Le chat est dans la maison et les enfants sont dans le jardin. La mère est une femme qui travaille pour des amis.", "French"},
+		{"Der Hund und die Katze sind nicht im Haus, das ist ein Problem, denn die Kinder spielen mit der Katze auf dem Hof.", "German"},
+		{"zippy flashes words", ""},
+	}
+	for _, tt := range tests {
+		if got := detectLanguage(tokenize(tt.text)); got != tt.want {
+			t.Errorf("detectLanguage(%.20q...) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	words := tokenize("# The Lighthouse\n\n" + strings.Repeat("the keeper lit the lamp and the ships went by ", 30))
+	m := model{
+		stream:    newEagerStream(words, false),
+		width:     80,
+		height:    12,
+		pacing:    pacing{wpm: 300},
+		preflight: newPreflight(words, "/tmp/lighthouse.md"),
+	}
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"The Lighthouse", "302 words", "at 300 WPM", "Language: English", "Press space to begin"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in the preflight screen:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "streak") {
+		t.Fatal("expected no streak unless the config file asks for it")
+	}
+	m.preflight.streak = 3
+	if !strings.Contains(ansi.Strip(m.View()), "Reading streak: 3 days") {
+		t.Fatal("expected the reading streak")
+	}
+	m = press(m, "+")
+	if m.preflight == nil || !strings.Contains(ansi.Strip(m.View()), "at 325 WPM") {
+		t.Fatal("expected the speed keys to change the estimate")
+	}
+	m = press(m, " ")
+	if m.preflight != nil || !m.running {
+		t.Fatal("expected space to dismiss the preflight screen and start playing")
+	}
+
+	m = model{stream: newEagerStream(tokenize("plain words"), false), preflight: newPreflight(tokenize("plain words"), "/tmp/notes.txt")}
+	if m.preflight.title != "notes.txt" {
+		t.Fatalf("expected the file name as the title, got %q", m.preflight.title)
+	}
+}
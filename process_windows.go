@@ -0,0 +1,26 @@
+package main
+
+import (
+	"os/exec"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive is the exit code of a process that has not exited.
+const stillActive = 259
+
+// processAlive reports whether a process with the given ID is running.
+func processAlive(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(h)
+	var code uint32
+	return windows.GetExitCodeProcess(h, &code) == nil && code == stillActive
+}
+
+// shellCommand runs a command line the way the user's shell would.
+func shellCommand(command string) *exec.Cmd {
+	return exec.Command("cmd", "/C", command)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// profile bundles reading options under a name in the config file, such as
+// "novel" or "training", to switch between with -profile or the profile key.
+// Options a profile leaves out are those from the command line and the rest
+// of the config file.
+type profile struct {
+	WPM       int    `json:"wpm,omitempty"`
+	Chunk     int    `json:"chunk,omitempty"`
+	Clauses   *bool  `json:"clauses,omitempty"`
+	Skim      *bool  `json:"skim,omitempty"`
+	SkimWords int    `json:"skim_words,omitempty"`
+	Theme     string `json:"theme,omitempty"`
+}
+
+type profileChoice struct {
+	name    string
+	profile profile
+}
+
+// baseOptions are the pacing and theme before any profile, which switching
+// profiles starts again from so that one profile's options don't carry over
+// into the next.
+type baseOptions struct {
+	pacing   pacing
+	themeIdx int
+}
+
+// profileChoices lists the config file's profiles by name.
+func profileChoices(cfg config) []profileChoice {
+	var choices []profileChoice
+	for _, name := range slices.Sorted(maps.Keys(cfg.Profiles)) {
+		choices = append(choices, profileChoice{name: name, profile: cfg.Profiles[name]})
+	}
+	return choices
+}
+
+// checkProfiles validates the profiles in the config file.
+func checkProfiles(cfg config) error {
+	for _, c := range profileChoices(cfg) {
+		pr := c.profile
+		switch {
+		case pr.WPM < 0, pr.Chunk < 0, pr.SkimWords < 0:
+			return fmt.Errorf("profile %q: speeds and word counts must be positive", c.name)
+		case pr.Theme != "":
+			if _, err := resolveTheme(pr.Theme, cfg); err != nil {
+				return fmt.Errorf("profile %q: %w", c.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// findProfile looks a profile up by name for -profile.
+func findProfile(name string, cfg config) (profile, error) {
+	if pr, ok := cfg.Profiles[name]; ok {
+		return pr, nil
+	}
+	if len(cfg.Profiles) == 0 {
+		return profile{}, fmt.Errorf("Unknown profile %q; the config file defines none.", name)
+	}
+	var names []string
+	for _, c := range profileChoices(cfg) {
+		names = append(names, c.name)
+	}
+	return profile{}, fmt.Errorf("Unknown profile %q; use one of %s.", name, strings.Join(names, ", "))
+}
+
+// applyPacing sets the pacing options the profile has, except those in
+// keep, which were given on the command line.
+func (pr profile) applyPacing(p *pacing, keep map[string]bool) {
+	if pr.WPM > 0 && !keep["wpm"] {
+		p.wpm = pr.WPM
+	}
+	if pr.Chunk > 0 && !keep["chunk"] {
+		p.chunk = pr.Chunk
+	}
+	if pr.Clauses != nil && !keep["clauses"] {
+		p.clauses = *pr.Clauses
+	}
+	if pr.Skim != nil && !keep["skim"] {
+		p.skim = *pr.Skim
+	}
+	if pr.SkimWords > 0 && !keep["skim-words"] {
+		p.skimWords = pr.SkimWords
+	}
+}
+
+// cycleProfile switches to the next profile, changing the speed, frames
+// and theme while reading.
+func (m *model) cycleProfile() {
+	if len(m.profiles) == 0 {
+		m.notice = "No profiles in the config file"
+		return
+	}
+	c := m.profiles[(m.profileIndex(m.profileName)+1)%len(m.profiles)]
+	m.useProfile(c, nil)
+	m.notice = fmt.Sprintf("Profile: %s, %d WPM", c.name, m.wpm)
+}
+
+// profileIndex finds a profile by name, or returns -1.
+func (m model) profileIndex(name string) int {
+	return slices.IndexFunc(m.profiles, func(c profileChoice) bool { return c.name == name })
+}
+
+// useProfile switches to a profile, except for the options in keep.
+func (m *model) useProfile(c profileChoice, keep map[string]bool) {
+	m.profileName = c.name
+	if m.base != nil {
+		m.pacing = m.base.pacing
+		m.themeIdx, m.theme = m.base.themeIdx, m.themes[m.base.themeIdx].theme
+	}
+	c.profile.applyPacing(&m.pacing, keep)
+	m.adjustWPM(0)
+	if c.profile.Theme != "" && !keep["theme"] {
+		for i, t := range m.themes {
+			if t.name == c.profile.Theme {
+				m.themeIdx, m.theme = i, t.theme
+			}
+		}
+	}
+}
+
+// restoreSettings brings back the profile, speed and chunk size the document
+// was last read with. Options given on the command line, or a -profile,
+// take precedence.
+func (m *model) restoreSettings(d docState, set map[string]bool) {
+	if set["profile"] {
+		return
+	}
+	restored := false
+	if i := m.profileIndex(d.Profile); d.Profile != "" && i >= 0 {
+		m.useProfile(m.profiles[i], set)
+		restored = true
+	}
+	if d.WPM > 0 && !set["wpm"] {
+		m.wpm = d.WPM
+		m.adjustWPM(0)
+		restored = true
+	}
+	if d.Chunk > 0 && !set["chunk"] {
+		m.chunk = d.Chunk
+		restored = true
+	}
+	if restored && m.notice == "" {
+		m.notice = fmt.Sprintf("%d WPM as last time", m.wpm)
+		if m.profileName != "" {
+			m.notice = fmt.Sprintf("Profile %s at %d WPM as last time", m.profileName, m.wpm)
+		}
+	}
+}
+
+// saveSettings remembers the profile, speed and chunk size in use for the
+// next time the document is opened.
+func saveSettings(key string, m model) error {
+	if key == "" {
+		return nil
+	}
+	return updateDoc(key, func(d *docState) {
+		d.WPM, d.Chunk, d.Profile = m.wpm, m.chunk, m.profileName
+	})
+}
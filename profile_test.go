@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	yes := true
+	cfg := config{Profiles: map[string]profile{
+		"novel":    {WPM: 450, Theme: "night"},
+		"training": {WPM: 700, Chunk: 2, Skim: &yes},
+	}}
+	if err := checkProfiles(cfg); err != nil {
+		t.Fatalf("checkProfiles: %v", err)
+	}
+
+	p := pacing{wpm: 300, chunk: 1, skimWords: 3}
+	cfg.Profiles["training"].applyPacing(&p, map[string]bool{"wpm": true})
+	if p.wpm != 300 || p.chunk != 2 || !p.skim || p.skimWords != 3 {
+		t.Fatalf("expected the profile to leave -wpm alone, got %+v", p)
+	}
+	if _, err := findProfile("paper", cfg); err == nil || !strings.Contains(err.Error(), "novel, training") {
+		t.Fatalf("expected an unknown profile to list the others, got %v", err)
+	}
+
+	themes := []themeChoice{{"default", builtinThemes["default"]}, {"night", builtinThemes["night"]}}
+	base := &baseOptions{pacing: pacing{wpm: 300, chunk: 1, skimWords: 3}}
+	m := model{stream: newEagerStream(tokenize("a b c d"), false), width: 80, height: 3, pacing: base.pacing, theme: themes[0].theme, themes: themes, profiles: profileChoices(cfg), base: base}
+	m = press(m, "P")
+	if m.wpm != 450 || m.theme != builtinThemes["night"] || !strings.Contains(m.View(), "Profile: novel") {
+		t.Fatalf("expected the novel profile, got %d WPM", m.wpm)
+	}
+	m = press(m, "P")
+	if m.wpm != 700 || m.chunk != 2 || !m.skim || m.theme != builtinThemes["default"] {
+		t.Fatalf("expected the training profile without novel's theme, got %+v", m.pacing)
+	}
+	if m = press(m, "P"); m.profileName != "novel" || m.chunk != 1 || m.skim {
+		t.Fatalf("expected to cycle back to novel without training's options, got %q %+v", m.profileName, m.pacing)
+	}
+
+	cfg.Profiles["paper"] = profile{Theme: "sepia-ish"}
+	if err := checkProfiles(cfg); err == nil {
+		t.Fatal("expected a profile with an unknown theme to be rejected")
+	}
+}
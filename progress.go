@@ -0,0 +1,70 @@
+package main
+
+import "strings"
+
+const (
+	progressFilled = "━"
+	progressEmpty  = "─"
+)
+
+// fractionReporter is implemented by streams that can tell how far through
+// their input they are before knowing how many words it holds.
+type fractionReporter interface {
+	Fraction() (float64, bool)
+}
+
+// Fraction reports the position by byte offset, once the size of the input
+// is final.
+func (s *lazyStream) Fraction() (float64, bool) {
+	if s.src == nil {
+		return 0, false
+	}
+	size, final := s.src.size()
+	tok, ok := s.Current()
+	if !final || size == 0 || !ok {
+		return 0, false
+	}
+	return float64(tok.offset) / float64(size), true
+}
+
+// progress is how far through the document reading is, from 0 to 1. It is
+// unknown for piped lazy input until the stream ends.
+func (m model) progress() (float64, bool) {
+	if known, total := m.stream.Total(); known {
+		if total <= 1 {
+			return 1, total == 1
+		}
+		return float64(m.stream.Pos()) / float64(total-1), true
+	}
+	if fr, ok := m.stream.(fractionReporter); ok {
+		return fr.Fraction()
+	}
+	return 0, false
+}
+
+// showProgress reports whether the progress bar gets a row above the status
+// line: there must be room for it and the progress must be known.
+func (m model) showProgress() bool {
+	if m.height < 3 {
+		return false
+	}
+	_, ok := m.progress()
+	return ok
+}
+
+// seekRow is the row that can be clicked or dragged along to seek: the
+// progress bar when it is shown, otherwise the status line.
+func (m model) seekRow() int {
+	if m.showProgress() {
+		return m.height - 2
+	}
+	return m.height - 1
+}
+
+func (m model) progressBar() string {
+	f, _ := m.progress()
+	filled := int(f*float64(m.width) + 0.5)
+	filled = min(max(filled, 0), m.width)
+	return m.theme.status().Render(strings.Repeat(progressFilled, filled)) +
+		m.theme.dim().Render(strings.Repeat(progressEmpty, m.width-filled))
+}
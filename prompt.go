@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// promptKind identifies what the status-line prompt is collecting input for.
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptPercent
+	promptWord
+	promptSearch
+	promptSearchBack
+)
+
+func (k promptKind) label() string {
+	switch k {
+	case promptPercent:
+		return "Jump to percentage: "
+	case promptWord:
+		return "Go to word: "
+	case promptSearch:
+		return "/"
+	case promptSearchBack:
+		return "?"
+	default:
+		return ""
+	}
+}
+
+func (k promptKind) isSearch() bool {
+	return k == promptSearch || k == promptSearchBack
+}
+
+func (m *model) openPrompt(kind promptKind) tea.Cmd {
+	m.prompt = kind
+	m.input = textinput.New()
+	m.input.Prompt = kind.label()
+	m.hits = searchHits{}
+	return m.input.Focus()
+}
+
+// handlePromptKey edits the prompt input, submitting on enter and
+// cancelling on escape. The percentage prompt also submits on %, so that
+// the number is typed and then % pressed.
+func (m *model) handlePromptKey(msg tea.KeyMsg) tea.Cmd {
+	submit := msg.Type == tea.KeyEnter || (m.prompt == promptPercent && msg.String() == "%")
+	switch {
+	case msg.Type == tea.KeyEsc || msg.Type == tea.KeyCtrlC:
+		m.prompt = promptNone
+		return nil
+	case submit:
+		kind, input := m.prompt, strings.TrimSpace(m.input.Value())
+		m.prompt = promptNone
+		return m.submitPrompt(kind, input)
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.prompt.isSearch() && m.input.Value() != m.hits.query {
+		m.hits = m.findHits(m.input.Value())
+	}
+	return cmd
+}
+
+func (m *model) submitPrompt(kind promptKind, input string) tea.Cmd {
+	switch kind {
+	case promptPercent:
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(input, "%"), 64)
+		if err != nil || pct < 0 || pct > 100 {
+			return nil
+		}
+		m.jump(func() { m.seekPercent(pct) })
+	case promptWord:
+		n, err := strconv.Atoi(strings.ReplaceAll(input, ",", ""))
+		if err != nil || n <= 0 {
+			return nil
+		}
+		m.jump(func() { m.stream.Seek(n - 1) })
+	case promptSearch, promptSearchBack:
+		m.jump(func() { m.search(input, kind == promptSearchBack) })
+	}
+	return nil
+}
+
+// fractionSeeker is implemented by streams that can seek to a fraction of
+// their input before knowing how many words it holds.
+type fractionSeeker interface {
+	SeekFraction(f float64)
+}
+
+// seekPercent moves to the word at the given fraction of the document.
+func (m *model) seekPercent(pct float64) {
+	known, total := m.stream.Total()
+	if known {
+		if total > 0 {
+			m.stream.Seek(int(pct / 100 * float64(total-1)))
+		}
+		return
+	}
+	if fs, ok := m.stream.(fractionSeeker); ok {
+		fs.SeekFraction(pct / 100)
+	}
+}
+
+func (m model) promptLine() string {
+	return m.input.View()
+}
+
+// searchContextWords is how many words around a match the search preview
+// shows on either side.
+const searchContextWords = 5
+
+// searchHits is what the incremental search overlay shows for a query: how
+// many matches it has and the words around the one that enter would jump to.
+// It is worked out as the query is typed rather than on every render, as it
+// takes a pass over the whole document.
+type searchHits struct {
+	query string
+	count int
+	found bool
+	// at is the word number of the match, counting from 1.
+	at                   int
+	before, match, after []string
+}
+
+func (m model) findHits(query string) searchHits {
+	h := searchHits{query: query}
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return h
+	}
+	dir := 1
+	if m.prompt == promptSearchBack {
+		dir = -1
+	}
+	h.count = m.countMatches(terms)
+	offset, ok := m.searchFrom(query, dir, dir)
+	if !ok {
+		return h
+	}
+	h.found = true
+	h.at = m.stream.Pos() + offset + 1
+	for i := offset - searchContextWords; i < offset+len(terms)+searchContextWords; i++ {
+		tok, ok := m.stream.Peek(i)
+		if !ok {
+			continue
+		}
+		switch {
+		case i < offset:
+			h.before = append(h.before, tok.text)
+		case i < offset+len(terms):
+			h.match = append(h.match, tok.text)
+		default:
+			h.after = append(h.after, tok.text)
+		}
+	}
+	return h
+}
+
+// searchPreview renders the incremental search overlay from m.hits.
+func (m model) searchPreview() string {
+	h := m.hits
+	if len(searchTerms(h.query)) == 0 {
+		return m.overlay("Type to search")
+	}
+	if !h.found {
+		where := "ahead"
+		if m.prompt == promptSearchBack {
+			where = "behind"
+		}
+		return m.overlay(fmt.Sprintf("%d matches  (none %s)", h.count, where))
+	}
+	hit := m.theme.pivot().Render(strings.Join(h.match, " "))
+	context := strings.TrimSpace(strings.Join(h.before, " ") + " " + hit + " " + strings.Join(h.after, " "))
+	header := fmt.Sprintf("%d matches  next at word %d", h.count, h.at)
+	return m.overlay(header + "\n\n" + context)
+}
+
+// countMatches counts matches of terms across the whole document.
+func (m model) countMatches(terms []string) int {
+	count := 0
+	for i := -m.stream.Pos(); ; i++ {
+		if _, ok := m.stream.Peek(i); !ok {
+			return count
+		}
+		if m.matchesAt(i, terms) {
+			count++
+		}
+	}
+}
+
+// overlayWidth is the outer width of overlays drawn over the word area.
+func (m model) overlayWidth() int {
+	return max(min(m.width-4, 70), 20)
+}
+
+func (m model) overlay(content string) string {
+	return m.theme.text().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(m.theme.Status)).
+		Padding(0, 1).
+		Width(m.overlayWidth()).
+		Render(content)
+}
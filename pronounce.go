@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+//go:embed pronunciations.txt
+var builtinLexicon string
+
+// lexicon maps lower-case words to their pronunciation in ARPAbet phones,
+// as the CMU Pronouncing Dictionary gives them.
+type lexicon map[string][]string
+
+// newLexicon loads the built-in lexicon and, if path is set, a lexicon in
+// the same format whose entries are added to it, such as the full CMU
+// Pronouncing Dictionary.
+func newLexicon(path string) (lexicon, error) {
+	l := lexicon{}
+	if err := l.read(strings.NewReader(builtinLexicon)); err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return l, nil
+	}
+	f, err := os.Open(expandHome(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := l.read(f); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// read adds the entries of a CMU dictionary file: lines of a word and its
+// phones, ";;;" comment lines, and alternative pronunciations marked
+// "word(2)", which are left out.
+func (l lexicon) read(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], ";;;") || strings.HasSuffix(fields[0], ")") {
+			continue
+		}
+		l[strings.ToLower(fields[0])] = fields[1:]
+	}
+	return sc.Err()
+}
+
+// lookUp returns the phones of a word as it appears in the text, ignoring
+// case, the punctuation around it and a possessive 's.
+func (l lexicon) lookUp(word string) ([]string, bool) {
+	word = strings.ToLower(bareWord(word))
+	if phones, ok := l[word]; ok {
+		return phones, true
+	}
+	if base, ok := strings.CutSuffix(strings.ReplaceAll(word, "’", "'"), "'s"); ok {
+		if phones, ok := l[base]; ok {
+			return append(phones[:len(phones):len(phones)], "Z"), true
+		}
+	}
+	return nil, false
+}
+
+var arpabetIPA = map[string]string{
+	"AA": "ɑ", "AE": "æ", "AH": "ʌ", "AO": "ɔ", "AW": "aʊ", "AY": "aɪ",
+	"EH": "ɛ", "ER": "ɝ", "EY": "eɪ", "IH": "ɪ", "IY": "i", "OW": "oʊ",
+	"OY": "ɔɪ", "UH": "ʊ", "UW": "u",
+	"B": "b", "CH": "tʃ", "D": "d", "DH": "ð", "F": "f", "G": "ɡ", "HH": "h",
+	"JH": "dʒ", "K": "k", "L": "l", "M": "m", "N": "n", "NG": "ŋ", "P": "p",
+	"R": "ɹ", "S": "s", "SH": "ʃ", "T": "t", "TH": "θ", "V": "v", "W": "w",
+	"Y": "j", "Z": "z", "ZH": "ʒ",
+}
+
+// onsets are the consonant clusters English syllables can start with, which
+// a stress mark goes in front of.
+var onsets = wordSet(`
+	P_R P_L B_R B_L T_R D_R K_R K_L G_R G_L F_R F_L TH_R SH_R
+	S_P S_T S_K S_M S_N S_L S_W T_W K_W D_W G_W
+	P_Y B_Y K_Y F_Y M_Y HH_Y V_Y
+	S_T_R S_P_R S_K_R S_P_L S_K_W
+`)
+
+// ipa transcribes ARPAbet phones in IPA, marking stressed syllables and
+// writing unstressed AH and ER as schwas.
+func ipa(phones []string) string {
+	var b strings.Builder
+	// consonants holds the IPA of the consonants since the last vowel, so
+	// that a stress mark can go before the ones that start its syllable.
+	var consonants, arpa []string
+	vowels := 0
+	for _, p := range phones {
+		base := strings.TrimRight(p, "012")
+		sym, ok := arpabetIPA[base]
+		if !ok {
+			continue
+		}
+		if base == p {
+			consonants, arpa = append(consonants, sym), append(arpa, base)
+			continue
+		}
+		stress := p[len(base):]
+		switch {
+		case stress == "0" && base == "AH":
+			sym = "ə"
+		case stress == "0" && base == "ER":
+			sym = "ɚ"
+		}
+		mark := ""
+		switch stress {
+		case "1":
+			mark = "ˈ"
+		case "2":
+			mark = "ˌ"
+		}
+		onset := len(consonants)
+		if vowels > 0 {
+			onset = min(onset, 1)
+			for n := min(len(arpa), 3); n > 1; n-- {
+				if onsets[strings.Join(arpa[len(arpa)-n:], "_")] {
+					onset = n
+					break
+				}
+			}
+		}
+		for i, c := range consonants {
+			if i == len(consonants)-onset {
+				b.WriteString(mark)
+				mark = ""
+			}
+			b.WriteString(c)
+		}
+		b.WriteString(mark)
+		b.WriteString(sym)
+		consonants, arpa = nil, nil
+		vowels++
+	}
+	for _, c := range consonants {
+		b.WriteString(c)
+	}
+	return "/" + b.String() + "/"
+}
+
+// pronunciation transcribes the words of a frame that the lexicon knows.
+func (m model) pronunciation(frame []token) string {
+	var words []string
+	for _, tok := range frame {
+		if phones, ok := m.lexicon.lookUp(tok.text); ok {
+			words = append(words, ipa(phones))
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// noPronunciation is shown under a word the lexicon does not know, so the
+// small built-in lexicon is not taken for a broken -ipa.
+const noPronunciation = "no pronunciation known"
+
+// pronunciationLine is the line under the word for -ipa: the frame's
+// pronunciation, or noPronunciation, centered under column col of a block
+// width columns wide, or a blank line when it does not fit so the word stays
+// put.
+func (m model) pronunciationLine(frame []token, col, width int) string {
+	text := m.pronunciation(frame)
+	if text == "" {
+		text = noPronunciation
+	}
+	w := lipgloss.Width(text)
+	if w > width {
+		return strings.Repeat(" ", width)
+	}
+	start := min(max(col-w/2, 0), width-w)
+	return m.theme.text().Render(strings.Repeat(" ", start)) + m.theme.dim().Render(text) +
+		m.theme.text().Render(strings.Repeat(" ", width-start-w))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIPA(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cmudict.dict")
+	extra := ";;; extra words\nzippy  Z IH1 P IY0\nquinoa  K W IH0 N OW1 AH0\nquinoa(2)  K IY1 N OW0 AH0 # rarer\n"
+	if err := os.WriteFile(path, []byte(extra), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := newLexicon(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for word, want := range map[string]string{
+		"Epitome":    "/ɪˈpɪtəmi/",
+		"chaos,":     "/ˈkeɪɑs/",
+		"Xavier's":   "/ˈzeɪviɚz/",
+		"asterisk":   "/ˈæstɚˌɪsk/",
+		"Dostoevsky": "/ˌdɑstəˈjɛfski/",
+		"zippy":      "/ˈzɪpi/",
+		"quinoa":     "/kwɪˈnoʊə/",
+	} {
+		phones, ok := l.lookUp(word)
+		if got := ipa(phones); !ok || got != want {
+			t.Errorf("expected %s for %q, got %s", want, word, got)
+		}
+	}
+	if _, ok := l.lookUp("table"); ok {
+		t.Error("expected an unknown word to have no pronunciation")
+	}
+}
+
+func TestPronunciationLine(t *testing.T) {
+	l, _ := newLexicon("")
+	m := model{stream: newEagerStream(tokenize("Nietzsche wrote"), true), lexicon: l, width: 40, height: 5}
+	if strings.Contains(m.View(), "/ˈnitʃə/") {
+		t.Fatal("expected no pronunciation without -ipa")
+	}
+	m = press(m, "i")
+	if !strings.Contains(m.View(), "/ˈnitʃə/") {
+		t.Fatalf("expected the pronunciation beneath the word, got %q", m.View())
+	}
+	m.stream.Seek(1)
+	if !strings.Contains(m.View(), noPronunciation) {
+		t.Fatalf("expected an unknown word to say it has no pronunciation, got %q", m.View())
+	}
+	if lines := strings.Count(m.View(), "\n"); lines != 4 {
+		t.Fatalf("expected the layout to stay for an unknown word, got %d lines", lines+1)
+	}
+	m.width = 10
+	if strings.Contains(m.View(), noPronunciation) {
+		t.Fatal("expected no notice where it does not fit")
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// quit quits, first asking for confirmation when the document is only partly
+// read, unless -yes was given. Playback pauses while asking.
+func (m *model) quit() tea.Cmd {
+	if m.noConfirm || m.stream == nil || m.stream.Pos() == 0 || !m.canAdvance() {
+		return tea.Quit
+	}
+	m.confirmQuit, m.quitWasRunning = true, m.running
+	m.setRunning(false)
+	return nil
+}
+
+// answerQuit quits on y, enter or the quit key again; anything else goes back
+// to reading.
+func (m *model) answerQuit(msg tea.KeyMsg) tea.Cmd {
+	m.confirmQuit = false
+	switch {
+	case msg.String() == "ctrl+c", msg.String() == "y", msg.String() == "enter", key.Matches(msg, m.keys().Quit):
+		return tea.Quit
+	}
+	if m.quitWasRunning {
+		return m.togglePlay()
+	}
+	return nil
+}
+
+func (m model) quitQuestion() string {
+	q := fmt.Sprintf("Quit at word %s", formatCount(m.stream.Pos()+1))
+	if f, ok := m.progress(); ok {
+		q += fmt.Sprintf(" (%d%%)", int(f*100))
+	}
+	q += "?"
+	if m.docKey != "" {
+		q += " Position saved."
+	}
+	return q + "  y/n"
+}
@@ -0,0 +1,679 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// readOptions are the options of `zippy read`, which `zippy list` shares.
+type readOptions struct {
+	p           pacing
+	file        string
+	lazy        bool
+	duration    time.Duration
+	maxWords    int
+	breakEvery  time.Duration
+	breakWords  int
+	yes         bool
+	watch       watchMode
+	follow      bool
+	onLimit     string
+	loop        bool
+	spool       bool
+	resume      bool
+	noResume    bool
+	noPreflight bool
+	timingLog   string
+	serve       string
+	mediaKeys   bool
+	journal     string
+	vault       string
+	dictionary  string
+	translateTo string
+	startAt     string
+	skipStep    int
+	wpmStep     int
+	fineStep    int
+	minWPM      int
+	maxWPM      int
+	themeName   string
+	profile     string
+	pivotColor  string
+	pivotStyle  string
+	noColor     bool
+	reticle     string
+	neighbors   bool
+	sparkline   bool
+	context     bool
+	ipa         bool
+	zen         bool
+	banner      bool
+	boxWidth    int
+	wordCase    string
+	wheel       string
+	align       string
+	vertical    float64
+}
+
+func (o *readOptions) register(fs *flag.FlagSet) {
+	o.p.register(fs)
+	fs.StringVar(&o.file, "file", "", "path to input text")
+	fs.BoolVar(&o.lazy, "lazy", false, "stream tokens lazily without buffering the whole input")
+	fs.BoolVar(&o.spool, "spool", false, "with -lazy, copy piped input to a temp file so it can be restarted and seeked")
+	fs.BoolVar(&o.resume, "resume", false, "resume at the saved position without asking")
+	fs.BoolVar(&o.noResume, "no-resume", false, "start from the beginning without asking, ignoring any saved position")
+	fs.BoolVar(&o.noPreflight, "no-preflight", false, "start on the first word instead of a summary of the document")
+	fs.StringVar(&o.startAt, "start-at", "", "start at a word number (1200), a percentage (35%) or the first match of some text (\"Chapter 7\"); overrides any saved position")
+	fs.DurationVar(&o.duration, "duration", 0, "stop after this much reading time, e.g. 10m (0 means no limit)")
+	fs.StringVar(&o.themeName, "theme", "", "color theme: default, light, amber, solarized or one defined in the config file")
+	fs.StringVar(&o.profile, "profile", "", "use a profile from the config file, a set of options such as the speed and theme; options given here take precedence")
+	fs.StringVar(&o.pivotColor, "pivot-color", "", "pivot letter color, overriding the theme's, e.g. #00AAFF")
+	fs.StringVar(&o.pivotStyle, "pivot-style", "", "pivot emphasis: bold (default), underline, reverse or a mix like bold,underline; plain for color only; none to disable highlighting")
+	fs.BoolVar(&o.noColor, "no-color", false, "use no colors, showing the pivot in reverse video unless -pivot-style says otherwise (also set by NO_COLOR)")
+	fs.StringVar(&o.reticle, "reticle", "off", "mark the pivot column: off, ticks above and below it, or rules across the screen (| cycles)")
+	fs.BoolVar(&o.neighbors, "neighbors", false, "show the previous and next words dimly beside the current one (g toggles)")
+	fs.BoolVar(&o.sparkline, "sparkline", false, "show the speed over the last few minutes of reading in the status line (W toggles)")
+	fs.BoolVar(&o.context, "context", false, "show the current sentence in a pane below the word (p toggles)")
+	fs.BoolVar(&o.ipa, "ipa", false, "show the pronunciation of the word beneath it in IPA (i toggles); the built-in lexicon only has a few hundred misleading spellings and names, so name the CMU Pronouncing Dictionary as \"pronunciations\" in the config file for the rest")
+	fs.BoolVar(&o.zen, "zen", false, "hide the status line and progress bar, showing them briefly on any key (z toggles)")
+	fs.BoolVar(&o.banner, "banner", false, "draw words in large block letters, e.g. for reading from across the room")
+	fs.IntVar(&o.boxWidth, "box", 0, "draw a rounded box this many columns wide around the word, with the reticle in its border")
+	fs.StringVar(&o.wheel, "wheel", "seek", "what the mouse wheel does: seek (step through the words) or speed")
+	fs.StringVar(&o.wordCase, "case", "as-is", "how to case words on screen: as-is, lower, upper or small-caps")
+	fs.StringVar(&o.align, "align", "orp", "where the word goes across the screen: orp (pivot a third of the way in), center (pivot in the middle) or left")
+	fs.Float64Var(&o.vertical, "vertical", 0.5, "how far down the screen the word goes, from 0 (top) to 1 (bottom)")
+	fs.IntVar(&o.skipStep, "skip-step", 10, "how many words pgup/pgdown jump back/forward by")
+	fs.IntVar(&o.wpmStep, "wpm-step", defaultWPMStep, "how much the speed keys change the WPM by")
+	fs.IntVar(&o.minWPM, "min-wpm", defaultMinWPM, "the slowest the speed keys go")
+	fs.IntVar(&o.maxWPM, "max-wpm", defaultMaxWPM, "the fastest the speed keys go")
+	fs.IntVar(&o.fineStep, "wpm-fine-step", defaultFineStep, "how much shift+up/shift+down change the WPM by")
+	fs.BoolVar(&o.loop, "loop", false, "restart from the beginning when the end is reached")
+	fs.IntVar(&o.maxWords, "max-words", 0, "stop after advancing this many words (0 means no limit)")
+	fs.BoolVar(&o.follow, "follow", false, "keep reading as text is appended to the file, like tail -f; implies -lazy")
+	fs.StringVar(&o.journal, "journal", "", "append a line about the session to this journal file when done, as a list item if it is markdown")
+	fs.StringVar(&o.dictionary, "dictionary", "", "where the define key looks words up: a WordNet database directory, or a URL with {word} in it such as https://api.dictionaryapi.dev/api/v2/entries/en/{word}; an installed WordNet is found by itself")
+	fs.StringVar(&o.translateTo, "translate-to", "", "language the translate keys translate to, as a code such as en; the rest is set up under \"translate\" in the config file")
+	fs.StringVar(&o.vault, "vault", "", "keep a note per document in this notes folder, e.g. an Obsidian vault, with the progress, stats and bookmarks")
+	fs.StringVar(&o.timingLog, "timing-log", "", "write every word shown while playing to this CSV file, with when it appeared and how long it stayed")
+	fs.BoolVar(&o.mediaKeys, "media-keys", false, "let media keys and playerctl play, pause and seek, through MPRIS on Linux or Now Playing on macOS (also \"media_keys\" in the config file)")
+	fs.StringVar(&o.serve, "serve", "", "serve a web page mirroring the word on screen at this address, e.g. :8080, for an audience or a second monitor, and Prometheus metrics at /metrics; a bare port listens on localhost only, so give a host such as 0.0.0.0:8080 to reach it from other machines")
+	fs.Var(&o.watch, "watch", "notice when the file changes on disk: -watch shows that it did, -watch=reload reloads it")
+	fs.BoolVar(&o.yes, "yes", false, "quit without asking first when the document is only partly read")
+	fs.DurationVar(&o.breakEvery, "break-every", 0, "pause for a break after this much reading without stopping, e.g. 20m")
+	fs.IntVar(&o.breakWords, "break-words", 0, "pause for a break after reading this many words without stopping")
+	fs.StringVar(&o.onLimit, "on-limit", limitPause, "what to do when a reading limit is reached: pause or quit")
+}
+
+// readFlags returns the flag set of `zippy read` and the options it fills in.
+func readFlags() (*flag.FlagSet, *readOptions) {
+	o := &readOptions{}
+	fs := flag.NewFlagSet("read", flag.ExitOnError)
+	o.register(fs)
+	fs.Usage = func() { usage(fs) }
+	return fs, o
+}
+
+// runRead implements `zippy read`, and with a pick mode `zippy list` and
+// `zippy history`, which pick the document first and apply the options to
+// reading it.
+func runRead(args []string, pick pickMode) int {
+	fs, o := readFlags()
+	fs.Parse(args)
+	if strayWatchMode(o.watch, fs.Arg(0)) {
+		fmt.Fprintf(os.Stderr, "Write -watch=%s, with an =.\n", fs.Arg(0))
+		return 2
+	}
+	// The file can also be named after the options, or before them.
+	if fs.NArg() > 0 && o.file == "" && pick == pickNone {
+		fs.Set("file", fs.Arg(0))
+		fs.Parse(fs.Args()[1:])
+	}
+	if strayWatchMode(o.watch, fs.Arg(0)) {
+		fmt.Fprintf(os.Stderr, "Write -watch=%s, with an =.\n", fs.Arg(0))
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Unexpected argument %q.\n", fs.Arg(0))
+		return 2
+	}
+	if err := applyEnv(fs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if o.file != "" {
+		if _, err := os.Stat(o.file); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if err := o.p.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fs.PrintDefaults()
+		return 1
+	}
+	if o.duration < 0 {
+		fmt.Fprintln(os.Stderr, "Duration must not be negative.")
+		return 1
+	}
+	if o.maxWords < 0 {
+		fmt.Fprintln(os.Stderr, "Max words must not be negative.")
+		return 1
+	}
+	if o.watch != watchOff && o.file == "" {
+		fmt.Fprintln(os.Stderr, "-watch needs -file.")
+		return 1
+	}
+	if o.follow && o.file == "" {
+		fmt.Fprintln(os.Stderr, "-follow needs -file.")
+		return 1
+	}
+	if o.follow && o.watch != watchOff {
+		fmt.Fprintln(os.Stderr, "-follow already reads what is added to the file; leave out -watch.")
+		return 1
+	}
+	if o.breakEvery < 0 || o.breakWords < 0 {
+		fmt.Fprintln(os.Stderr, "Breaks must not be negative.")
+		return 1
+	}
+	if firstRun(pick != pickNone) {
+		if err := setUp("start reading"); errors.Is(err, errSetupCancelled) {
+			return 1
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not save the settings:", err)
+		}
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read config:", err)
+		return 1
+	}
+	if cfg.WPM < 0 {
+		fmt.Fprintln(os.Stderr, "Config: wpm must be positive.")
+		return 1
+	}
+	if err := checkProfiles(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "Config:", err)
+		return 1
+	}
+	if err := cfg.Goal.check(); err != nil {
+		fmt.Fprintln(os.Stderr, "Config:", err)
+		return 1
+	}
+	// Options given on the command line win over the profile, and the
+	// profile over the rest of the config file.
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["wpm"] && cfg.WPM > 0 {
+		o.p.wpm = cfg.WPM
+	}
+	if o.journal == "" {
+		o.journal = expandHome(cfg.Journal)
+	}
+	if o.vault == "" {
+		o.vault = expandHome(cfg.Vault)
+	}
+	if o.dictionary == "" {
+		o.dictionary = cfg.Dictionary
+	}
+	dict, err := newDictionary(o.dictionary)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if o.translateTo != "" {
+		cfg.Translate.To = o.translateTo
+	}
+	translator, err := newTranslator(cfg.Translate)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Config:", err)
+		return 1
+	}
+	lex, err := newLexicon(cfg.Pronunciations)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Config:", err)
+		return 1
+	}
+	// Switching profiles while reading starts from the options as they are
+	// before -profile applies.
+	basePacing, baseThemeName := o.p, o.themeName
+	if baseThemeName == "" {
+		baseThemeName = cfg.Theme
+	}
+	if o.profile != "" {
+		pr, err := findProfile(o.profile, cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		pr.applyPacing(&o.p, set)
+		if !set["theme"] && pr.Theme != "" {
+			o.themeName = pr.Theme
+		}
+	}
+	if o.themeName == "" {
+		o.themeName = cfg.Theme
+	}
+	if _, err := resolveTheme(o.themeName, cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if o.pivotColor == "" {
+		o.pivotColor = cfg.PivotColor
+	}
+	if o.pivotStyle == "" {
+		o.pivotStyle = cfg.PivotStyle
+	}
+	o.noColor = o.noColor || os.Getenv("NO_COLOR") != ""
+	profile := termenv.NewOutput(os.Stdout).ColorProfile()
+	if o.pivotStyle == "" {
+		o.pivotStyle = defaultPivotStyle(profile, o.noColor)
+	}
+	emphasis, err := parsePivotStyle(o.pivotStyle)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if o.noColor && profile != termenv.Ascii {
+		// lipgloss drops all styling under NO_COLOR, which would leave the
+		// pivot indistinguishable; keep attributes like reverse video when
+		// the terminal has them.
+		lipgloss.SetColorProfile(termenv.ANSI)
+	}
+	// Every theme is prepared up front for the theme key to cycle through,
+	// starting from the chosen one.
+	var (
+		themes   []themeChoice
+		themeIdx int
+		baseIdx  int
+	)
+	for _, name := range themeNames(cfg) {
+		th, err := resolveTheme(name, cfg)
+		if err != nil {
+			continue
+		}
+		if o.pivotColor != "" {
+			th.Pivot = o.pivotColor
+		}
+		if o.noColor {
+			th = th.monochrome()
+		}
+		th.emphasis = emphasis
+		if name == o.themeName || (o.themeName == "" && name == defaultTheme) {
+			themeIdx = len(themes)
+		}
+		if name == baseThemeName || (baseThemeName == "" && name == defaultTheme) {
+			baseIdx = len(themes)
+		}
+		themes = append(themes, themeChoice{name: name, theme: th})
+	}
+	if !set["align"] && cfg.Align != "" {
+		o.align = cfg.Align
+	}
+	if !set["vertical"] && cfg.Vertical != nil {
+		o.vertical = *cfg.Vertical
+	}
+	if !set["wheel"] && cfg.Wheel != "" {
+		o.wheel = cfg.Wheel
+	}
+	wheelMode, err := parseWheel(o.wheel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if !set["min-wpm"] && cfg.MinWPM > 0 {
+		o.minWPM = cfg.MinWPM
+	}
+	if !set["max-wpm"] && cfg.MaxWPM > 0 {
+		o.maxWPM = cfg.MaxWPM
+	}
+	if o.minWPM <= 0 || o.maxWPM < o.minWPM {
+		fmt.Fprintln(os.Stderr, "The WPM bounds must be positive, with -min-wpm no more than -max-wpm.")
+		return 1
+	}
+	var wpmNotice string
+	o.p.wpm, wpmNotice = clampWPM(o.p.wpm, o.minWPM, o.maxWPM)
+	presets, err := checkPresets(cfg.WPMPresets)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if o.boxWidth != 0 && o.boxWidth < minBoxWidth {
+		fmt.Fprintf(os.Stderr, "-box must be at least %d columns\n", minBoxWidth)
+		return 1
+	}
+	caseMode, err := parseCase(o.wordCase)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	alignment, err := parseAlign(o.align)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := parseVertical(o.vertical); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	ret, err := parseReticle(o.reticle)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	keys, err := keyMapFrom(cfg.Keys)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Config:", err)
+		return 1
+	}
+	if o.skipStep <= 0 {
+		fmt.Fprintln(os.Stderr, "Skip step must be positive.")
+		return 1
+	}
+	if o.wpmStep <= 0 || o.fineStep <= 0 {
+		fmt.Fprintln(os.Stderr, "WPM steps must be positive.")
+		return 1
+	}
+	if o.resume && o.noResume {
+		fmt.Fprintln(os.Stderr, "Use only one of -resume and -no-resume.")
+		return 1
+	}
+	if o.onLimit != limitPause && o.onLimit != limitQuit {
+		fmt.Fprintf(os.Stderr, "Unknown -on-limit %q; use %q or %q.\n", o.onLimit, limitPause, limitQuit)
+		return 1
+	}
+	var start startPoint
+	if o.startAt != "" {
+		var err error
+		if start, err = parseStartAt(o.startAt); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if start.kind == startSearch && o.lazy {
+			fmt.Fprintln(os.Stderr, "-start-at with a search needs buffered input; drop -lazy or use a word number or percentage.")
+			return 1
+		}
+	}
+	if pick == pickNone && o.file == "" && isTerminal(os.Stdin) && hasHistory() {
+		// With nothing to read, offer what was read lately.
+		pick = pickHistory
+	}
+	if pick != pickNone {
+		chosen, err := chooseDocument(pick)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not list documents:", err)
+			return 1
+		}
+		if chosen == "" {
+			return 0
+		}
+		o.file, o.resume = chosen, !o.noResume
+	}
+
+	stream, err := buildStream(o.lazy, o.spool, o.follow, o.file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if initErr, ok := err.(streamInitError); ok && initErr.showUsage {
+			fs.PrintDefaults()
+		}
+		return 1
+	}
+	var (
+		saved docState
+		hash  string
+	)
+	key := docKey(o.file)
+	if key != "" {
+		if store, err := loadDocStore(); err == nil {
+			saved = *store.doc(key)
+		}
+		hash, _ = hashFile(o.file)
+	}
+	var reopen reopenFunc
+	if o.file != "" {
+		reopen = fileReopener(o.lazy, o.spool, o.follow, o.file)
+	}
+	m := model{
+		theme:         themes[themeIdx].theme,
+		themes:        themes,
+		themeIdx:      themeIdx,
+		profiles:      profileChoices(cfg),
+		profileName:   o.profile,
+		base:          &baseOptions{pacing: basePacing, themeIdx: baseIdx},
+		keyMap:        &keys,
+		reticle:       ret,
+		showNeighbors: o.neighbors,
+		showSparkline: o.sparkline,
+		showContext:   o.context,
+		frameStats:    frameStats{inFlight: new(atomic.Int64)},
+		showIPA:       o.ipa,
+		lexicon:       lex,
+		hooks:         cfg.Hooks,
+		zen:           o.zen,
+		banner:        o.banner,
+		boxWidth:      o.boxWidth,
+		wordCase:      caseMode,
+		wheelMode:     wheelMode,
+		presets:       presets,
+		align:         alignment,
+		vertical:      lipgloss.Position(o.vertical),
+		pacing:        o.p,
+		stream:        stream,
+		duration:      o.duration,
+		maxWords:      o.maxWords,
+		breakEvery:    o.breakEvery,
+		breakWords:    o.breakWords,
+		noConfirm:     o.yes,
+		onLimit:       o.onLimit,
+		loop:          o.loop && stream.SupportsRestart(),
+		skipStep:      o.skipStep,
+		wpmStep:       o.wpmStep,
+		fineStep:      o.fineStep,
+		minWPM:        o.minWPM,
+		maxWPM:        o.maxWPM,
+		markA:         -1,
+		markB:         -1,
+		docKey:        key,
+		reopen:        reopen,
+		watch:         o.watch,
+		bookmarks:     saved.Bookmarks,
+		unknown:       saved.Unknown,
+		dictionary:    dict,
+		translator:    translator,
+	}
+	m.notice = wpmNotice
+	m.restoreSettings(saved, set)
+	if o.startAt != "" {
+		m.startAt(start)
+	} else if pos, total, ok := savedPosition(key, hash); ok && !o.noResume {
+		if o.resume {
+			m.resumeAt(pos)
+		} else {
+			m.askResume, m.resumePos, m.resumeTotal = true, pos, total
+		}
+	} else if pos, sentence, ok := stalePosition(key, hash); ok && !o.noResume {
+		if o.resume {
+			pos, found := findPlace(o.file, sentence, pos)
+			m.placeFound(placeMsg{pos: pos, found: found})
+		} else {
+			m.askResume, m.resumeStale, m.resumePos, m.resumeContext = true, true, pos, sentence
+		}
+	}
+	if eager, ok := stream.(*eagerStream); ok && len(eager.words) > 0 && !o.noPreflight && !m.askResume {
+		m.preflight = newPreflight(eager.words, o.file)
+	}
+	if cfg.Goal.set() || (cfg.ShowStreak && m.preflight != nil) {
+		// Without the earlier sessions the goal's progress and the streak
+		// would be wrong, so they are left out if the statistics cannot be
+		// read.
+		if sessions, err := loadSessions(); err == nil {
+			now := time.Now()
+			if cfg.Goal.set() {
+				m.goal = cfg.Goal
+				m.goalWords, m.goalTime = readOn(sessions, now)
+				m.goalMet = m.goal.met(m.goalWords, m.goalTime)
+			}
+			if cfg.ShowStreak && m.preflight != nil {
+				m.preflight.streak, _ = streaks(sessions, now)
+			}
+		}
+	}
+
+	if o.timingLog != "" {
+		f, err := os.Create(o.timingLog)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not create the timing log:", err)
+			return 1
+		}
+		defer f.Close()
+		m.timing = newTimingLog(f)
+		defer func() {
+			// Quitting while playing leaves the last frame open.
+			m.timing.close(time.Now())
+			if err := m.timing.flush(); err != nil {
+				fmt.Fprintln(os.Stderr, "Could not write the timing log:", err)
+			}
+		}()
+	}
+
+	if o.serve != "" {
+		ln, err := net.Listen("tcp", serveAddr(o.serve))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not serve the mirror:", err)
+			return 1
+		}
+		m.mirror = newMirror(time.Now())
+		srv := &http.Server{Handler: m.mirror}
+		go srv.Serve(ln)
+		defer srv.Close()
+		m.notice = "Mirroring at " + mirrorURL(ln.Addr())
+	}
+
+	if status, err := newStatusFile(); err == nil {
+		m.status = status
+		defer status.remove()
+	}
+
+	opts := []tea.ProgramOption{tea.WithMouseCellMotion()}
+	if o.file == "" {
+		// The text comes in on stdin, so keys have to be read from the
+		// terminal itself.
+		tty, err := openTTY()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Piped text needs a terminal to read keys from:", err)
+			return 1
+		}
+		defer tty.Close()
+		opts = append(opts, tea.WithInput(tty))
+	}
+	var prog *tea.Program
+	// Media keys can be pressed as soon as zippy is on the bus, but have to
+	// wait for the program to reach it. Reading goes on without them if
+	// there is no bus or the platform has none.
+	ready := make(chan struct{})
+	if o.mediaKeys || cfg.MediaKeys {
+		player, err := startMediaKeys(func(msg tea.Msg) {
+			<-ready
+			prog.Send(msg)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not set up media keys: %v; carrying on without them\n", err)
+		} else {
+			defer player.close()
+			m.media = player
+		}
+	}
+	prog = tea.NewProgram(m, opts...)
+	close(ready)
+	stopHangup := quitOnHangup(prog)
+	if o.watch != watchOff {
+		stopWatching, err := watchFile(o.file, prog.Send)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not watch the file:", err)
+			return 1
+		}
+		defer stopWatching()
+	}
+	if err := m.startHook("start"); err != nil {
+		fmt.Fprintln(os.Stderr, "Could not run the start hook:", err)
+	}
+	started := time.Now()
+	final, err := prog.Run()
+	stopHangup()
+	var (
+		words int
+		read  time.Duration
+	)
+	if m, ok := final.(model); ok {
+		words, read = m.wordsRead, m.playedFor()
+		s := session{Document: key, Start: started, Words: words, Duration: read, Curve: m.curve.finish(read, words)}
+		if err := recordSession(s); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not save reading statistics:", err)
+		}
+		if o.journal != "" {
+			if err := appendJournal(o.journal, s); err != nil {
+				fmt.Fprintln(os.Stderr, "Could not write to the journal:", err)
+			}
+		}
+		if cfg.Highlights.set() {
+			if _, err := pushHighlights(cfg.Highlights, m); err != nil {
+				fmt.Fprintln(os.Stderr, "Could not send highlights:", err)
+			}
+		}
+		if cfg.Webhook.URL != "" && words > 0 {
+			if err := sendWebhook(cfg.Webhook, newWebhookPayload(s, m, time.Now())); err != nil {
+				fmt.Fprintln(os.Stderr, "Could not send the session to the webhook:", err)
+			}
+		}
+		if m.stream != stream {
+			// The file was reloaded.
+			stream = m.stream
+			hash, _ = hashFile(o.file)
+		}
+		if err := saveSettings(key, m); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not save reading settings:", err)
+		}
+		finished := "0"
+		if !m.stream.CanAdvance() {
+			finished = "1"
+		}
+		if err := m.runHook("finish", "ZIPPY_HOOK_FINISHED="+finished); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not run the finish hook:", err)
+		}
+	}
+	if err := savePosition(key, hash, stream, words, read); err != nil {
+		fmt.Fprintln(os.Stderr, "Could not save reading position:", err)
+	}
+	if o.vault != "" && key != "" {
+		var highlights []highlight
+		if m, ok := final.(model); ok {
+			for _, b := range m.bookmarks {
+				highlights = append(highlights, highlight{pos: b, text: m.snippet(b)})
+			}
+		}
+		if err := updateVaultNote(o.vault, key, highlights); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not update the note in the vault:", err)
+		}
+	}
+	if c, ok := stream.(io.Closer); ok {
+		c.Close()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	if m, ok := final.(model); ok && m.limitReached != "" && m.onLimit == limitQuit {
+		fmt.Println(m.summary())
+	}
+	return 0
+}
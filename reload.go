@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// reopenFunc builds a fresh stream from the document.
+type reopenFunc func() (stream, error)
+
+func fileReopener(lazy, spool, follow bool, path string) reopenFunc {
+	return func() (stream, error) {
+		// buildStream's errors are worded for startup.
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
+		}
+		return buildStream(lazy, spool, follow, path)
+	}
+}
+
+// reloadFile re-reads the file from disk, for documents edited while being
+// read. The reader then looks for the words it was at, or stays at the same
+// word number if they are gone.
+func (m *model) reloadFile() tea.Cmd {
+	if m.reopen == nil {
+		m.notice = "Only files can be reloaded"
+		return nil
+	}
+	s, err := m.reopen()
+	if err != nil {
+		m.notice = fmt.Sprintf("Could not reload: %v", err)
+		return nil
+	}
+	// The place, bookmarks and flagged words are looked for in the new text
+	// by the words found at them in the old one.
+	places := []place{placeAt(m.stream, m.stream.Pos())}
+	for _, b := range m.bookmarks {
+		places = append(places, placeAt(m.stream, b))
+	}
+	for _, w := range m.unknown {
+		places = append(places, placeAt(m.stream, w.Position))
+	}
+	old := m.stream
+	m.stream = s
+	// A word the old stream was still reading arrives for the new one with
+	// an error from the closed input, which the new one drops.
+	if c, ok := old.(io.Closer); ok {
+		c.Close()
+	}
+	m.changedOnDisk = false
+	// Other word indexes into the old text no longer mean anything.
+	m.jumps, m.jumpIdx = nil, 0
+	m.markA, m.markB = -1, -1
+	path, bookmarks := m.docKey, len(m.bookmarks)
+	return tea.Batch(s.Init(), func() tea.Msg {
+		found := findPlaces(path, places)
+		return placeMsg{
+			pos:       max(found[0], 0),
+			found:     found[0] >= 0,
+			reload:    true,
+			near:      places[0].pos,
+			bookmarks: found[1 : 1+bookmarks],
+			unknown:   found[1+bookmarks:],
+		}
+	})
+}
+
+// rebaseMarks moves the bookmarks and flagged words to where they were found
+// after reloading, dropping the ones that were not, and saves them. It
+// returns how many were dropped.
+func (m *model) rebaseMarks(bookmarks, unknown []int) (int, error) {
+	if len(bookmarks) != len(m.bookmarks) || len(unknown) != len(m.unknown) {
+		// Marks were added or removed since the reload; leave them be.
+		return 0, nil
+	}
+	if len(bookmarks) == 0 && len(unknown) == 0 {
+		return 0, nil
+	}
+	lost := 0
+	var moved []int
+	for _, pos := range bookmarks {
+		if pos < 0 {
+			lost++
+			continue
+		}
+		moved = append(moved, pos)
+	}
+	slices.Sort(moved)
+	m.bookmarks = slices.Compact(moved)
+	var words []unknownWord
+	for i, w := range m.unknown {
+		if unknown[i] < 0 {
+			lost++
+			continue
+		}
+		w.Position = unknown[i]
+		words = append(words, w)
+	}
+	m.unknown = words
+	if m.docKey == "" {
+		return lost, nil
+	}
+	saved, savedWords := slices.Clone(m.bookmarks), slices.Clone(m.unknown)
+	return lost, updateDoc(m.docKey, func(d *docState) { d.Bookmarks, d.Unknown = saved, savedWords })
+}
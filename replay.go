@@ -0,0 +1,205 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// replayModel plays a timing log back, showing each frame for as long as it
+// was shown when the log was recorded.
+type replayModel struct {
+	rows []timingRow
+	// shown is how long each row stays on screen: until the next row began,
+	// so that pauses are replayed too, or with skipPauses only as long as
+	// the frame was playing.
+	shown   []time.Duration
+	idx     int
+	running bool
+	// gen tells the ticks of the current run from those scheduled before a
+	// pause.
+	gen    int
+	theme  theme
+	align  alignment
+	width  int
+	height int
+	clock  clock
+}
+
+type replayTickMsg struct{ gen int }
+
+func newReplayModel(rows []timingRow, skipPauses bool, th theme, align alignment) replayModel {
+	m := replayModel{rows: rows, theme: th, align: align, running: true}
+	for i, row := range rows {
+		d := row.duration
+		if !skipPauses && i+1 < len(rows) {
+			if gap := rows[i+1].start.Sub(row.start); gap > 0 {
+				d = gap
+			}
+		}
+		m.shown = append(m.shown, d)
+	}
+	return m
+}
+
+func (m replayModel) tick() tea.Cmd {
+	if m.idx >= len(m.rows) {
+		return nil
+	}
+	c := m.clock
+	if c == nil {
+		c = wallClock{}
+	}
+	gen := m.gen
+	return c.Tick(m.shown[m.idx], func(time.Time) tea.Msg { return replayTickMsg{gen} })
+}
+
+func (m replayModel) Init() tea.Cmd { return m.tick() }
+
+func (m replayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case replayTickMsg:
+		if msg.gen != m.gen || !m.running {
+			return m, nil
+		}
+		if m.idx+1 >= len(m.rows) {
+			m.running = false
+			return m, nil
+		}
+		m.idx++
+		return m, m.tick()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case " ":
+			// Carrying on shows the frame for its whole time again.
+			m.gen++
+			m.running = !m.running
+			if m.running {
+				if m.idx+1 >= len(m.rows) {
+					m.idx = 0
+				}
+				return m, m.tick()
+			}
+		}
+	}
+	return m, nil
+}
+
+// elapsed is how far into the recording the current frame began.
+func (m replayModel) elapsed() time.Duration {
+	var d time.Duration
+	for _, s := range m.shown[:m.idx] {
+		d += s
+	}
+	return d
+}
+
+func (m replayModel) View() string {
+	if len(m.rows) == 0 {
+		return "No frames to replay."
+	}
+	if m.width == 0 || m.height == 0 {
+		return "Loading..."
+	}
+	row := m.rows[m.idx]
+	block, _ := formatWord(row.word, "", "", m.width, m.align, m.theme)
+	var total time.Duration
+	for _, s := range m.shown {
+		total += s
+	}
+	state := "Replay"
+	switch {
+	case !m.running && m.idx+1 >= len(m.rows):
+		state = "End of replay"
+	case !m.running:
+		state = "Paused"
+	}
+	status := fmt.Sprintf("%s  frame %d/%d  word %s  %s of %s  shown %s (meant %s)  space: play/pause  q: quit",
+		state, m.idx+1, len(m.rows), formatCount(row.position+1),
+		m.elapsed().Round(time.Second), total.Round(time.Second),
+		row.duration.Round(time.Millisecond), row.scheduled.Round(time.Millisecond))
+	body := m.theme.place(m.width, max(m.height-1, 1), lipgloss.Left, lipgloss.Center, block)
+	if m.height < 2 {
+		return body
+	}
+	return body + "\n" + m.theme.status().Width(m.width).Render(truncate(status, m.width))
+}
+
+// runReplay implements `zippy replay`, which plays a -timing-log back.
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	var skipPauses bool
+	fs.BoolVar(&skipPauses, "skip-pauses", false, "leave out the time playback was paused, showing each frame only as long as it played")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s replay [options] LOG.csv\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Play back a session recorded with -timing-log, with its original timing.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	rows, err := readTimingLog(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	if len(rows) == 0 {
+		fmt.Fprintf(os.Stderr, "%s has no frames to replay.\n", fs.Arg(0))
+		return 1
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read config:", err)
+		return 1
+	}
+	th, err := resolveTheme(cfg.Theme, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	noColor := os.Getenv("NO_COLOR") != ""
+	style := cfg.PivotStyle
+	if style == "" {
+		style = defaultPivotStyle(termenv.NewOutput(os.Stdout).ColorProfile(), noColor)
+	}
+	if th.emphasis, err = parsePivotStyle(style); err != nil {
+		fmt.Fprintln(os.Stderr, "Config:", err)
+		return 1
+	}
+	if noColor {
+		th = th.monochrome()
+	}
+	align := alignORP
+	if cfg.Align != "" {
+		if align, err = parseAlign(cfg.Align); err != nil {
+			fmt.Fprintln(os.Stderr, "Config:", err)
+			return 1
+		}
+	}
+
+	m := newReplayModel(rows, skipPauses, th, align)
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
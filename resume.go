@@ -0,0 +1,325 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// savePosition records where reading stopped so that the next run on the
+// same content can pick up from there, along with how much was read.
+func savePosition(key, hash string, s stream, words int, read time.Duration) error {
+	if key == "" || hash == "" || s.Pos() < 0 {
+		return nil
+	}
+	known, total := s.Total()
+	return updateDoc(key, func(d *docState) {
+		d.Position = s.Pos()
+		d.Hash = hash
+		if known {
+			d.Total = total
+		}
+		d.Context = contextAt(s)
+		d.LastRead = time.Now()
+		d.WordsRead += words
+		d.ReadTime += read
+	})
+}
+
+// savedPosition returns the saved word index for a document, and its word
+// count if known, if the saved state was recorded for the same content.
+func savedPosition(key, hash string) (pos, total int, ok bool) {
+	if key == "" || hash == "" {
+		return 0, 0, false
+	}
+	store, err := loadDocStore()
+	if err != nil {
+		return 0, 0, false
+	}
+	d, found := store.Documents[key]
+	if !found || d.Hash != hash || d.Position <= 0 {
+		return 0, 0, false
+	}
+	return d.Position, d.Total, true
+}
+
+// stalePosition returns the saved position and the words found there when
+// the document has changed since it was saved, so that the place can be
+// looked for again.
+func stalePosition(key, hash string) (pos int, context []string, ok bool) {
+	if key == "" || hash == "" {
+		return 0, nil, false
+	}
+	store, err := loadDocStore()
+	if err != nil {
+		return 0, nil, false
+	}
+	d, found := store.Documents[key]
+	if !found || d.Hash == "" || d.Hash == hash || d.Position <= 0 {
+		return 0, nil, false
+	}
+	return d.Position, d.Context, true
+}
+
+// contextAt returns the words from the current position on, which identify
+// the place again if the document is edited.
+func contextAt(s stream) []string {
+	return contextFrom(s, 0)
+}
+
+// contextFrom returns the words from offset on, as contextAt does.
+func contextFrom(s stream, offset int) []string {
+	var words []string
+	for i := range snippetWords {
+		tok, ok := s.Peek(offset + i)
+		if !ok {
+			break
+		}
+		words = append(words, tok.text)
+	}
+	return words
+}
+
+// rematch finds where context now starts in words: the position matching the
+// most of its words, preferring the one nearest to near on a tie. More than
+// half of the words must match.
+func rematch(words []token, context []string, near int) (int, bool) {
+	p := newPlaceMatcher(context, near)
+	for _, w := range words {
+		p.add(w.text)
+	}
+	return p.result()
+}
+
+// placeMatcher does rematch's work a word at a time, keeping only the last
+// few words, so that a document need not be read into memory to find the
+// place in it.
+type placeMatcher struct {
+	want []string
+	near int
+	// window holds the last len(want) words; seen counts every word added.
+	window          []string
+	seen            int
+	best, bestScore int
+}
+
+func newPlaceMatcher(context []string, near int) *placeMatcher {
+	want := make([]string, len(context))
+	for i, w := range context {
+		want[i] = normalizeWord(w)
+	}
+	return &placeMatcher{want: want, near: near}
+}
+
+func (p *placeMatcher) add(word string) {
+	p.window = append(p.window, normalizeWord(word))
+	p.seen++
+	if len(p.window) > len(p.want) {
+		p.window = p.window[1:]
+	}
+	if len(p.window) == len(p.want) {
+		p.score(p.seen-len(p.window), p.window)
+	}
+}
+
+// score considers the place at word i, where words are the ones from i on.
+func (p *placeMatcher) score(i int, words []string) {
+	score := 0
+	for j, w := range words {
+		if w == p.want[j] {
+			score++
+		}
+	}
+	if score > p.bestScore || score == p.bestScore && abs(i-p.near) < abs(p.best-p.near) {
+		p.best, p.bestScore = i, score
+	}
+}
+
+// result scores the places too near the end for a full window, and returns
+// the best place.
+func (p *placeMatcher) result() (int, bool) {
+	first := p.seen - len(p.window)
+	start := 1
+	if len(p.window) < len(p.want) {
+		start = 0
+	}
+	for k := start; k < len(p.window); k++ {
+		p.score(first+k, p.window[k:])
+	}
+	return p.best, p.bestScore*2 > len(p.want)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// findPlace looks for the saved context in the current content of a file.
+func findPlace(path string, context []string, near int) (int, bool) {
+	pos := findPlaces(path, []place{{pos: near, context: context}})[0]
+	return max(pos, 0), pos >= 0
+}
+
+// place is a word position with the words found there, to look for again
+// in a changed document.
+type place struct {
+	pos     int
+	context []string
+}
+
+// placeAt is the place at word pos of the stream, or one without context if
+// the stream no longer has the word.
+func placeAt(s stream, pos int) place {
+	return place{pos: pos, context: contextFrom(s, pos-s.Pos())}
+}
+
+// findPlaces looks for several places in one pass over a file, returning
+// where each is now or -1 if it was not found. The file is read a word at a
+// time, as it may be too big to read whole under -lazy.
+func findPlaces(path string, places []place) []int {
+	found := make([]int, len(places))
+	for i := range found {
+		found[i] = -1
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return found
+	}
+	defer f.Close()
+	matchers := make([]*placeMatcher, len(places))
+	for i, p := range places {
+		matchers[i] = newPlaceMatcher(p.context, p.pos)
+	}
+	t := newTokenizer(f)
+	for {
+		tok, done, err := t.next()
+		if err != nil {
+			return found
+		}
+		if tok.text != "" {
+			for _, p := range matchers {
+				p.add(tok.text)
+			}
+		}
+		if done {
+			break
+		}
+	}
+	for i, p := range matchers {
+		if pos, ok := p.result(); ok && len(places[i].context) > 0 {
+			found[i] = pos
+		}
+	}
+	return found
+}
+
+// placeMsg reports the outcome of looking for the saved place in a changed
+// document, at startup or after reloading it.
+type placeMsg struct {
+	pos   int
+	found bool
+	// reload is set when looking for the place after reloading the file,
+	// where near is kept if the place is not found.
+	reload bool
+	near   int
+	// bookmarks and unknown are where the bookmarks and flagged words are
+	// after reloading, in the order they were, or -1 where they were lost.
+	bookmarks, unknown []int
+}
+
+func findPlaceCmd(path string, context []string, near int) tea.Cmd {
+	return func() tea.Msg {
+		pos, found := findPlace(path, context, near)
+		return placeMsg{pos: pos, found: found, near: near}
+	}
+}
+
+// resumeQuestion is the startup prompt offering to continue where the last
+// session stopped.
+func (m model) resumeQuestion() string {
+	if m.resumeStale {
+		return "This file has changed since you last read it.\nLook for where you left off? [y/n]"
+	}
+	q := "Resume at word " + formatCount(m.resumePos+1)
+	total := m.resumeTotal
+	if known, n := m.stream.Total(); known {
+		total = n
+	}
+	if total > 0 {
+		q += fmt.Sprintf(" (%d%%)", (m.resumePos+1)*100/total)
+	}
+	return q + "? [y/n]"
+}
+
+// answerResume handles the resume prompt: y jumps to the saved position, or
+// goes looking for it if the file changed, and n starts from the beginning.
+// Quit asks first as it does while reading; only ctrl+c leaves at once.
+func (m *model) answerResume(msg tea.KeyMsg) tea.Cmd {
+	if key.Matches(msg, m.keys().Quit) && msg.String() != "ctrl+c" {
+		m.askResume = false
+		return m.quit()
+	}
+	switch msg.String() {
+	case "y", "Y", "enter":
+		m.askResume = false
+		if m.resumeStale {
+			m.notice = "Looking for your place..."
+			return findPlaceCmd(m.docKey, m.resumeContext, m.resumePos)
+		}
+		m.resumeAt(m.resumePos)
+	case "n", "N", "esc":
+	case "ctrl+c":
+		return tea.Quit
+	default:
+		return nil
+	}
+	m.askResume = false
+	return nil
+}
+
+// placeFound finishes a search for the saved place in a changed document.
+func (m *model) placeFound(msg placeMsg) {
+	if msg.reload {
+		if !msg.found {
+			m.resumeAt(msg.near)
+			m.notice = fmt.Sprintf("Reloaded; the text changed here, staying at word %s", formatCount(msg.near+1))
+		} else {
+			m.resumeAt(msg.pos)
+			m.notice = "Reloaded"
+		}
+		lost, err := m.rebaseMarks(msg.bookmarks, msg.unknown)
+		switch {
+		case err != nil:
+			m.notice = fmt.Sprintf("Could not save bookmarks: %v", err)
+		case lost > 0:
+			m.notice += fmt.Sprintf("; %d bookmarks or flagged words were not found again and were dropped", lost)
+		}
+		return
+	}
+	if !msg.found {
+		m.notice = "Could not find where you left off; starting from the beginning"
+		return
+	}
+	m.resumeAt(msg.pos)
+	m.notice = fmt.Sprintf("Found your place at word %s", formatCount(msg.pos+1))
+}
+
+// resumeAt jumps to pos, or arranges to once a lazy stream has loaded.
+func (m *model) resumeAt(pos int) {
+	m.startAt(startPoint{kind: startWord, word: pos})
+}
+
+// formatCount formats n with thousands separators.
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0 && s[i-1] != '-'; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
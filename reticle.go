@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// reticleMode is how the pivot column is marked between the words: not at
+// all, with a tick above and below it, or with ticks on horizontal rules
+// across the screen.
+type reticleMode int
+
+const (
+	reticleOff reticleMode = iota
+	reticleTicks
+	reticleRules
+)
+
+var reticleNames = []string{"off", "ticks", "rules"}
+
+func (r reticleMode) String() string { return reticleNames[r] }
+
+func parseReticle(s string) (reticleMode, error) {
+	for i, name := range reticleNames {
+		if s == name {
+			return reticleMode(i), nil
+		}
+	}
+	return reticleOff, fmt.Errorf("unknown reticle %q; use off, ticks or rules", s)
+}
+
+// next cycles through the modes for the reticle key.
+func (r reticleMode) next() reticleMode {
+	return (r + 1) % reticleMode(len(reticleNames))
+}
+
+// reticleLines returns the lines to draw above and below the word so that
+// they point at column col.
+func (m model) reticleLines(col int) (above, below string) {
+	col = min(max(col, 0), max(m.width-1, 0))
+	switch m.reticle {
+	case reticleTicks:
+		tick := strings.Repeat(" ", col) + "│"
+		return m.theme.dim().Render(tick), m.theme.dim().Render(tick)
+	case reticleRules:
+		left, right := strings.Repeat("─", col), strings.Repeat("─", max(m.width-col-1, 0))
+		return m.theme.dim().Render(left + "┬" + right), m.theme.dim().Render(left + "┴" + right)
+	}
+	return "", ""
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// searchFrom looks for query starting at the word offset from the current
+// position, stepping by dir (1 forward, -1 backward). It returns the offset
+// of the first word of the match.
+func (m model) searchFrom(query string, offset, dir int) (int, bool) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return 0, false
+	}
+	for i := offset; ; i += dir {
+		if _, ok := m.stream.Peek(i); !ok {
+			return 0, false
+		}
+		if m.matchesAt(i, terms) {
+			return i, true
+		}
+	}
+}
+
+// matchesAt reports whether the words from offset onwards spell out terms.
+// The last term only needs to be a prefix so that partial words match.
+func (m model) matchesAt(offset int, terms []string) bool {
+	for j, term := range terms {
+		tok, ok := m.stream.Peek(offset + j)
+		if !ok {
+			return false
+		}
+		word := normalizeWord(tok.text)
+		if j == len(terms)-1 {
+			if !strings.HasPrefix(word, term) {
+				return false
+			}
+		} else if word != term {
+			return false
+		}
+	}
+	return true
+}
+
+// search jumps to the next match of query in the given direction and
+// remembers it for n/N.
+func (m *model) search(query string, backward bool) {
+	if query == "" {
+		query = m.lastSearch
+	}
+	m.lastSearch = query
+	m.searchBackward = backward
+	m.repeatSearch(false)
+}
+
+// repeatSearch jumps to the next match of the last search, in the opposite
+// direction when reverse is set.
+func (m *model) repeatSearch(reverse bool) {
+	if m.lastSearch == "" {
+		return
+	}
+	dir := 1
+	if m.searchBackward != reverse {
+		dir = -1
+	}
+	offset, ok := m.searchFrom(m.lastSearch, dir, dir)
+	if !ok {
+		m.notice = "Not found: " + m.lastSearch
+		return
+	}
+	m.stream.Seek(m.stream.Pos() + offset)
+}
+
+func searchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if term := normalizeWord(field); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// normalizeWord lowercases a word and strips surrounding punctuation so that
+// searches ignore case and attached commas or quotes.
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
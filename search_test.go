@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+func TestSearchPhrase(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("The cat sat. The Cat, sat again. A dog."), false)}
+	m.search("cat sat", false)
+	if m.stream.Pos() != 1 {
+		t.Fatalf("expected match at 1, got %d", m.stream.Pos())
+	}
+	m.repeatSearch(false)
+	if m.stream.Pos() != 4 {
+		t.Fatalf("expected next match at 4, got %d", m.stream.Pos())
+	}
+	m.repeatSearch(true)
+	if m.stream.Pos() != 1 {
+		t.Fatalf("expected previous match at 1, got %d", m.stream.Pos())
+	}
+	m.repeatSearch(false)
+	m.repeatSearch(false)
+	if m.stream.Pos() != 4 || m.notice == "" {
+		t.Fatalf("expected to stay at 4 with a notice, got %d %q", m.stream.Pos(), m.notice)
+	}
+}
+
+func TestSearchBackwardPrefix(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("running late, run faster"), false)}
+	m.stream.Seek(3)
+	m.search("run", true)
+	if m.stream.Pos() != 2 {
+		t.Fatalf("expected match at 2, got %d", m.stream.Pos())
+	}
+	m.repeatSearch(false)
+	if m.stream.Pos() != 0 {
+		t.Fatalf("expected prefix match at 0, got %d", m.stream.Pos())
+	}
+}
+
+func TestCountMatchesCoversWholeDocument(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("a cat, a cat. a dog"), false)}
+	m.stream.Seek(3)
+	if got := m.countMatches(searchTerms("a")); got != 3 {
+		t.Fatalf("expected 3 matches, got %d", got)
+	}
+	if got := m.countMatches(searchTerms("a cat")); got != 2 {
+		t.Fatalf("expected 2 phrase matches, got %d", got)
+	}
+}
+
+func TestSearchHitsFollowTheQuery(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("one cat two cat three"), true)}
+	m = press(m, "/", "c", "a")
+	if m.hits.query != "ca" || m.hits.count != 2 || !m.hits.found || m.hits.at != 2 {
+		t.Fatalf("expected two matches, the next at word 2, got %+v", m.hits)
+	}
+	if len(m.hits.before) != 1 || m.hits.match[0] != "cat" {
+		t.Fatalf("expected the context of the first cat, got %+v", m.hits)
+	}
+	m = press(m, "x")
+	if m.hits.found || m.hits.count != 0 {
+		t.Fatalf("expected no matches for cax, got %+v", m.hits)
+	}
+}
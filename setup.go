@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// speedTestText is read at the reader's usual pace to measure it.
+const speedTestText = `The lighthouse keeper had kept the same routine for thirty years. Every evening he climbed the spiral stairs, polished the great lens until it shone, and lit the lamp just as the sun slipped under the sea. Ships passed in the dark without ever knowing his name, but he liked to think that each of them carried a little of his care along with its cargo. On stormy nights he stayed awake until morning, listening to the wind and counting the flashes, one every ten seconds, steady as a heartbeat. When the town finally replaced him with an automatic light, he moved into a cottage on the hill, and still he went to the window each evening to watch the beam sweep across the water.`
+
+// leftHandKeys moves stepping and the speed under the left hand, for
+// readers who keep the right one on the mouse. The keys they take from
+// skim, the A-B loop and define are made up elsewhere.
+var leftHandKeys = map[string][]string{
+	"back":    {"a", "left"},
+	"forward": {"d", "right"},
+	"faster":  {"w", "+", "=", "up"},
+	"slower":  {"s", "-", "_", "down"},
+	"skim":    {"e"},
+	"ab_loop": {"x"},
+	"define":  {"t"},
+}
+
+type setupStep int
+
+const (
+	setupWelcome setupStep = iota
+	setupReading
+	setupSpeed
+	setupTheme
+	setupHand
+	setupDone
+)
+
+// setupModel is the first-run wizard. It measures the reading speed, picks
+// a theme and a hand for the keys, and leaves the choices in cfg. Only the
+// steps confirmed with enter change cfg, so skipping with esc keeps the rest
+// of it as it was.
+type setupModel struct {
+	step   setupStep
+	cfg    config
+	path   string
+	width  int
+	height int
+	// started is when the speed test passage was shown; measured is the
+	// speed it was read at and wpm the one offered to start from.
+	started  time.Time
+	measured int
+	wpm      int
+	themes   []string
+	theme    int
+	leftHand bool
+	// finish is what enter does on the last screen.
+	finish    string
+	cancelled bool
+}
+
+func newSetupModel(cfg config, path, finish string) setupModel {
+	m := setupModel{cfg: cfg, path: path, finish: finish, themes: themeNames(cfg), wpm: cfg.WPM, leftHand: usesLeftHand(cfg.Keys)}
+	if m.wpm == 0 {
+		m.wpm = 300
+	}
+	for i, name := range m.themes {
+		if name == cfg.Theme || (cfg.Theme == "" && name == defaultTheme) {
+			m.theme = i
+		}
+	}
+	return m
+}
+
+func (m setupModel) Init() tea.Cmd { return nil }
+
+func (m setupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			m.cancelled = true
+			return m, tea.Quit
+		case "esc":
+			// Skipping keeps what was chosen so far, so the wizard does
+			// not come back next time.
+			m.step = setupDone
+			return m, nil
+		}
+		return m.handleKey(msg.String())
+	}
+	return m, nil
+}
+
+func (m setupModel) handleKey(k string) (tea.Model, tea.Cmd) {
+	switch m.step {
+	case setupWelcome:
+		if k == "enter" {
+			m.step, m.started = setupReading, time.Now()
+		}
+	case setupReading:
+		if k == "enter" {
+			m.measured = measuredWPM(len(strings.Fields(speedTestText)), time.Since(m.started))
+			m.wpm = suggestedWPM(m.measured)
+			m.step = setupSpeed
+		}
+	case setupSpeed:
+		switch k {
+		case "up", "k", "+":
+			m.wpm = min(m.wpm+defaultWPMStep, defaultMaxWPM)
+		case "down", "j", "-":
+			m.wpm = max(m.wpm-defaultWPMStep, defaultMinWPM)
+		case "enter":
+			m.cfg.WPM = m.wpm
+			m.step = setupTheme
+		}
+	case setupTheme:
+		switch k {
+		case "up", "k":
+			m.theme = (m.theme + len(m.themes) - 1) % len(m.themes)
+		case "down", "j":
+			m.theme = (m.theme + 1) % len(m.themes)
+		case "enter":
+			m.cfg.Theme = m.themes[m.theme]
+			m.step = setupHand
+		}
+	case setupHand:
+		switch k {
+		case "up", "down", "k", "j", "tab":
+			m.leftHand = !m.leftHand
+		case "enter":
+			m.cfg.Keys = withLeftHand(m.cfg.Keys, m.leftHand)
+			m.step = setupDone
+		}
+	case setupDone:
+		if k == "enter" {
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// usesLeftHand reports whether keys already has every left-hand binding.
+func usesLeftHand(keys map[string][]string) bool {
+	for name, k := range leftHandKeys {
+		if !slices.Equal(keys[name], k) {
+			return false
+		}
+	}
+	return true
+}
+
+// withLeftHand adds the left-hand bindings to keys, or takes them out
+// again, leaving any other keys in the config file alone.
+func withLeftHand(keys map[string][]string, left bool) map[string][]string {
+	keys = maps.Clone(keys)
+	for name, k := range leftHandKeys {
+		switch {
+		case left:
+			if keys == nil {
+				keys = map[string][]string{}
+			}
+			keys[name] = k
+		case slices.Equal(keys[name], k):
+			delete(keys, name)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return keys
+}
+
+// measuredWPM is the speed words were read at in d.
+func measuredWPM(words int, d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(float64(words) / d.Minutes())
+}
+
+// suggestedWPM is where to start flashing words for someone who reads at
+// wpm: a little faster, since RSVP saves the eye movements, rounded to the
+// speed keys' step.
+func suggestedWPM(wpm int) int {
+	s := (wpm*5/4 + defaultWPMStep/2) / defaultWPMStep * defaultWPMStep
+	return min(max(s, 150), 900)
+}
+
+func (m setupModel) View() string {
+	width := 70
+	if m.width > 0 {
+		width = min(max(m.width-4, 20), width)
+	}
+	para := lipgloss.NewStyle().Width(width)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#777777"))
+	var b strings.Builder
+	switch m.step {
+	case setupWelcome:
+		b.WriteString(para.Render("Welcome to zippy. Three quick questions set it up: how fast you read, the colors and which hand works the keys."))
+		b.WriteString("\n\n" + dim.Render("enter: start  esc: skip"))
+	case setupReading:
+		b.WriteString(para.Render("Read this at your usual pace, then press enter:"))
+		b.WriteString("\n\n" + para.Render(speedTestText))
+	case setupSpeed:
+		b.WriteString(para.Render(fmt.Sprintf("You read %d words a minute. Flashing the words in place saves moving your eyes, so zippy will start you a little faster:", m.measured)))
+		fmt.Fprintf(&b, "\n\n  %d WPM\n\n", m.wpm)
+		b.WriteString(dim.Render("up/down: change  enter: next"))
+	case setupTheme:
+		b.WriteString(para.Render("Pick the colors:") + "\n\n")
+		for i, name := range m.themes {
+			cursor := "  "
+			if i == m.theme {
+				cursor = "> "
+			}
+			b.WriteString(cursor + m.themePreview(name) + "\n")
+		}
+		b.WriteString("\n" + dim.Render("up/down: choose  enter: next"))
+	case setupHand:
+		b.WriteString(para.Render("Which hand works the keys?") + "\n\n")
+		choices := []string{
+			"Right: h/l or the arrows step, +/- change the speed",
+			"Left: a/d step, w/s change the speed, e skims",
+		}
+		for i, c := range choices {
+			cursor := "  "
+			if (i == 1) == m.leftHand {
+				cursor = "> "
+			}
+			b.WriteString(cursor + c + "\n")
+		}
+		b.WriteString("\n" + dim.Render("up/down: choose  enter: next"))
+	case setupDone:
+		b.WriteString(para.Render(fmt.Sprintf("All set. The settings go in %s, to change there or with `zippy setup` any time.", m.path)))
+		b.WriteString("\n\n" + dim.Render("enter: "+m.finish))
+	}
+	if m.width == 0 || m.height == 0 {
+		return b.String()
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, b.String())
+}
+
+// themePreview shows a theme's name in its own colors, pivot included.
+func (m setupModel) themePreview(name string) string {
+	th, err := resolveTheme(name, m.cfg)
+	if err != nil {
+		return name
+	}
+	th.emphasis = pivotEmphasis{bold: true}
+	word, pivot := fmt.Sprintf(" %-10s", name), 3
+	runes := []rune(word)
+	return th.text().Render(string(runes[:pivot])) +
+		th.pivot().Render(string(runes[pivot])) +
+		th.text().Render(string(runes[pivot+1:])+" ")
+}
+
+// firstRun reports whether there is no config file yet and someone at a
+// terminal to set one up.
+func firstRun(picking bool) bool {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) || picking {
+		return false
+	}
+	path, err := configPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// errSetupCancelled means ctrl+c was pressed during the wizard.
+var errSetupCancelled = errors.New("setup cancelled")
+
+// setUp runs the wizard and writes the config file with the choices made.
+func setUp(finish string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	final, err := tea.NewProgram(newSetupModel(cfg, path, finish), tea.WithAltScreen()).Run()
+	if err != nil {
+		return err
+	}
+	m := final.(setupModel)
+	if m.cancelled {
+		return errSetupCancelled
+	}
+	return saveConfig(path, m.cfg)
+}
+
+// saveConfig writes the config file.
+func saveConfig(path string, cfg config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// runSetup implements `zippy setup`, which runs the first-run wizard again.
+func runSetup(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s setup\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Choose the starting speed, theme and keys, and save them in the config file.")
+		return 2
+	}
+	if err := setUp("finish"); err != nil && !errors.Is(err, errSetupCancelled) {
+		fmt.Fprintln(os.Stderr, "Could not save the settings:", err)
+		return 1
+	}
+	return 0
+}
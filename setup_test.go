@@ -0,0 +1,106 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSetupWizard(t *testing.T) {
+	m := newSetupModel(config{}, "/tmp/config.json", "start reading")
+	send := func(keys ...string) {
+		for _, k := range keys {
+			next, _ := m.Update(keyMsg(k))
+			m = next.(setupModel)
+		}
+	}
+	send("enter")
+	if m.step != setupReading || !strings.Contains(m.View(), "The lighthouse") {
+		t.Fatalf("expected the speed test passage, got %q", m.View())
+	}
+	// Pretend the passage took a minute to read.
+	m.started = time.Now().Add(-time.Minute)
+	send("enter")
+	words := len(strings.Fields(speedTestText))
+	if m.measured < words-1 || m.measured > words || m.wpm != suggestedWPM(m.measured) {
+		t.Fatalf("expected about %d WPM measured, got %d suggesting %d", words, m.measured, m.wpm)
+	}
+	send("up", "enter")
+	if m.cfg.WPM != suggestedWPM(m.measured)+defaultWPMStep || m.step != setupTheme {
+		t.Fatalf("expected up to raise the speed, got %d", m.cfg.WPM)
+	}
+	send("down", "enter", "down", "enter")
+	if m.cfg.Theme != "light" || m.cfg.Keys == nil || m.step != setupDone {
+		t.Fatalf("expected the light theme and left-hand keys, got %q %v", m.cfg.Theme, m.cfg.Keys)
+	}
+	if next, cmd := m.Update(keyMsg("enter")); cmd == nil || cmd() != tea.Quit() || next.(setupModel).cancelled {
+		t.Fatal("expected enter to finish")
+	}
+
+	m = newSetupModel(config{}, "/tmp/config.json", "finish")
+	if send("esc"); m.step != setupDone || m.cfg.WPM != 0 {
+		t.Fatalf("expected esc to skip to the end without a speed, got %v %d", m.step, m.cfg.WPM)
+	}
+	m = newSetupModel(config{WPM: 450}, "/tmp/config.json", "finish")
+	if send("enter", "enter", "up", "esc"); m.cfg.WPM != 450 {
+		t.Fatalf("expected an unconfirmed speed to leave the config alone, got %d", m.cfg.WPM)
+	}
+}
+
+func TestSetupHandKeepsOtherKeys(t *testing.T) {
+	keys := map[string][]string{"quit": {"Q"}}
+	m := newSetupModel(config{Keys: keys}, "/tmp/config.json", "finish")
+	m.step = setupHand
+	next, _ := m.Update(keyMsg("down"))
+	next, _ = next.Update(keyMsg("enter"))
+	m = next.(setupModel)
+	if m.cfg.Keys["quit"][0] != "Q" || m.cfg.Keys["back"][0] != "a" || len(keys) != 1 {
+		t.Fatalf("expected the left-hand keys added to the others, got %v", m.cfg.Keys)
+	}
+
+	m = newSetupModel(m.cfg, "/tmp/config.json", "finish")
+	if !m.leftHand {
+		t.Fatal("expected the hand step to start from the left-hand keys in the config")
+	}
+	m.step = setupHand
+	next, _ = m.Update(keyMsg("down"))
+	next, _ = next.Update(keyMsg("enter"))
+	if got := next.(setupModel).cfg.Keys; len(got) != 1 || got["quit"][0] != "Q" {
+		t.Fatalf("expected only the left-hand keys taken out, got %v", got)
+	}
+}
+
+func TestSuggestedWPM(t *testing.T) {
+	for _, c := range []struct{ read, want int }{{100, 150}, {240, 300}, {300, 375}, {2000, 900}} {
+		if got := suggestedWPM(c.read); got != c.want {
+			t.Errorf("suggestedWPM(%d) = %d, want %d", c.read, got, c.want)
+		}
+	}
+}
+
+func TestLeftHandKeys(t *testing.T) {
+	k, err := keyMapFrom(leftHandKeys)
+	if err != nil {
+		t.Fatalf("keyMapFrom: %v", err)
+	}
+	for _, b := range k.bindings() {
+		if !b.binding.Enabled() {
+			t.Errorf("expected %s to keep a key", b.name)
+		}
+	}
+}
+
+func TestSaveConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zippy", "config.json")
+	t.Setenv("ZIPPY_CONFIG", path)
+	if err := saveConfig(path, config{WPM: 350, Theme: "night", Keys: leftHandKeys}); err != nil {
+		t.Fatalf("saveConfig: %v", err)
+	}
+	cfg, err := loadConfig()
+	if err != nil || cfg.WPM != 350 || cfg.Theme != "night" || cfg.Keys["back"][0] != "a" {
+		t.Fatalf("expected the saved config back, got %+v (%v)", cfg, err)
+	}
+}
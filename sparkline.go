@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// sparkWindow is how far back the speed sparkline goes, in playing time.
+const sparkWindow = 4 * time.Minute
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws the speed read at over the last sparkWindow of playing, a
+// bar per point of the speed curve. Bars are scaled from zero to the target
+// speed or the fastest point, whichever is higher, so holding the pace
+// shows as an even row near the top.
+func (m model) sparkline() string {
+	played := m.playedFor()
+	var points []int
+	for _, s := range m.curve.finish(played, m.wordsRead) {
+		if played-s.At < sparkWindow {
+			points = append(points, s.WPM)
+		}
+	}
+	if len(points) == 0 {
+		return ""
+	}
+	top := m.wpm
+	for _, p := range points {
+		top = max(top, p)
+	}
+	var b strings.Builder
+	for _, p := range points {
+		i := 0
+		if top > 0 {
+			i = p * (len(sparkBars) - 1) / top
+		}
+		b.WriteRune(sparkBars[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+	"sync"
+)
+
+// seekSource is input that can be read again from any byte offset.
+type seekSource interface {
+	readFrom(offset int64) io.Reader
+	// readArrived reads like readFrom, but fails with errNotArrived where
+	// it would have to wait for more input.
+	readArrived(offset int64) io.Reader
+	// size reports how many bytes the input holds, and whether that is
+	// final.
+	size() (int64, bool)
+}
+
+// fileSource re-reads a regular file.
+type fileSource struct {
+	file *os.File
+	n    int64
+}
+
+func (f fileSource) readFrom(offset int64) io.Reader {
+	return io.NewSectionReader(f.file, offset, math.MaxInt64-offset)
+}
+
+func (f fileSource) readArrived(offset int64) io.Reader {
+	return f.readFrom(offset)
+}
+
+func (f fileSource) size() (int64, bool) {
+	return f.n, true
+}
+
+// spool copies a one-shot reader such as a pipe into a temp file as it is
+// consumed, so that anything read so far can be read again.
+type spool struct {
+	src  io.Reader
+	file *os.File
+
+	// fill serialises reads from src, which may block, so that re-reading
+	// spooled data only ever waits on mu.
+	fill sync.Mutex
+	mu   sync.Mutex
+	n    int64
+	eof  bool
+	err  error
+}
+
+// newSpool spools into the cache directory, or the temp directory if that
+// cannot be had.
+func newSpool(src io.Reader) (*spool, error) {
+	dir, err := cacheDir()
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err != nil {
+		dir = ""
+	}
+	file, err := os.CreateTemp(dir, "zippy-spool-*")
+	if err != nil {
+		return nil, err
+	}
+	return &spool{src: src, file: file}, nil
+}
+
+// errNotArrived is where a spool read without waiting runs out of input
+// that has come in so far.
+var errNotArrived = errors.New("the input has not arrived yet")
+
+func (s *spool) readFrom(offset int64) io.Reader {
+	return &spoolReader{spool: s, offset: offset, wait: true}
+}
+
+func (s *spool) readArrived(offset int64) io.Reader {
+	return &spoolReader{spool: s, offset: offset}
+}
+
+func (s *spool) size() (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.n, s.eof
+}
+
+// readAt reads spooled bytes at offset. When offset has not been reached
+// yet, it pulls more from src first if wait is set, and otherwise fails
+// with errNotArrived.
+func (s *spool) readAt(p []byte, offset int64, wait bool) (int, error) {
+	for {
+		s.mu.Lock()
+		n, eof, err := s.n, s.eof, s.err
+		s.mu.Unlock()
+		if offset < n {
+			return s.file.ReadAt(p[:min(int64(len(p)), n-offset)], offset)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if eof {
+			return 0, io.EOF
+		}
+		if !wait {
+			return 0, errNotArrived
+		}
+		s.pull(n)
+	}
+}
+
+// pull reads the next chunk from src, unless another reader already did so
+// since the spool held seen bytes.
+func (s *spool) pull(seen int64) {
+	s.fill.Lock()
+	defer s.fill.Unlock()
+	s.mu.Lock()
+	n := s.n
+	s.mu.Unlock()
+	if n != seen {
+		return
+	}
+
+	buf := make([]byte, 32*1024)
+	read, err := s.src.Read(buf)
+	if read > 0 {
+		if _, werr := s.file.WriteAt(buf[:read], n); werr != nil {
+			err = werr
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.n += int64(read)
+	switch {
+	case err == io.EOF:
+		s.eof = true
+	case err != nil:
+		s.err = err
+	}
+}
+
+// Close removes the temp file.
+func (s *spool) Close() error {
+	err := s.file.Close()
+	if rerr := os.Remove(s.file.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+type spoolReader struct {
+	spool  *spool
+	offset int64
+	wait   bool
+}
+
+func (r *spoolReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n, err := r.spool.readAt(p, r.offset, r.wait)
+	r.offset += int64(n)
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type startKind int
+
+const (
+	startWord startKind = iota
+	startPercent
+	startSearch
+)
+
+// startPoint is a place to begin reading at: a word index, a percentage of
+// the document or the first match of a search.
+type startPoint struct {
+	kind    startKind
+	word    int
+	percent float64
+	query   string
+}
+
+// parseStartAt parses the -start-at flag: "1200" is the 1200th word, "35%"
+// is that far into the document and anything else is searched for.
+func parseStartAt(s string) (startPoint, error) {
+	s = strings.TrimSpace(s)
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil || f < 0 || f > 100 {
+			return startPoint{}, fmt.Errorf("-start-at %q is not a percentage between 0%% and 100%%", s)
+		}
+		return startPoint{kind: startPercent, percent: f}, nil
+	}
+	if n, err := strconv.Atoi(strings.ReplaceAll(s, ",", "")); err == nil {
+		if n <= 0 {
+			return startPoint{}, fmt.Errorf("-start-at word numbers start at 1")
+		}
+		return startPoint{kind: startWord, word: n - 1}, nil
+	}
+	if len(searchTerms(s)) == 0 {
+		return startPoint{}, fmt.Errorf("-start-at needs a word number, a percentage or some text to search for")
+	}
+	return startPoint{kind: startSearch, query: s}, nil
+}
+
+// startAt moves to p, or arranges to once a lazy stream has loaded.
+func (m *model) startAt(p startPoint) {
+	if _, loaded := m.stream.Current(); !loaded {
+		m.loadSeek, m.seekOnLoad = true, p
+		return
+	}
+	switch p.kind {
+	case startWord:
+		m.stream.Seek(p.word)
+	case startPercent:
+		m.seekPercent(p.percent)
+	case startSearch:
+		// Remember the query so that n finds the next occurrence.
+		m.lastSearch, m.searchBackward = p.query, false
+		offset, ok := m.searchFrom(p.query, 0, 1)
+		if !ok {
+			m.notice = "Not found: " + p.query
+			return
+		}
+		m.stream.Seek(m.stream.Pos() + offset)
+	}
+}
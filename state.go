@@ -0,0 +1,200 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// docState is everything remembered about a single document between runs.
+type docState struct {
+	Bookmarks []int `json:"bookmarks,omitempty"`
+	// Position is the word index reading stopped at, valid for the content
+	// with the given Hash.
+	Position int    `json:"position"`
+	Hash     string `json:"hash,omitempty"`
+	Total    int    `json:"total,omitempty"`
+	// Context is the words at Position, used to find the place again if the
+	// content changes.
+	Context  []string  `json:"context,omitempty"`
+	LastRead time.Time `json:"last_read,omitzero"`
+	// WordsRead and ReadTime add up every session, for the average speed.
+	WordsRead int           `json:"words_read,omitempty"`
+	ReadTime  time.Duration `json:"read_time,omitempty"`
+	// WPM, Chunk and Profile are the settings last read with, restored
+	// when the document is opened again.
+	WPM     int    `json:"wpm,omitempty"`
+	Chunk   int    `json:"chunk,omitempty"`
+	Profile string `json:"profile,omitempty"`
+	// Unknown are the words flagged as unknown while reading.
+	Unknown []unknownWord `json:"unknown_words,omitempty"`
+	// HighlightsSent are the hashes of the sentences already sent as
+	// highlights.
+	HighlightsSent []string `json:"highlights_sent_hashes,omitempty"`
+}
+
+// averageWPM is the speed the document has been read at across sessions,
+// or zero if it has not been played yet.
+func (d docState) averageWPM() int {
+	if d.ReadTime < time.Second {
+		return 0
+	}
+	return int(float64(d.WordsRead) / d.ReadTime.Minutes())
+}
+
+// docStore holds per-document state, keyed by absolute file path, in a JSON
+// file under the XDG data directory.
+type docStore struct {
+	path      string
+	Documents map[string]*docState `json:"documents"`
+}
+
+// dataDir returns zippy's directory under $XDG_DATA_HOME, falling back to
+// ~/.local/share as the spec asks.
+func dataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "zippy"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "zippy"), nil
+}
+
+// cacheDir returns zippy's directory under $XDG_CACHE_HOME, falling back to
+// ~/.cache, for files that can be deleted at any time.
+func cacheDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "zippy"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache", "zippy"), nil
+}
+
+// lockState takes the lock that keeps zippy instances from changing the
+// files in the data directory at the same time, waiting until it is free.
+// Stores are replaced by renaming, so reading one needs no lock; changing
+// one must hold it from loading to saving so that no update is lost.
+func lockState() (unlock func(), err error) {
+	dir, err := dataDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "lock"), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+func loadDocStore() (*docStore, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return nil, err
+	}
+	s := &docStore{path: filepath.Join(dir, "documents.json"), Documents: map[string]*docState{}}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Documents == nil {
+		s.Documents = map[string]*docState{}
+	}
+	return s, nil
+}
+
+// doc returns the state for a document, creating it if needed.
+func (s *docStore) doc(key string) *docState {
+	d, ok := s.Documents[key]
+	if !ok {
+		d = &docState{}
+		s.Documents[key] = d
+	}
+	return d
+}
+
+// save writes the store atomically so that a crash never leaves a truncated
+// file behind. The caller holds lockState.
+func (s *docStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// updateDoc loads the store, applies fn to the document's state and saves it
+// again.
+func updateDoc(key string, fn func(*docState)) error {
+	unlock, err := lockState()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	s, err := loadDocStore()
+	if err != nil {
+		return err
+	}
+	fn(s.doc(key))
+	return s.save()
+}
+
+// docKey identifies a document in the store. Only files can be remembered.
+func docKey(filePath string) string {
+	if filePath == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return filePath
+	}
+	return abs
+}
+
+// hashFile returns a hex SHA-256 of a file's content, used to tell whether
+// saved state still applies to it.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDocStoreRoundTrip(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	if err := updateDoc("/books/a.txt", func(d *docState) { d.Bookmarks = []int{3, 9} }); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	s, err := loadDocStore()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	got := s.doc("/books/a.txt").Bookmarks
+	if len(got) != 2 || got[0] != 3 || got[1] != 9 {
+		t.Fatalf("expected bookmarks [3 9], got %v", got)
+	}
+	if len(s.doc("/books/b.txt").Bookmarks) != 0 {
+		t.Fatal("expected no bookmarks for an unknown document")
+	}
+}
+
+func TestConcurrentUpdatesKeepEveryChange(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := updateDoc("/books/a.txt", func(d *docState) { d.Bookmarks = append(d.Bookmarks, i) })
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	s, err := loadDocStore()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := len(s.doc("/books/a.txt").Bookmarks); got != 20 {
+		t.Fatalf("expected all 20 bookmarks to survive, got %d", got)
+	}
+}
+
+func TestBookmarksToggleAndCycle(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	m := model{stream: newEagerStream(tokenize("a b c d e"), true), docKey: "/books/a.txt"}
+	m.stream.Seek(3)
+	m = press(m, "b")
+	m.stream.Seek(1)
+	m = press(m, "b")
+	m.stream.Seek(0)
+	m = press(m, "B")
+	if m.stream.Pos() != 1 {
+		t.Fatalf("expected first bookmark at 1, got %d", m.stream.Pos())
+	}
+	m = press(m, "B", "B")
+	if m.stream.Pos() != 1 {
+		t.Fatalf("expected cycling to wrap back to 1, got %d", m.stream.Pos())
+	}
+	m = press(m, "b")
+	s, err := loadDocStore()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := s.doc("/books/a.txt").Bookmarks; len(got) != 1 || got[0] != 3 {
+		t.Fatalf("expected persisted bookmarks [3], got %v", got)
+	}
+}
+
+func TestMarksOverlayJumpAndDelete(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	m := model{
+		stream:    newEagerStream(tokenize("a b c d e"), true),
+		docKey:    "/books/a.txt",
+		bookmarks: []int{1, 3},
+		width:     80,
+		height:    24,
+	}
+	m = press(m, "M")
+	if !m.showMarks {
+		t.Fatal("expected the bookmark overlay to open")
+	}
+	m = press(m, "j", "enter")
+	if m.showMarks || m.stream.Pos() != 3 {
+		t.Fatalf("expected to jump to 3 and close, got %d open=%v", m.stream.Pos(), m.showMarks)
+	}
+	m = press(m, "M", "d", "esc")
+	if len(m.bookmarks) != 1 || m.bookmarks[0] != 1 {
+		t.Fatalf("expected bookmarks [1] after delete, got %v", m.bookmarks)
+	}
+}
+
+func TestSavedPositionRequiresSameContent(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	s := newEagerStream(tokenize("a b c d"), true)
+	s.Seek(2)
+	if err := savePosition("/books/a.txt", "hash1", s, 300, time.Minute); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := savePosition("/books/a.txt", "hash1", s, 100, time.Minute); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if pos, total, ok := savedPosition("/books/a.txt", "hash1"); !ok || pos != 2 || total != 4 {
+		t.Fatalf("expected saved position 2 of 4, got %d/%d ok=%v", pos, total, ok)
+	}
+	if _, _, ok := savedPosition("/books/a.txt", "hash2"); ok {
+		t.Fatal("expected no position for changed content")
+	}
+	store, err := loadDocStore()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := store.doc("/books/a.txt").averageWPM(); got != 200 {
+		t.Fatalf("expected an average of 200 WPM over both sessions, got %d", got)
+	}
+}
+
+func TestResumePrompt(t *testing.T) {
+	m := model{
+		stream:      newEagerStream(tokenize("a b c d e f g h"), true),
+		askResume:   true,
+		resumePos:   5,
+		resumeTotal: 8,
+	}
+	if got := m.resumeQuestion(); got != "Resume at word 6 (75%)? [y/n]" {
+		t.Fatalf("unexpected question %q", got)
+	}
+	if got := formatCount(6000); got != "6,000" {
+		t.Fatalf("unexpected count %q", got)
+	}
+	m = press(m, "x")
+	if !m.askResume {
+		t.Fatal("expected other keys to leave the question open")
+	}
+	m = press(m, "y")
+	if m.askResume || m.stream.Pos() != 5 {
+		t.Fatalf("expected to resume at 5, got %d asking=%v", m.stream.Pos(), m.askResume)
+	}
+}
+
+func TestRematchAfterEdit(t *testing.T) {
+	words := tokenize("A new opening line. It was a dark and stormy night; the rain fell.")
+	context := []string{"dark", "and", "stormy", "night.", "The", "rain", "fell", "in"}
+	pos, ok := rematch(words, context, 3)
+	if !ok || words[pos].text != "dark" {
+		t.Fatalf("expected to find the place at dark, got %d ok=%v", pos, ok)
+	}
+	if _, ok := rematch(words, []string{"nothing", "like", "this", "here"}, 3); ok {
+		t.Fatal("expected no match for unrelated context")
+	}
+}
+
+func TestFindPlaceInFile(t *testing.T) {
+	path := writeTemp(t, "Filler words here. "+strings.Repeat("more filler ", 50)+"the place is near the end")
+	pos, ok := findPlace(path, []string{"place", "is", "near", "the", "end"}, 0)
+	if !ok || pos != 104 {
+		t.Fatalf("expected the place at 104, got %d ok=%v", pos, ok)
+	}
+	if _, ok := findPlace(path+".missing", []string{"place"}, 0); ok {
+		t.Fatal("expected no place in a missing file")
+	}
+}
+
+func TestStalePositionPrompt(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	s := newEagerStream(tokenize("a b c d e f"), true)
+	s.Seek(3)
+	if err := savePosition("/books/a.txt", "hash1", s, 0, 0); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, _, ok := stalePosition("/books/a.txt", "hash1"); ok {
+		t.Fatal("expected unchanged content not to be stale")
+	}
+	pos, context, ok := stalePosition("/books/a.txt", "hash2")
+	if !ok || pos != 3 || len(context) != 3 || context[0] != "d" {
+		t.Fatalf("expected stale position 3 with context [d e f], got %d %v ok=%v", pos, context, ok)
+	}
+
+	m := model{
+		stream:        newEagerStream(tokenize("x d e f"), true),
+		askResume:     true,
+		resumeStale:   true,
+		resumePos:     pos,
+		resumeContext: context,
+	}
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = updated.(model)
+	if m.askResume || cmd == nil {
+		t.Fatal("expected y to start looking for the place")
+	}
+	updated, _ = m.Update(placeMsg{pos: 1, found: true})
+	if m = updated.(model); m.stream.Pos() != 1 {
+		t.Fatalf("expected to resume at the found place, got %d", m.stream.Pos())
+	}
+}
+
+func TestReloadKeepsPlace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.txt")
+	if err := os.WriteFile(path, []byte("one two three four five six seven eight nine ten eleven"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reopen := fileReopener(false, false, false, path)
+	s, err := reopen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model{stream: s, docKey: path, reopen: reopen, markA: -1, markB: -1}
+	m.stream.Seek(3)
+	reload := func(m model) model {
+		next, cmd := m.Update(keyMsg("R"))
+		m = next.(model)
+		msgs := []tea.Msg{cmd()}
+		if batch, ok := msgs[0].(tea.BatchMsg); ok {
+			msgs = nil
+			for _, c := range batch {
+				msgs = append(msgs, c())
+			}
+		}
+		for _, msg := range msgs {
+			next, _ = m.Update(msg)
+			m = next.(model)
+		}
+		return m
+	}
+
+	if err := os.WriteFile(path, []byte("a new start. one two three four five six seven eight nine ten eleven"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if m = reload(m); m.stream.Pos() != 6 || m.notice != "Reloaded" {
+		t.Fatalf("expected to follow the words to 6, got %d (%q)", m.stream.Pos(), m.notice)
+	}
+
+	if err := os.WriteFile(path, []byte("completely different text that shares nothing at all with before"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if m = reload(m); m.stream.Pos() != 6 || !strings.Contains(m.notice, "staying at word 7") {
+		t.Fatalf("expected to stay at word 7, got %d (%q)", m.stream.Pos(), m.notice)
+	}
+}
+
+func TestReloadClosesOldStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.txt")
+	if err := os.WriteFile(path, []byte("one two three four"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reopen := fileReopener(true, false, false, path)
+	s, err := reopen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := s.(*lazyStream)
+	m := model{stream: s, docKey: path, reopen: reopen, markA: -1, markB: -1}
+	// A word the old stream asked for, arriving after the reload.
+	pending := tokenizeCmd(old.tokenizer, old.gen)
+
+	next, _ := m.Update(keyMsg("R"))
+	m = next.(model)
+	if old.inputCloser != nil {
+		t.Fatal("expected the old stream to be closed")
+	}
+	next, _ = m.Update(pending())
+	m = next.(model)
+	if ls := m.stream.(*lazyStream); ls.err != nil || ls.newest != -1 {
+		t.Fatalf("expected the new stream to drop the old one's word, got %d words (%v)", ls.newest+1, ls.err)
+	}
+}
+
+func TestReloadMovesBookmarksAndFlaggedWords(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "doc.txt")
+	text := "Alpha bravo charlie delta echo. Foxtrot golf hotel india juliet. Kilo lima mike november oscar papa."
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reopen := fileReopener(false, false, false, path)
+	s, err := reopen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model{stream: s, docKey: path, reopen: reopen, markA: -1, markB: -1, bookmarks: []int{5, 10}, unknown: []unknownWord{{Word: "lima", Position: 11}}}
+	if err := os.WriteFile(path, []byte("New words first. "+strings.Replace(text, "Foxtrot golf hotel india juliet. ", "", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	next, cmd := m.Update(keyMsg("R"))
+	next, _ = next.Update(cmd())
+	m = next.(model)
+	if !slices.Equal(m.bookmarks, []int{8}) || m.unknown[0].Position != 9 {
+		t.Fatalf("expected the bookmark and flagged word to follow the text, got %v %+v", m.bookmarks, m.unknown)
+	}
+	if !strings.Contains(m.notice, "1 bookmarks or flagged words were not found again") {
+		t.Fatalf("expected a note about the lost bookmark, got %q", m.notice)
+	}
+	store, err := loadDocStore()
+	if err != nil || !slices.Equal(store.doc(path).Bookmarks, []int{8}) {
+		t.Fatalf("expected the moved bookmarks to be saved, got %+v (%v)", store.doc(path), err)
+	}
+}
+
+func TestSettingsRememberedPerDocument(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	cfg := config{Profiles: map[string]profile{"novel": {WPM: 450, Theme: "night"}}}
+	themes := []themeChoice{{"default", builtinThemes["default"]}, {"night", builtinThemes["night"]}}
+	m := model{pacing: pacing{wpm: 380, chunk: 2}, profiles: profileChoices(cfg), profileName: "novel"}
+	if err := saveSettings("/docs/book.txt", m); err != nil {
+		t.Fatalf("saveSettings: %v", err)
+	}
+	store, err := loadDocStore()
+	if err != nil {
+		t.Fatalf("loadDocStore: %v", err)
+	}
+	saved := *store.doc("/docs/book.txt")
+
+	m = model{pacing: pacing{wpm: 500, chunk: 1}, themes: themes, profiles: profileChoices(cfg)}
+	m.restoreSettings(saved, nil)
+	if m.wpm != 380 || m.chunk != 2 || m.profileName != "novel" || m.theme != builtinThemes["night"] {
+		t.Fatalf("expected the last settings back, got %+v in profile %q", m.pacing, m.profileName)
+	}
+	if m.notice != "Profile novel at 380 WPM as last time" {
+		t.Fatalf("unexpected notice %q", m.notice)
+	}
+
+	m = model{pacing: pacing{wpm: 600, chunk: 1}, themes: themes, profiles: profileChoices(cfg)}
+	m.restoreSettings(saved, map[string]bool{"wpm": true, "theme": true})
+	if m.wpm != 600 || m.chunk != 2 || m.theme == builtinThemes["night"] {
+		t.Fatalf("expected -wpm and -theme to win, got %+v", m.pacing)
+	}
+	m = model{pacing: pacing{wpm: 600, chunk: 1}, profiles: profileChoices(cfg)}
+	if m.restoreSettings(saved, map[string]bool{"profile": true}); m.wpm != 600 || m.chunk != 1 {
+		t.Fatalf("expected -profile to skip the remembered settings, got %+v", m.pacing)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// session is one run of the reader, as kept in the statistics store.
+type session struct {
+	// Document is the file's key in the document store, or empty for piped
+	// input.
+	Document string        `json:"document,omitempty"`
+	Start    time.Time     `json:"start"`
+	Words    int           `json:"words"`
+	Duration time.Duration `json:"duration"`
+	// Curve is the speed read at over the session; see speedCurve.
+	Curve []speedSample `json:"curve,omitempty"`
+}
+
+// wpm is the average speed of the session, or zero for one too short to
+// tell.
+func (s session) wpm() int {
+	if s.Duration < minMeasured {
+		return 0
+	}
+	return int(float64(s.Words) / s.Duration.Minutes())
+}
+
+// speedSample is the speed read at during the step of playing time that
+// ended At into the session.
+type speedSample struct {
+	At  time.Duration `json:"at"`
+	WPM int           `json:"wpm"`
+}
+
+// curveStep is how much playing time each point of the speed curve covers.
+const curveStep = 15 * time.Second
+
+// speedCurve follows the speed read at while playing, a point per curveStep.
+// words and at are where the step being measured began.
+type speedCurve struct {
+	samples []speedSample
+	words   int
+	at      time.Duration
+}
+
+// record notes the words read after played time spent playing, closing
+// the step once it is long enough.
+func (c *speedCurve) record(played time.Duration, words int) {
+	if played-c.at < curveStep {
+		return
+	}
+	wpm := int(float64(words-c.words) / (played - c.at).Minutes())
+	c.samples = append(c.samples, speedSample{At: played, WPM: wpm})
+	c.words, c.at = words, played
+}
+
+// finish returns the curve with the step cut short by the end of the
+// session, if it ran long enough to measure.
+func (c speedCurve) finish(played time.Duration, words int) []speedSample {
+	if played-c.at >= minMeasured {
+		c.samples = append(c.samples, speedSample{At: played, WPM: int(float64(words-c.words) / (played - c.at).Minutes())})
+	}
+	return c.samples
+}
+
+// statsPath is the statistics store, a JSON object per line so that a
+// session is added without rewriting the ones before it.
+func statsPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions.jsonl"), nil
+}
+
+// recordSession adds a session to the statistics store. Sessions in which
+// nothing was read are not worth keeping.
+func recordSession(s session) error {
+	if s.Words == 0 {
+		return nil
+	}
+	path, err := statsPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockState()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadSessions reads every session in the statistics store, oldest first.
+// A line cut short by a crash is skipped rather than losing the rest.
+func loadSessions() ([]session, error) {
+	path, err := statsPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var sessions []session
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var s session
+		if json.Unmarshal(sc.Bytes(), &s) == nil {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, sc.Err()
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpeedCurve(t *testing.T) {
+	var c speedCurve
+	c.record(10*time.Second, 50)
+	if len(c.samples) != 0 {
+		t.Fatal("expected no point before a whole step")
+	}
+	c.record(15*time.Second, 75)
+	c.record(30*time.Second, 150)
+	got := c.finish(36*time.Second, 150)
+	want := []speedSample{{15 * time.Second, 300}, {30 * time.Second, 300}, {36 * time.Second, 0}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+	if got := c.finish(32*time.Second, 160); len(got) != 2 {
+		t.Fatalf("expected a step too short to measure to be dropped, got %v", got)
+	}
+}
+
+func TestSessionStore(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	if err := recordSession(session{Document: "/books/a.txt", Start: start, Words: 600, Duration: 2 * time.Minute}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := recordSession(session{Start: start.Add(time.Hour)}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := recordSession(session{Start: start.Add(2 * time.Hour), Words: 90, Duration: time.Minute}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	// A line cut short by a crash does not cost the others.
+	path, _ := statsPath()
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"document":"/books/b.tx`)
+	f.Close()
+
+	sessions, err := loadSessions()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected the two sessions with words read, got %+v", sessions)
+	}
+	if s := sessions[0]; s.Document != "/books/a.txt" || !s.Start.Equal(start) || s.wpm() != 300 {
+		t.Fatalf("unexpected first session %+v", s)
+	}
+	if sessions[1].Document != "" || sessions[1].wpm() != 90 {
+		t.Fatalf("unexpected piped session %+v", sessions[1])
+	}
+}
+
+func TestStatsReport(t *testing.T) {
+	now := time.Date(2024, 5, 10, 18, 0, 0, 0, time.Local)
+	day := func(d, hour int) time.Time { return time.Date(2024, 5, d, hour, 0, 0, 0, time.Local) }
+	sessions := []session{
+		{Document: "/books/old.txt", Start: day(1, 9), Words: 9000, Duration: 30 * time.Minute},
+		{Document: "/books/a.txt", Start: day(8, 9), Words: 3000, Duration: 10 * time.Minute},
+		{Document: "/books/b.txt", Start: day(8, 21), Words: 1000, Duration: 5 * time.Minute},
+		{Document: "/books/a.txt", Start: day(10, 8), Words: 3000, Duration: 10 * time.Minute},
+	}
+	r := buildStatsReport(sessions, now.Add(-7*24*time.Hour), now)
+	if r.Sessions != 3 || r.Words != 7000 || r.Time != 25*time.Minute || r.WPM != 280 || r.WordsPerDay != 1000 {
+		t.Fatalf("unexpected totals %+v", r)
+	}
+	if len(r.Days) != 2 || r.Days[0] != (dayStats{"2024-05-08", 4000, 15 * time.Minute, 266, false}) {
+		t.Fatalf("unexpected days %+v", r.Days)
+	}
+	if len(r.Documents) != 2 || r.Documents[0] != (docStats{"/books/a.txt", 2, 6000, 20 * time.Minute, 300}) {
+		t.Fatalf("unexpected documents %+v", r.Documents)
+	}
+
+	var b strings.Builder
+	r.write(&b)
+	for _, want := range []string{"Words per day:  1,000", "2024-05-10  3,000  10m0s  300", "/books/b.txt  1         1,000  5m0s   200"} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("expected %q in the report:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	for in, want := range map[string]time.Duration{"7d": 7 * 24 * time.Hour, "2w": 14 * 24 * time.Hour, "12h": 12 * time.Hour} {
+		if got, err := parseSince(in); err != nil || got != want {
+			t.Errorf("parseSince(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "d", "-3d", "soon"} {
+		if _, err := parseSince(in); err == nil {
+			t.Errorf("parseSince(%q): expected an error", in)
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	m := model{
+		stream:    newEagerStream(tokenize("a b c d"), false),
+		width:     200,
+		height:    3,
+		pacing:    pacing{wpm: 300},
+		elapsed:   5 * time.Minute,
+		wordsRead: 1500,
+		curve: speedCurve{
+			samples: []speedSample{{45 * time.Second, 600}, {2 * time.Minute, 300}, {3 * time.Minute, 150}, {4 * time.Minute, 600}},
+			words:   1200,
+			at:      4 * time.Minute,
+		},
+	}
+	// The first point is older than the window; the last is the minute
+	// since the curve's last step.
+	if got := m.sparkline(); got != "▄▂█▄" {
+		t.Fatalf("expected ▄▂█▄, got %q", got)
+	}
+	if strings.Contains(m.View(), "▄▂█▄") {
+		t.Fatal("expected the sparkline to be off by default")
+	}
+	if m = press(m, "W"); !strings.Contains(m.View(), "at 300 WPM ▄▂█▄") {
+		t.Fatalf("expected the sparkline after the speed read at:\n%s", m.View())
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	on := func(days ...int) []session {
+		var sessions []session
+		for _, d := range days {
+			sessions = append(sessions, session{Start: time.Date(2024, 5, d, 20, 0, 0, 0, time.Local), Words: 100})
+		}
+		return sessions
+	}
+	now := time.Date(2024, 5, 10, 9, 0, 0, 0, time.Local)
+	tests := []struct {
+		sessions         []session
+		current, longest int
+	}{
+		{nil, 0, 0},
+		{on(1, 2, 3, 4, 7, 8, 9, 10), 4, 4},
+		{on(1, 2, 3, 4, 5, 8, 9), 2, 5},
+		// Nothing read yet today keeps yesterday's streak alive.
+		{on(8, 9, 9), 2, 2},
+		{on(3, 4, 8), 0, 2},
+	}
+	for _, tt := range tests {
+		if current, longest := streaks(tt.sessions, now); current != tt.current || longest != tt.longest {
+			t.Errorf("streaks(%d sessions) = %d, %d; want %d, %d", len(tt.sessions), current, longest, tt.current, tt.longest)
+		}
+	}
+}
+
+func TestExportSessions(t *testing.T) {
+	sessions := []session{
+		{Document: "/books/a, b.txt", Start: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), Words: 600, Duration: 2 * time.Minute,
+			Curve: []speedSample{{15 * time.Second, 320}, {30 * time.Second, 280}}},
+		{Start: time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC), Words: 10, Duration: 3 * time.Second},
+	}
+	var b strings.Builder
+	if err := writeSessionsCSV(&b, sessions); err != nil {
+		t.Fatal(err)
+	}
+	want := "document,start,words,seconds,wpm,curve\n" +
+		"\"/books/a, b.txt\",2024-05-01T09:00:00Z,600,120.0,300,15:320 30:280\n" +
+		",2024-05-02T09:00:00Z,10,3.0,0,\n"
+	if b.String() != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, b.String())
+	}
+
+	b.Reset()
+	if err := writeSessionsJSON(&b, nil); err != nil || b.String() != "[]\n" {
+		t.Fatalf("expected an empty array with no sessions, got %q, %v", b.String(), err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runStatsExport implements `zippy stats export`, which dumps the sessions
+// in the statistics store for spreadsheets and other tools.
+func runStatsExport(args []string) int {
+	fs := flag.NewFlagSet("stats export", flag.ExitOnError)
+	var format string
+	fs.StringVar(&format, "format", "csv", "output format: csv or json")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s stats export [options]\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Print every recorded reading session.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if format != "csv" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Unknown format %q; use csv or json.\n", format)
+		return 2
+	}
+	sessions, err := loadSessions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read statistics:", err)
+		return 1
+	}
+	if format == "json" {
+		err = writeSessionsJSON(os.Stdout, sessions)
+	} else {
+		err = writeSessionsCSV(os.Stdout, sessions)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// writeSessionsJSON prints the sessions as a JSON array, just as they are
+// stored.
+func writeSessionsJSON(w io.Writer, sessions []session) error {
+	if sessions == nil {
+		sessions = []session{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sessions)
+}
+
+// writeSessionsCSV prints a row per session, with durations in seconds. The
+// speed curve goes in one column as seconds:wpm pairs separated by spaces.
+func writeSessionsCSV(w io.Writer, sessions []session) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"document", "start", "words", "seconds", "wpm", "curve"})
+	for _, s := range sessions {
+		var curve []string
+		for _, p := range s.Curve {
+			curve = append(curve, fmt.Sprintf("%d:%d", int(p.At.Seconds()), p.WPM))
+		}
+		cw.Write([]string{
+			s.Document,
+			s.Start.Format(time.RFC3339),
+			strconv.Itoa(s.Words),
+			strconv.FormatFloat(s.Duration.Seconds(), 'f', 1, 64),
+			strconv.Itoa(s.wpm()),
+			strings.Join(curve, " "),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
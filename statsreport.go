@@ -0,0 +1,283 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// topDocuments is how many documents the report ranks.
+const topDocuments = 5
+
+// statsReport sums up the sessions in the statistics store.
+type statsReport struct {
+	Since    time.Time     `json:"since,omitzero"`
+	Sessions int           `json:"sessions"`
+	Words    int           `json:"words"`
+	Time     time.Duration `json:"time"`
+	WPM      int           `json:"wpm"`
+	// WordsPerDay averages over every day of the period, read or not.
+	WordsPerDay int        `json:"words_per_day"`
+	Days        []dayStats `json:"days"`
+	Documents   []docStats `json:"top_documents"`
+	// Streak and LongestStreak count days in a row with some reading,
+	// over every session rather than just the report's.
+	Streak        int `json:"streak"`
+	LongestStreak int `json:"longest_streak"`
+	// Goal is the progress towards the daily goal, if there is one.
+	Goal *goalStats `json:"goal,omitempty"`
+}
+
+// goalStats is how today is going against the daily goal, and on how many
+// of the days in the report it was met.
+type goalStats struct {
+	goal
+	TodayWords int           `json:"today_words"`
+	TodayTime  time.Duration `json:"today_time"`
+	Met        bool          `json:"met"`
+	DaysMet    int           `json:"days_met"`
+}
+
+// dayStats is what was read on one day.
+type dayStats struct {
+	Date  string        `json:"date"`
+	Words int           `json:"words"`
+	Time  time.Duration `json:"time"`
+	WPM   int           `json:"wpm"`
+	// GoalMet is set on days the daily goal was met.
+	GoalMet bool `json:"goal_met,omitempty"`
+}
+
+// docStats is what was read of one document; Document is empty for piped
+// input.
+type docStats struct {
+	Document string        `json:"document"`
+	Sessions int           `json:"sessions"`
+	Words    int           `json:"words"`
+	Time     time.Duration `json:"time"`
+	WPM      int           `json:"wpm"`
+}
+
+// speedOf is the speed words were read at in d, or zero if d is too short
+// to tell.
+func speedOf(words int, d time.Duration) int {
+	return session{Words: words, Duration: d}.wpm()
+}
+
+// buildStatsReport sums up the sessions started since since, or all of them
+// if it is zero, as of now.
+func buildStatsReport(sessions []session, since, now time.Time) statsReport {
+	r := statsReport{Since: since, Days: []dayStats{}, Documents: []docStats{}}
+	days := map[string]*dayStats{}
+	docs := map[string]*docStats{}
+	first := since
+	for _, s := range sessions {
+		if s.Start.Before(since) || s.Start.After(now) {
+			continue
+		}
+		if first.IsZero() || s.Start.Before(first) {
+			first = s.Start
+		}
+		r.Sessions++
+		r.Words += s.Words
+		r.Time += s.Duration
+
+		date := s.Start.Local().Format(time.DateOnly)
+		day, ok := days[date]
+		if !ok {
+			day = &dayStats{Date: date}
+			days[date] = day
+		}
+		day.Words += s.Words
+		day.Time += s.Duration
+
+		doc, ok := docs[s.Document]
+		if !ok {
+			doc = &docStats{Document: s.Document}
+			docs[s.Document] = doc
+		}
+		doc.Sessions++
+		doc.Words += s.Words
+		doc.Time += s.Duration
+	}
+	r.WPM = speedOf(r.Words, r.Time)
+	if r.Sessions > 0 {
+		period := (now.Sub(first) + 24*time.Hour - 1) / (24 * time.Hour)
+		r.WordsPerDay = r.Words / max(int(period), 1)
+	}
+	for _, day := range days {
+		day.WPM = speedOf(day.Words, day.Time)
+		r.Days = append(r.Days, *day)
+	}
+	slices.SortFunc(r.Days, func(a, b dayStats) int { return strings.Compare(a.Date, b.Date) })
+	for _, doc := range docs {
+		doc.WPM = speedOf(doc.Words, doc.Time)
+		r.Documents = append(r.Documents, *doc)
+	}
+	slices.SortFunc(r.Documents, func(a, b docStats) int {
+		return cmp.Or(cmp.Compare(b.Words, a.Words), strings.Compare(a.Document, b.Document))
+	})
+	if len(r.Documents) > topDocuments {
+		r.Documents = r.Documents[:topDocuments]
+	}
+	return r
+}
+
+// addGoal adds the daily goal's progress to the report.
+func (r *statsReport) addGoal(g goal, sessions []session, now time.Time) {
+	if !g.set() {
+		return
+	}
+	words, d := readOn(sessions, now)
+	r.Goal = &goalStats{goal: g, TodayWords: words, TodayTime: d, Met: g.met(words, d)}
+	for i, day := range r.Days {
+		if g.met(day.Words, day.Time) {
+			r.Days[i].GoalMet = true
+			r.Goal.DaysMet++
+		}
+	}
+}
+
+// parseSince reads the period for --since: a duration such as 12h, or a
+// number of days or weeks such as 7d or 2w.
+func parseSince(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			days, err := strconv.Atoi(n)
+			if err != nil || days <= 0 {
+				break
+			}
+			return time.Duration(days) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("-since wants a period such as 7d, 2w or 12h, not %q", s)
+	}
+	return d, nil
+}
+
+// write prints the report as a summary followed by tables of the days and
+// the documents read most.
+func (r statsReport) write(w io.Writer) {
+	if r.Sessions == 0 {
+		if r.Since.IsZero() {
+			fmt.Fprintln(w, "Nothing read yet.")
+		} else {
+			fmt.Fprintln(w, "Nothing read in that time.")
+		}
+		return
+	}
+	fmt.Fprintf(w, "Sessions:       %d\n", r.Sessions)
+	fmt.Fprintf(w, "Words read:     %s\n", formatCount(r.Words))
+	fmt.Fprintf(w, "Time spent:     %s\n", r.Time.Round(time.Second))
+	fmt.Fprintf(w, "Average speed:  %d WPM\n", r.WPM)
+	fmt.Fprintf(w, "Words per day:  %s\n", formatCount(r.WordsPerDay))
+	fmt.Fprintf(w, "Streak:         %s (longest %s)\n", dayCount(r.Streak), dayCount(r.LongestStreak))
+	if g := r.Goal; g != nil {
+		today := g.describe(g.TodayWords, g.TodayTime)
+		if g.Met {
+			today += ", reached"
+		}
+		fmt.Fprintf(w, "Today's goal:   %s\n", today)
+		fmt.Fprintf(w, "Goal met on:    %d of %d days\n", g.DaysMet, len(r.Days))
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := "DATE\tWORDS\tTIME\tWPM\t"
+	if r.Goal != nil {
+		header += "GOAL\t"
+	}
+	fmt.Fprintln(tw, header)
+	for _, d := range r.Days {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t", d.Date, formatCount(d.Words), d.Time.Round(time.Second), d.WPM)
+		if r.Goal != nil {
+			met := "-"
+			if d.GoalMet {
+				met = "met"
+			}
+			fmt.Fprintf(tw, "%s\t", met)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DOCUMENT\tSESSIONS\tWORDS\tTIME\tWPM")
+	for _, d := range r.Documents {
+		name := d.Document
+		if name == "" {
+			name = "(piped input)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%d\n", name, d.Sessions, formatCount(d.Words), d.Time.Round(time.Second), d.WPM)
+	}
+	tw.Flush()
+}
+
+// runStats implements `zippy stats`, which reports on the sessions in the
+// statistics store, and `zippy stats export`.
+func runStats(args []string) int {
+	if len(args) > 0 && args[0] == "export" {
+		return runStatsExport(args[1:])
+	}
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	var (
+		since  string
+		asJSON bool
+	)
+	fs.StringVar(&since, "since", "", "only count sessions from this far back, e.g. 7d, 2w or 12h")
+	fs.BoolVar(&asJSON, "json", false, "print the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s stats [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s stats export [-format csv|json]\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Report how much and how fast you have been reading, or export every session.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	now := time.Now()
+	var from time.Time
+	if since != "" {
+		d, err := parseSince(since)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		from = now.Add(-d)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read config:", err)
+		return 1
+	}
+	sessions, err := loadSessions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read statistics:", err)
+		return 1
+	}
+	r := buildStatsReport(sessions, from, now)
+	r.addGoal(cfg.Goal, sessions, now)
+	r.Streak, r.LongestStreak = streaks(sessions, now)
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	r.write(os.Stdout)
+	return 0
+}
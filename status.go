@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// liveStatus is what a running zippy tells `zippy status` about itself.
+type liveStatus struct {
+	PID      int       `json:"pid"`
+	Document string    `json:"document,omitempty"`
+	Position int       `json:"position"`
+	Total    int       `json:"total,omitempty"`
+	Percent  int       `json:"percent"`
+	WPM      int       `json:"wpm"`
+	Playing  bool      `json:"playing"`
+	Updated  time.Time `json:"updated"`
+}
+
+func (m model) liveStatus() liveStatus {
+	s := liveStatus{PID: os.Getpid(), Document: m.docKey, WPM: m.wpm, Playing: m.running}
+	if m.stream == nil {
+		return s
+	}
+	s.Position = m.stream.Pos() + 1
+	if known, total := m.stream.Total(); known && total > 0 {
+		s.Total = total
+		s.Percent = min(s.Position*100/total, 100)
+	}
+	return s
+}
+
+// statusFile keeps a running zippy's status in the cache directory, one
+// file per process. It is rewritten when something in the default status
+// line changes, and at most once a second for the position alone.
+type statusFile struct {
+	path    string
+	last    liveStatus
+	written time.Time
+}
+
+func newStatusFile() (*statusFile, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &statusFile{path: filepath.Join(dir, fmt.Sprintf("status-%d.json", os.Getpid()))}, nil
+}
+
+// write saves s unless it is too like the last status written. Errors are
+// ignored: a stale status line is not worth interrupting reading for.
+func (f *statusFile) write(s liveStatus, now time.Time) {
+	moved := s
+	moved.Position = f.last.Position
+	if s == f.last || (moved == f.last && now.Sub(f.written) < time.Second) {
+		return
+	}
+	f.last, f.written = s, now
+	s.Updated = now
+	data, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	tmp := f.path + ".tmp"
+	if os.WriteFile(tmp, data, 0o644) == nil {
+		os.Rename(tmp, f.path)
+	}
+}
+
+func (f *statusFile) remove() {
+	os.Remove(f.path)
+}
+
+// currentStatus returns the status of the running zippy that changed last,
+// removing the files of any that are gone.
+func currentStatus() (liveStatus, bool) {
+	dir, err := cacheDir()
+	if err != nil {
+		return liveStatus{}, false
+	}
+	paths, _ := filepath.Glob(filepath.Join(dir, "status-*.json"))
+	var latest liveStatus
+	found := false
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var s liveStatus
+		if json.Unmarshal(data, &s) != nil || !processAlive(s.PID) {
+			os.Remove(path)
+			continue
+		}
+		if !found || s.Updated.After(latest.Updated) {
+			latest, found = s, true
+		}
+	}
+	return latest, found
+}
+
+// format fills in a status line template.
+func (s liveStatus) format(template string) string {
+	name, state, icon := "stdin", "paused", "⏸"
+	if s.Document != "" {
+		name = filepath.Base(s.Document)
+	}
+	if s.Playing {
+		state, icon = "playing", "▶"
+	}
+	percent, total := "?", "?"
+	if s.Total > 0 {
+		percent, total = strconv.Itoa(s.Percent), strconv.Itoa(s.Total)
+	}
+	return strings.NewReplacer(
+		"{document}", name,
+		"{path}", s.Document,
+		"{percent}", percent,
+		"{position}", strconv.Itoa(s.Position),
+		"{total}", total,
+		"{wpm}", strconv.Itoa(s.WPM),
+		"{state}", state,
+		"{icon}", icon,
+	).Replace(template)
+}
+
+// defaultStatusFormat is the line `zippy status` prints without -format.
+const defaultStatusFormat = "{icon} {document} {percent}% {wpm} WPM"
+
+// runStatus implements `zippy status`, printing a line about the document
+// being read for tmux or shell prompt status lines.
+func runStatus(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	var (
+		format string
+		asJSON bool
+	)
+	fs.StringVar(&format, "format", defaultStatusFormat, "the line to print, with {document}, {path}, {percent}, {position}, {total}, {wpm}, {state} (playing or paused) and {icon} filled in")
+	fs.BoolVar(&asJSON, "json", false, "print the status as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s status [options]\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Print a line about what a running zippy is reading, for status lines such as")
+		fmt.Fprintln(os.Stderr, "tmux's. Prints nothing and exits with 1 when zippy is not running.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	s, ok := currentStatus()
+	if !ok {
+		return 1
+	}
+	if asJSON {
+		data, err := json.Marshal(s)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println(string(data))
+		return 0
+	}
+	fmt.Println(s.format(format))
+	return 0
+}
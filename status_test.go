@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatus(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	if _, ok := currentStatus(); ok {
+		t.Fatal("expected no status with zippy not running")
+	}
+	gone := filepath.Join(dir, "zippy", "status-1.json")
+	os.MkdirAll(filepath.Dir(gone), 0o755)
+	os.WriteFile(gone, []byte(`{"pid": 1073741823, "position": 5}`), 0o644)
+
+	status, err := newStatusFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model{pacing: pacing{wpm: 350, chunk: 1}, stream: newEagerStream(tokenize(strings.Repeat("word ", 200)), true), docKey: "/books/dune.txt", status: status}
+	m = press(m, "l", "l")
+	s, ok := currentStatus()
+	if !ok {
+		t.Fatal("expected the status of this process")
+	}
+	if s.Position != 2 {
+		t.Fatalf("expected moving alone not to rewrite the status straight away, got position %d", s.Position)
+	}
+	m = press(m, "+")
+	if s, _ = currentStatus(); s.Position != 3 || s.Percent != 1 || s.WPM != 375 {
+		t.Fatalf("unexpected status %+v", s)
+	}
+	if _, err := os.Stat(gone); err == nil {
+		t.Fatal("expected the status of a process that is gone to be removed")
+	}
+
+	if got, want := s.format(defaultStatusFormat), "⏸ dune.txt 1% 375 WPM"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	s.Playing, s.Total = true, 0
+	if got, want := s.format("{state} {position}/{total}"), "playing 3/?"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	status.write(m.liveStatus(), time.Now())
+	status.remove()
+	if _, ok := currentStatus(); ok {
+		t.Fatal("expected no status once reading is over")
+	}
+}
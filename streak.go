@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// streaks counts the days in a row with at least one session, in local
+// time. current runs up to today, or up to yesterday if nothing has been
+// read yet today, as the streak can still be kept; longest is the best
+// there has been.
+func streaks(sessions []session, now time.Time) (current, longest int) {
+	read := map[string]bool{}
+	for _, s := range sessions {
+		read[s.Start.Local().Format(time.DateOnly)] = true
+	}
+	day := func(t time.Time) string { return t.Format(time.DateOnly) }
+
+	d := now.Local()
+	if !read[day(d)] {
+		d = d.AddDate(0, 0, -1)
+	}
+	for read[day(d)] {
+		current++
+		d = d.AddDate(0, 0, -1)
+	}
+
+	for date := range read {
+		t, err := time.ParseInLocation(time.DateOnly, date, time.Local)
+		if err != nil || read[day(t.AddDate(0, 0, -1))] {
+			continue
+		}
+		// t starts a run.
+		n := 0
+		for read[day(t)] {
+			n++
+			t = t.AddDate(0, 0, 1)
+		}
+		longest = max(longest, n)
+	}
+	return current, longest
+}
+
+// dayCount says how many days n is, e.g. "1 day" or "4 days".
+func dayCount(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"slices"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -9,9 +14,11 @@ import (
 type stream interface {
 	Init() tea.Cmd
 	Handle(tea.Msg) tea.Cmd
-	Current() (string, bool)
+	Current() (token, bool)
+	Peek(offset int) (token, bool)
 	Next() tea.Cmd
 	Prev()
+	Seek(idx int)
 	Restart() tea.Cmd
 	SupportsSeek() bool
 	SupportsRestart() bool
@@ -21,8 +28,18 @@ type stream interface {
 	Total() (bool, int)
 }
 
+// seeksAnywhere reports whether s can return to any word read so far. Lazy
+// streams forget what is outside their history window unless the input can
+// be re-read.
+func seeksAnywhere(s stream) bool {
+	if l, ok := s.(*lazyStream); ok {
+		return l.src != nil
+	}
+	return s.SupportsSeek()
+}
+
 type eagerStream struct {
-	words           []string
+	words           []token
 	idx             int
 	supportsRestart bool
 }
@@ -36,8 +53,8 @@ func (e streamInitError) Error() string {
 	return e.msg
 }
 
-func buildStream(lazy bool, filePath string) (stream, error) {
-	if lazy {
+func buildStream(lazy, spool, follow bool, filePath string) (stream, error) {
+	if lazy || follow {
 		reader, err := openInput(filePath)
 		if err != nil {
 			return nil, streamInitError{
@@ -45,6 +62,18 @@ func buildStream(lazy bool, filePath string) (stream, error) {
 				showUsage: true,
 			}
 		}
+		if spool && filePath == "" {
+			s, err := newSpooledStream(reader)
+			if err != nil {
+				return nil, streamInitError{msg: fmt.Sprintf("Could not spool stdin: %v", err)}
+			}
+			return s, nil
+		}
+		if follow {
+			s := newLazyStream(followFile(reader), filePath)
+			s.follow = true
+			return s, nil
+		}
 		return newLazyStream(reader, filePath), nil
 	}
 
@@ -62,10 +91,11 @@ func buildStream(lazy bool, filePath string) (stream, error) {
 			showUsage: false,
 		}
 	}
-	return newEagerStream(words, filePath != ""), nil
+	// Buffered input is all in memory, so even stdin can be replayed.
+	return newEagerStream(words, true), nil
 }
 
-func newEagerStream(words []string, supportsRestart bool) *eagerStream {
+func newEagerStream(words []token, supportsRestart bool) *eagerStream {
 	return &eagerStream{words: words, supportsRestart: supportsRestart}
 }
 
@@ -77,13 +107,21 @@ func (s *eagerStream) Handle(tea.Msg) tea.Cmd {
 	return nil
 }
 
-func (s *eagerStream) Current() (string, bool) {
+func (s *eagerStream) Current() (token, bool) {
 	if len(s.words) == 0 || s.idx < 0 || s.idx >= len(s.words) {
-		return "", false
+		return token{}, false
 	}
 	return s.words[s.idx], true
 }
 
+func (s *eagerStream) Peek(offset int) (token, bool) {
+	i := s.idx + offset
+	if i < 0 || i >= len(s.words) {
+		return token{}, false
+	}
+	return s.words[i], true
+}
+
 func (s *eagerStream) Next() tea.Cmd {
 	if s.idx < len(s.words)-1 {
 		s.idx++
@@ -97,6 +135,10 @@ func (s *eagerStream) Prev() {
 	}
 }
 
+func (s *eagerStream) Seek(idx int) {
+	s.idx = min(max(idx, 0), max(len(s.words)-1, 0))
+}
+
 func (s *eagerStream) Restart() tea.Cmd {
 	if s.supportsRestart {
 		s.idx = 0
@@ -131,28 +173,119 @@ func (s *eagerStream) Total() (bool, int) {
 	return true, len(s.words)
 }
 
+// lazyHistory is how many of the most recently read words a lazy stream keeps
+// around for stepping back.
+const lazyHistory = 500
+
+// lazyStream reads words on demand. It only remembers the last lazyHistory
+// words, in a ring buffer. When the input is a regular file it also records
+// where every word starts, so that it can seek anywhere by re-reading the
+// file from that offset.
 type lazyStream struct {
-	tokenizer       *tokenizer
-	inputCloser     io.Closer
-	filePath        string
-	done            bool
-	err             error
-	waitingToken    bool
-	hasCurrent      bool
-	currentWord     string
-	idx             int
-	total           int
+	tokenizer    *tokenizer
+	inputCloser  io.Closer
+	filePath     string
+	done         bool
+	err          error
+	waitingToken bool
+	gen          int
+	history      []token
+	// newest is the index of the last word read and idx the one displayed;
+	// both are -1 until the first word arrives.
+	newest int
+	idx    int
+	total  int
+	// totalKnown is set once the end of the input has been reached, and
+	// stays set when seeking back.
+	totalKnown      bool
 	supportsRestart bool
+	// follow keeps waiting for more text at the end of the file, which is
+	// then never reached.
+	follow bool
+
+	// src is non-nil when the input can be re-read from any offset. marks
+	// then holds where every word read so far starts, and chapters the
+	// titles the marks refer to.
+	src      seekSource
+	marks    []wordMark
+	chapters []string
+	// short is set when a seek stopped at the end of the input that has
+	// arrived so far; see seekedShort.
+	short bool
+}
+
+// wordMark is where a word starts in a seekable input, with what the
+// tokenizer needs to pick up reading there. One is kept for every word read,
+// so it is kept small: the chapter is an index into lazyStream.chapters.
+type wordMark struct {
+	offset                               int64
+	sentenceWord                         int32
+	chapter                              int32
+	paragraphStart, heading, headingLine bool
+}
+
+// mark records tok in marks.
+func (s *lazyStream) mark(tok token) {
+	if len(s.chapters) == 0 || s.chapters[len(s.chapters)-1] != tok.chapter {
+		s.chapters = append(s.chapters, tok.chapter)
+	}
+	s.marks = append(s.marks, wordMark{
+		offset:         tok.offset,
+		sentenceWord:   int32(tok.sentenceWord),
+		chapter:        int32(len(s.chapters) - 1),
+		paragraphStart: tok.paragraphStart,
+		heading:        tok.heading,
+		headingLine:    tok.headingLine,
+	})
+}
+
+// markToken is the word at mark i, without its text.
+func (s *lazyStream) markToken(i int) token {
+	m := s.marks[i]
+	return token{
+		offset:         m.offset,
+		sentenceWord:   int(m.sentenceWord),
+		chapter:        s.chapters[m.chapter],
+		paragraphStart: m.paragraphStart,
+		heading:        m.heading,
+		headingLine:    m.headingLine,
+	}
 }
 
 func newLazyStream(reader io.ReadCloser, filePath string) *lazyStream {
-	return &lazyStream{
+	s := &lazyStream{
 		tokenizer:       newTokenizer(reader),
 		inputCloser:     reader,
 		filePath:        filePath,
+		history:         make([]token, lazyHistory),
+		newest:          -1,
 		idx:             -1,
 		supportsRestart: filePath != "",
 	}
+	if f, ok := reader.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			s.src = fileSource{file: f, n: info.Size()}
+		}
+	}
+	return s
+}
+
+// newSpooledStream reads a one-shot input such as a pipe through a spool,
+// which makes it restartable and seekable like a file.
+func newSpooledStream(reader io.ReadCloser) (*lazyStream, error) {
+	sp, err := newSpool(reader)
+	if err != nil {
+		return nil, err
+	}
+	return &lazyStream{
+		tokenizer:       newTokenizer(sp.readFrom(0)),
+		inputCloser:     sp,
+		history:         make([]token, lazyHistory),
+		newest:          -1,
+		idx:             -1,
+		supportsRestart: true,
+		src:             sp,
+	}, nil
 }
 
 func (s *lazyStream) Init() tea.Cmd {
@@ -164,7 +297,7 @@ func (s *lazyStream) Init() tea.Cmd {
 
 func (s *lazyStream) Handle(msg tea.Msg) tea.Cmd {
 	tm, ok := msg.(tokenMsg)
-	if !ok {
+	if !ok || tm.gen != s.gen || tm.from != s.tokenizer {
 		return nil
 	}
 	s.waitingToken = false
@@ -174,33 +307,60 @@ func (s *lazyStream) Handle(msg tea.Msg) tea.Cmd {
 		s.closeInput()
 		return nil
 	}
-	if tm.word == "" && tm.done {
-		s.done = true
-		s.total = s.idx + 1
-		s.closeInput()
-		return nil
-	}
-	if tm.word != "" {
-		s.idx++
-		s.hasCurrent = true
-		s.currentWord = tm.word
+	if tm.tok.text != "" {
+		// The word was asked for by Next at the newest word; if the reader
+		// has stepped back since, it stays where it is.
+		atNewest := s.idx == s.newest
+		s.push(tm.tok)
+		if atNewest {
+			s.idx = s.newest
+		}
 	}
 	if tm.done {
-		s.done = true
-		s.total = s.idx + 1
-		s.closeInput()
+		s.finish()
 	}
 	return nil
 }
 
-func (s *lazyStream) Current() (string, bool) {
-	if !s.hasCurrent {
-		return "", false
+// push appends a freshly read word to the history window.
+func (s *lazyStream) push(tok token) {
+	s.newest++
+	s.history[s.newest%len(s.history)] = tok
+	if s.src != nil && s.newest == len(s.marks) {
+		s.mark(tok)
+	}
+}
+
+// finish records that the input has been read to the end. Seekable inputs
+// stay open so they can be re-read.
+func (s *lazyStream) finish() {
+	s.done = true
+	s.total = s.newest + 1
+	s.totalKnown = true
+	if s.src == nil {
+		s.closeInput()
+	}
+}
+
+func (s *lazyStream) Current() (token, bool) {
+	return s.Peek(0)
+}
+
+// Peek can only see words in the history window since lazy streams do not
+// read ahead.
+func (s *lazyStream) Peek(offset int) (token, bool) {
+	i := s.idx + offset
+	if s.newest < 0 || i < s.oldest() || i > s.newest {
+		return token{}, false
 	}
-	return s.currentWord, true
+	return s.history[i%len(s.history)], true
 }
 
 func (s *lazyStream) Next() tea.Cmd {
+	if s.idx < s.newest {
+		s.idx++
+		return nil
+	}
 	if s.done {
 		return nil
 	}
@@ -208,7 +368,141 @@ func (s *lazyStream) Next() tea.Cmd {
 }
 
 func (s *lazyStream) Prev() {
-	panic("lazyStream Prev not supported")
+	s.Seek(s.idx - 1)
+}
+
+// Seek moves within the history window, or re-reads the input around idx
+// when it is seekable, which works even before the first word of a restart
+// has arrived.
+func (s *lazyStream) Seek(idx int) {
+	if s.newest < 0 && len(s.marks) == 0 {
+		return
+	}
+	if s.src == nil || (idx >= s.oldest() && idx <= s.newest) {
+		s.idx = min(max(idx, s.oldest()), s.newest)
+		return
+	}
+	if err := s.reposition(max(idx, 0)); err != nil {
+		s.err = err
+		s.done = true
+	}
+}
+
+// SeekFraction moves to the word at the given fraction of the input by
+// byte offset, which works before the total word count is known.
+func (s *lazyStream) SeekFraction(f float64) {
+	if s.src == nil || len(s.marks) == 0 {
+		return
+	}
+	size, final := s.src.size()
+	if !final {
+		s.short = true
+		return
+	}
+	target := int64(f * float64(size))
+	if err := s.readUntil(func() bool {
+		return s.marks[len(s.marks)-1].offset >= target
+	}); err != nil {
+		s.err = err
+		s.done = true
+		return
+	}
+	idx, _ := slices.BinarySearchFunc(s.marks, target, func(m wordMark, t int64) int {
+		return cmp.Compare(m.offset, t)
+	})
+	s.Seek(min(idx, len(s.marks)-1))
+}
+
+// reposition refills the history window so that it ends at idx, reading
+// forward past the words seen so far if needed.
+func (s *lazyStream) reposition(idx int) error {
+	if err := s.readUntil(func() bool { return len(s.marks) > idx }); err != nil {
+		return err
+	}
+	idx = min(idx, len(s.marks)-1)
+	start := max(idx-len(s.history)+1, 0)
+
+	s.gen++
+	s.waitingToken = false
+	s.done = false
+	s.newest = start - 1
+	mark := s.markToken(start)
+	s.tokenizer = newTokenizerAt(s.src.readFrom(mark.offset), mark)
+	for s.newest < idx {
+		tok, done, err := s.tokenizer.next()
+		if err != nil {
+			return err
+		}
+		if tok.text != "" {
+			mark := s.markToken(s.newest + 1)
+			tok.paragraphStart, tok.heading = mark.paragraphStart, mark.heading
+			s.push(tok)
+		}
+		if done {
+			s.finish()
+			break
+		}
+	}
+	if s.totalKnown && s.newest == s.total-1 {
+		s.done = true
+	}
+	s.idx = s.newest
+	return nil
+}
+
+// readUntil extends marks by reading past the last known word until enough
+// is known or the input ends. The history window is left alone. It reads
+// only the input that has arrived, since waiting on a pipe would freeze the
+// screen, and sets short if that was not enough.
+func (s *lazyStream) readUntil(enough func() bool) error {
+	if len(s.marks) == 0 || enough() || s.totalKnown {
+		return nil
+	}
+	last := s.markToken(len(s.marks) - 1)
+	t := newTokenizerAt(s.src.readArrived(last.offset), last)
+	// Skip the last known word itself.
+	if _, _, err := t.next(); err != nil {
+		return s.arrived(err)
+	}
+	for !enough() {
+		tok, done, err := t.next()
+		if err != nil {
+			return s.arrived(err)
+		}
+		if tok.text != "" {
+			s.mark(tok)
+		}
+		if done {
+			s.total = len(s.marks)
+			s.totalKnown = true
+			return nil
+		}
+	}
+	return nil
+}
+
+// arrived turns running out of the input that has arrived into a short
+// seek rather than an error.
+func (s *lazyStream) arrived(err error) error {
+	if errors.Is(err, errNotArrived) {
+		s.short = true
+		return nil
+	}
+	return err
+}
+
+// shortSeeker is implemented by streams whose seeks can stop short while
+// the input is still arriving, rather than wait for it.
+type shortSeeker interface {
+	seekedShort() bool
+}
+
+// seekedShort reports whether a seek since the last call stopped short of
+// its target because the input was still arriving.
+func (s *lazyStream) seekedShort() bool {
+	short := s.short
+	s.short = false
+	return short
 }
 
 func (s *lazyStream) Restart() tea.Cmd {
@@ -216,19 +510,26 @@ func (s *lazyStream) Restart() tea.Cmd {
 		return nil
 	}
 	s.resetState()
+	if s.src != nil {
+		s.tokenizer = newTokenizer(s.src.readFrom(0))
+		return s.requestToken()
+	}
 	reader, err := openInput(s.filePath)
 	if err != nil {
 		s.err = err
 		s.done = true
 		return nil
 	}
+	if s.follow {
+		reader = followFile(reader)
+	}
 	s.inputCloser = reader
 	s.tokenizer = newTokenizer(reader)
 	return s.requestToken()
 }
 
 func (s *lazyStream) SupportsSeek() bool {
-	return false
+	return true
 }
 
 func (s *lazyStream) SupportsRestart() bool {
@@ -236,7 +537,7 @@ func (s *lazyStream) SupportsRestart() bool {
 }
 
 func (s *lazyStream) CanAdvance() bool {
-	return !s.done
+	return s.idx < s.newest || !s.done
 }
 
 func (s *lazyStream) Err() error {
@@ -244,17 +545,16 @@ func (s *lazyStream) Err() error {
 }
 
 func (s *lazyStream) Pos() int {
-	if !s.hasCurrent {
-		return -1
-	}
 	return s.idx
 }
 
 func (s *lazyStream) Total() (bool, int) {
-	if s.done {
-		return true, s.total
-	}
-	return false, 0
+	return s.totalKnown, s.total
+}
+
+// oldest is the index of the earliest word still in the history window.
+func (s *lazyStream) oldest() int {
+	return max(s.newest-len(s.history)+1, 0)
 }
 
 func (s *lazyStream) requestToken() tea.Cmd {
@@ -262,7 +562,13 @@ func (s *lazyStream) requestToken() tea.Cmd {
 		return nil
 	}
 	s.waitingToken = true
-	return tokenizeCmd(s.tokenizer)
+	return tokenizeCmd(s.tokenizer, s.gen)
+}
+
+// Close releases the input, including any spool file.
+func (s *lazyStream) Close() error {
+	s.closeInput()
+	return nil
 }
 
 func (s *lazyStream) closeInput() {
@@ -273,12 +579,15 @@ func (s *lazyStream) closeInput() {
 }
 
 func (s *lazyStream) resetState() {
+	s.gen++
 	s.done = false
 	s.err = nil
 	s.waitingToken = false
-	s.hasCurrent = false
-	s.currentWord = ""
+	s.newest = -1
 	s.idx = -1
-	s.total = 0
-	s.closeInput()
+	if s.src == nil {
+		s.total = 0
+		s.totalKnown = false
+		s.closeInput()
+	}
 }
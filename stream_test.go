@@ -1,10 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -18,9 +20,9 @@ func runCmd(t *testing.T, cmd tea.Cmd) tea.Msg {
 }
 
 func TestEagerStreamBasics(t *testing.T) {
-	s := newEagerStream([]string{"alpha", "beta"}, true)
-	if got, ok := s.Current(); !ok || got != "alpha" {
-		t.Fatalf("expected first word, got %q ok=%v", got, ok)
+	s := newEagerStream(tokenize("alpha beta"), true)
+	if got, ok := s.Current(); !ok || got.text != "alpha" {
+		t.Fatalf("expected first word, got %q ok=%v", got.text, ok)
 	}
 	if !s.CanAdvance() {
 		t.Fatalf("expected stream to be able to advance")
@@ -33,21 +35,37 @@ func TestEagerStreamBasics(t *testing.T) {
 	}
 
 	s.Next()
-	if got, _ := s.Current(); got != "beta" {
-		t.Fatalf("expected second word, got %q", got)
+	if got, _ := s.Current(); got.text != "beta" {
+		t.Fatalf("expected second word, got %q", got.text)
 	}
 	if s.CanAdvance() {
 		t.Fatalf("expected no further advance")
 	}
 
 	s.Prev()
-	if got, _ := s.Current(); got != "alpha" {
-		t.Fatalf("expected first word after prev, got %q", got)
+	if got, _ := s.Current(); got.text != "alpha" {
+		t.Fatalf("expected first word after prev, got %q", got.text)
 	}
 
 	s.Restart()
-	if got, _ := s.Current(); got != "alpha" {
-		t.Fatalf("expected first word after restart, got %q", got)
+	if got, _ := s.Current(); got.text != "alpha" {
+		t.Fatalf("expected first word after restart, got %q", got.text)
+	}
+}
+
+func TestEagerStreamSeekClamps(t *testing.T) {
+	s := newEagerStream(tokenize("alpha beta gamma"), false)
+	s.Seek(2)
+	if got, _ := s.Current(); got.text != "gamma" {
+		t.Fatalf("expected third word, got %q", got.text)
+	}
+	s.Seek(10)
+	if s.Pos() != 2 {
+		t.Fatalf("expected seek past end to clamp to 2, got %d", s.Pos())
+	}
+	s.Seek(-3)
+	if s.Pos() != 0 {
+		t.Fatalf("expected seek before start to clamp to 0, got %d", s.Pos())
 	}
 }
 
@@ -55,8 +73,8 @@ func TestLazyStreamFlow(t *testing.T) {
 	s := newLazyStream(io.NopCloser(strings.NewReader("one two")), "")
 	msg := runCmd(t, s.Init())
 	s.Handle(msg)
-	if got, ok := s.Current(); !ok || got != "one" {
-		t.Fatalf("expected first word, got %q ok=%v", got, ok)
+	if got, ok := s.Current(); !ok || got.text != "one" {
+		t.Fatalf("expected first word, got %q ok=%v", got.text, ok)
 	}
 	if !s.CanAdvance() {
 		t.Fatalf("expected stream to be able to advance")
@@ -67,8 +85,8 @@ func TestLazyStreamFlow(t *testing.T) {
 
 	msg = runCmd(t, s.Next())
 	s.Handle(msg)
-	if got, ok := s.Current(); !ok || got != "two" {
-		t.Fatalf("expected second word, got %q ok=%v", got, ok)
+	if got, ok := s.Current(); !ok || got.text != "two" {
+		t.Fatalf("expected second word, got %q ok=%v", got.text, ok)
 	}
 	if s.Pos() != 1 {
 		t.Fatalf("expected position 1, got %d", s.Pos())
@@ -81,14 +99,39 @@ func TestLazyStreamFlow(t *testing.T) {
 	}
 }
 
-func TestLazyStreamPrevPanics(t *testing.T) {
-	s := newLazyStream(io.NopCloser(strings.NewReader("one")), "")
-	defer func() {
-		if r := recover(); r == nil {
-			t.Fatal("expected panic from Prev")
-		}
-	}()
+func TestLazyStreamHistoryWindow(t *testing.T) {
+	words := make([]string, lazyHistory+10)
+	for i := range words {
+		words[i] = "w"
+	}
+	s := newLazyStream(io.NopCloser(strings.NewReader(strings.Join(words, " "))), "")
+	s.Handle(runCmd(t, s.Init()))
+	for s.Pos() < len(words)-1 {
+		s.Handle(runCmd(t, s.Next()))
+	}
+
 	s.Prev()
+	if s.Pos() != len(words)-2 {
+		t.Fatalf("expected to step back to %d, got %d", len(words)-2, s.Pos())
+	}
+	if cmd := s.Next(); cmd != nil {
+		t.Fatal("expected stepping forward within history not to read")
+	}
+	if s.Pos() != len(words)-1 {
+		t.Fatalf("expected to return to %d, got %d", len(words)-1, s.Pos())
+	}
+
+	s.Seek(0)
+	if s.Pos() != 10 {
+		t.Fatalf("expected seek to clamp to oldest word 10, got %d", s.Pos())
+	}
+	s.Prev()
+	if s.Pos() != 10 {
+		t.Fatalf("expected prev to stop at oldest word 10, got %d", s.Pos())
+	}
+	if _, ok := s.Peek(-1); ok {
+		t.Fatal("expected no word before the history window")
+	}
 }
 
 func TestLazyStreamRestartFile(t *testing.T) {
@@ -112,13 +155,270 @@ func TestLazyStreamRestartFile(t *testing.T) {
 
 	msg := runCmd(t, s.Init())
 	s.Handle(msg)
-	if got, ok := s.Current(); !ok || got != "hello" {
-		t.Fatalf("expected first word, got %q ok=%v", got, ok)
+	if got, ok := s.Current(); !ok || got.text != "hello" {
+		t.Fatalf("expected first word, got %q ok=%v", got.text, ok)
 	}
 
 	msg = runCmd(t, s.Restart())
 	s.Handle(msg)
-	if got, ok := s.Current(); !ok || got != "hello" {
-		t.Fatalf("expected first word after restart, got %q ok=%v", got, ok)
+	if got, ok := s.Current(); !ok || got.text != "hello" {
+		t.Fatalf("expected first word after restart, got %q ok=%v", got.text, ok)
+	}
+}
+
+func writeTemp(t *testing.T, content string) string {
+	t.Helper()
+	path := t.TempDir() + "/input.txt"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp: %v", err)
+	}
+	return path
+}
+
+func TestLazyFileStreamSeeksPastHistory(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("# Intro ##\n\n")
+	for i := range 2000 {
+		fmt.Fprintf(&b, "w%d", i)
+		if i%10 == 9 {
+			b.WriteString(".\n\n")
+		} else {
+			b.WriteString(" ")
+		}
+	}
+	path := writeTemp(t, b.String())
+	reader, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open temp: %v", err)
+	}
+	s := newLazyStream(reader, path)
+	s.Handle(runCmd(t, s.Init()))
+	for s.Pos() < 1000 {
+		s.Handle(runCmd(t, s.Next()))
+	}
+
+	s.Seek(21)
+	got, ok := s.Current()
+	if !ok || got.text != "w20" || !got.paragraphStart || !got.sentenceStart() || got.chapter != "Intro" {
+		t.Fatalf("expected paragraph start w20 in Intro, got %+v ok=%v", got, ok)
+	}
+	s.Seek(1)
+	if got, _ := s.Current(); got.text != "w0" || !got.paragraphStart {
+		t.Fatalf("expected paragraph start w0 after seeking back, got %+v", got)
+	}
+	if got, _ := s.Peek(-1); got.text != "Intro" || !got.heading {
+		t.Fatalf("expected heading word before w0, got %+v", got)
+	}
+	s.Prev()
+	s.Prev()
+	if s.Pos() != 0 {
+		t.Fatalf("expected prev to stop at 0, got %d", s.Pos())
+	}
+
+	s.Seek(1500)
+	if got, _ := s.Current(); got.text != "w1499." {
+		t.Fatalf("expected to read ahead to w1499., got %+v", got)
+	}
+	s.Handle(runCmd(t, s.Next()))
+	if got, _ := s.Current(); got.text != "w1500" || !got.paragraphStart {
+		t.Fatalf("expected reading to continue with paragraph start w1500, got %+v", got)
+	}
+
+	s.SeekFraction(0.5)
+	size, _ := s.src.size()
+	if got, _ := s.Current(); got.offset < size/2 || got.offset > size/2+10 {
+		t.Fatalf("expected the word at the middle byte %d, got %+v", size/2, got)
+	}
+	if known, total := s.Total(); known || total != 0 {
+		t.Fatalf("expected total to stay unknown, got %v/%d", known, total)
+	}
+	s.Seek(5000)
+	if known, total := s.Total(); !known || total != 2001 {
+		t.Fatalf("expected total 2001 after seeking past the end, got %v/%d", known, total)
+	}
+	if got, _ := s.Current(); got.text != "w1999." {
+		t.Fatalf("expected last word, got %+v", got)
+	}
+	if s.CanAdvance() {
+		t.Fatal("expected no words after the last one")
+	}
+}
+
+func TestSpooledStreamRestartsAndSeeks(t *testing.T) {
+	pr, pw := io.Pipe()
+	go func() {
+		for i := range 800 {
+			fmt.Fprintf(pw, "w%d ", i)
+		}
+		pw.Close()
+	}()
+	s, err := newSpooledStream(pr)
+	if err != nil {
+		t.Fatalf("spool: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	if !s.SupportsRestart() {
+		t.Fatal("expected spooled stdin to support restart")
+	}
+
+	s.Handle(runCmd(t, s.Init()))
+	for s.Pos() < 700 {
+		s.Handle(runCmd(t, s.Next()))
+	}
+	s.Seek(3)
+	if got, _ := s.Current(); got.text != "w3" {
+		t.Fatalf("expected w3 after seeking back past history, got %+v", got)
+	}
+
+	s.Handle(runCmd(t, s.Restart()))
+	if got, _ := s.Current(); got.text != "w0" || s.Pos() != 0 {
+		t.Fatalf("expected w0 after restart, got %+v at %d", got, s.Pos())
+	}
+}
+
+func TestFollowedStreamWaitsForAppendedText(t *testing.T) {
+	path := writeTemp(t, "first line\n")
+	s, err := buildStream(false, false, true, path)
+	if err != nil {
+		t.Fatalf("buildStream: %v", err)
+	}
+	t.Cleanup(func() { s.(*lazyStream).closeInput() })
+
+	s.Handle(runCmd(t, s.Init()))
+	s.Handle(runCmd(t, s.Next()))
+	if got, _ := s.Current(); got.text != "line" {
+		t.Fatalf("expected second word, got %q", got.text)
+	}
+
+	next := s.Next()
+	go func() {
+		time.Sleep(2 * followPoll)
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		f.WriteString("appended later\n")
+	}()
+	s.Handle(runCmd(t, next))
+	if got, _ := s.Current(); got.text != "appended" {
+		t.Fatalf("expected the appended word, got %q", got.text)
+	}
+	if !s.CanAdvance() {
+		t.Fatalf("a followed file should never end")
+	}
+}
+
+func TestLazyStreamStaysBackWhenAWordArrives(t *testing.T) {
+	s := newLazyStream(io.NopCloser(strings.NewReader("one two three")), "")
+	s.Handle(runCmd(t, s.Init()))
+	s.Handle(runCmd(t, s.Next()))
+	cmd := s.Next()
+	s.Prev()
+	s.Handle(runCmd(t, cmd))
+	if got, _ := s.Current(); s.Pos() != 0 || got.text != "one" {
+		t.Fatalf("expected to stay on the first word, got %d %q", s.Pos(), got.text)
+	}
+	s.Next()
+	s.Next()
+	if got, _ := s.Current(); got.text != "three" {
+		t.Fatalf("expected the word read meanwhile to be kept, got %q", got.text)
+	}
+}
+
+func TestLazyFileStreamMarksKeepChapters(t *testing.T) {
+	path := writeTemp(t, "# One\nw0 w1 w2\n# Two\nw3 w4")
+	reader, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open temp: %v", err)
+	}
+	s := newLazyStream(reader, path)
+	t.Cleanup(func() { _ = s.Close() })
+	s.Handle(runCmd(t, s.Init()))
+	for s.CanAdvance() {
+		s.Handle(runCmd(t, s.Next()))
+	}
+	if len(s.chapters) != 2 {
+		t.Fatalf("expected the two chapter titles to be kept once, got %q", s.chapters)
+	}
+	// With a one-word window every seek re-reads the file from a mark.
+	s.history = make([]token, 1)
+	s.Seek(5)
+	if got, ok := s.Current(); !ok || got.text != "w3" || got.chapter != "Two" {
+		t.Fatalf("expected w3 in Two, got %+v ok=%v", got, ok)
+	}
+	s.Seek(1)
+	if got, ok := s.Current(); !ok || got.text != "w0" || got.chapter != "One" {
+		t.Fatalf("expected w0 in One, got %+v ok=%v", got, ok)
+	}
+}
+
+func TestLazyFileStreamSeeksBeforeARestartArrives(t *testing.T) {
+	path := writeTemp(t, "# One\nw0 w1 w2\n# Two\nw3 w4")
+	reader, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open temp: %v", err)
+	}
+	s := newLazyStream(reader, path)
+	t.Cleanup(func() { _ = s.Close() })
+	s.Handle(runCmd(t, s.Init()))
+	for s.CanAdvance() {
+		s.Handle(runCmd(t, s.Next()))
+	}
+	s.Restart()
+	s.Seek(5)
+	if got, ok := s.Current(); !ok || got.text != "w3" || got.chapter != "Two" {
+		t.Fatalf("expected w3 in Two, got %+v ok=%v", got, ok)
+	}
+}
+
+func TestSpooledStreamSeeksOnlyAsFarAsHasArrived(t *testing.T) {
+	pr, pw := io.Pipe()
+	var first strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&first, "w%d ", i)
+	}
+	go pw.Write([]byte(first.String()))
+	s, err := newSpooledStream(pr)
+	if err != nil {
+		t.Fatalf("spool: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	s.Handle(runCmd(t, s.Init()))
+
+	// The pipe stays open with nothing more to say, so a seek past what
+	// has come in must not wait for it.
+	seeked := make(chan struct{})
+	go func() {
+		s.Seek(5000)
+		close(seeked)
+	}()
+	select {
+	case <-seeked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the seek not to wait for the pipe")
+	}
+	if got, _ := s.Current(); got.text != "w99" || !s.seekedShort() || s.seekedShort() {
+		t.Fatalf("expected to stop at the last whole word that arrived, got %+v", got)
+	}
+	m := model{stream: s, width: 80, height: 3}
+	s.SeekFraction(0.5)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 3})
+	if !strings.Contains(next.(model).notice, "Still loading") {
+		t.Fatalf("expected a notice while the input is arriving, got %q", next.(model).notice)
+	}
+
+	go func() {
+		for i := 100; i < 200; i++ {
+			fmt.Fprintf(pw, "w%d ", i)
+		}
+		pw.Close()
+	}()
+	for s.CanAdvance() {
+		s.Handle(runCmd(t, s.Next()))
+	}
+	s.Seek(150)
+	if got, _ := s.Current(); got.text != "w150" {
+		t.Fatalf("expected w150 once it has arrived, got %+v", got)
 	}
 }
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// textView is the scrollable full-text view toggled with v. lineStarts holds
+// the word index each line of the text starts with, so that closing the view
+// can resume from wherever it was scrolled to.
+type textView struct {
+	viewport   viewport.Model
+	lineStarts []int
+	// openedAt is the scroll offset the view opened with, to tell whether
+	// it has been scrolled since.
+	openedAt int
+}
+
+// openText shows the full text centered on the current word. Lazy streams
+// only have the words in their history window to show.
+func (m *model) openText() {
+	m.text = textView{viewport: viewport.New(m.width, m.textHeight())}
+	m.layoutText()
+	m.showText = true
+}
+
+// textHeight leaves room for the status line.
+func (m model) textHeight() int {
+	return max(m.height-1, 1)
+}
+
+// layoutText wraps the text to the screen and scrolls the current word to
+// the middle of the view.
+func (m *model) layoutText() {
+	width := max(m.width-2, 10)
+	pos := m.stream.Pos()
+	var (
+		lines      []string
+		lineStarts []int
+		line       []string
+		used       int
+		current    int
+	)
+	flush := func() {
+		if len(line) > 0 {
+			lines = append(lines, " "+strings.Join(line, " "))
+			line, used = nil, 0
+		}
+	}
+	first := 0
+	for {
+		if _, ok := m.stream.Peek(first - 1); !ok {
+			break
+		}
+		first--
+	}
+	for i := first; ; i++ {
+		tok, ok := m.stream.Peek(i)
+		if !ok {
+			break
+		}
+		w := lipgloss.Width(tok.text)
+		if i > first && (tok.paragraphStart || tok.heading) {
+			flush()
+			lines = append(lines, "")
+			lineStarts = append(lineStarts, pos+i)
+		} else if used > 0 && used+1+w > width {
+			flush()
+		}
+		if len(line) == 0 {
+			lineStarts = append(lineStarts, pos+i)
+		}
+		word := m.theme.text().Render(tok.text)
+		if i == 0 {
+			word = m.theme.pivot().Render(tok.text)
+			current = len(lines)
+		}
+		if used > 0 {
+			used++
+		}
+		line = append(line, word)
+		used += w
+	}
+	flush()
+
+	m.text.viewport.Width, m.text.viewport.Height = m.width, m.textHeight()
+	m.text.viewport.SetContent(strings.Join(lines, "\n"))
+	m.text.viewport.SetYOffset(current - m.text.viewport.Height/2)
+	m.text.lineStarts = lineStarts
+	m.text.openedAt = m.text.viewport.YOffset
+}
+
+// closeText goes back to word-by-word reading, from the line in the middle
+// of the view if it was scrolled.
+func (m *model) closeText() {
+	m.showText = false
+	v := m.text.viewport
+	if v.YOffset == m.text.openedAt || len(m.text.lineStarts) == 0 {
+		return
+	}
+	line := min(v.YOffset+v.Height/2, len(m.text.lineStarts)-1)
+	m.jump(func() { m.stream.Seek(m.text.lineStarts[line]) })
+}
+
+// handleTextKey scrolls the text view; v, esc and enter close it, and ctrl+c
+// and the quit key quit as they do everywhere else.
+func (m *model) handleTextKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case msg.String() == "ctrl+c":
+		return tea.Quit
+	case key.Matches(msg, m.keys().Quit):
+		return m.quit()
+	case key.Matches(msg, m.keys().Text):
+		m.closeText()
+		return nil
+	}
+	switch msg.String() {
+	case "esc", "enter":
+		m.closeText()
+		return nil
+	}
+	var cmd tea.Cmd
+	m.text.viewport, cmd = m.text.viewport.Update(msg)
+	return cmd
+}
+
+func (m model) textStatus() string {
+	if m.confirmQuit {
+		return m.quitQuestion()
+	}
+	return "Full text  j/k, pgup/pgdown: scroll  v/esc: read from the middle line"
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// theme holds the colors the reader is drawn with. Colors are anything
+// lipgloss accepts, such as "#FF3B30" or an ANSI number like "9"; an empty
+// color leaves the terminal's own.
+type theme struct {
+	// Text is the color of the words; empty leaves the terminal's own.
+	Text       string `json:"text,omitempty"`
+	Pivot      string `json:"pivot,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Background string `json:"background,omitempty"`
+	// Dim is for secondary elements such as the empty part of the progress
+	// bar and words shown around the current one.
+	Dim string `json:"dim,omitempty"`
+
+	emphasis pivotEmphasis
+}
+
+// pivotEmphasis is how the pivot letter stands out besides its color.
+type pivotEmphasis struct {
+	bold, underline, reverse bool
+	// off disables pivot highlighting altogether, color included.
+	off bool
+}
+
+// parsePivotStyle parses -pivot-style: "none", "plain" for color only, or a
+// comma-separated mix of bold, underline and reverse.
+func parsePivotStyle(s string) (pivotEmphasis, error) {
+	var e pivotEmphasis
+	switch s {
+	case "":
+		return pivotEmphasis{bold: true}, nil
+	case "none":
+		return pivotEmphasis{off: true}, nil
+	case "plain":
+		return e, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		switch strings.TrimSpace(part) {
+		case "bold":
+			e.bold = true
+		case "underline":
+			e.underline = true
+		case "reverse":
+			e.reverse = true
+		default:
+			return e, fmt.Errorf("unknown pivot style %q; use none, plain, or a mix of bold, underline and reverse", part)
+		}
+	}
+	return e, nil
+}
+
+// defaultPivotStyle is the pivot style when none is configured. lipgloss maps
+// theme colors down to 256 colors by itself, but with only 8 or 16 the nearest
+// match to the pivot color can barely differ from the text, so the pivot is
+// underlined as well. Without colors it is shown in reverse video.
+func defaultPivotStyle(p termenv.Profile, noColor bool) string {
+	switch {
+	case noColor:
+		return "reverse"
+	case p == termenv.ANSI:
+		return "bold,underline"
+	}
+	return ""
+}
+
+const defaultTheme = "default"
+
+var builtinThemes = map[string]theme{
+	defaultTheme: {Pivot: "#FF3B30", Status: "#777777", Dim: "#3A3A3A"},
+	"light":      {Pivot: "#D70015", Status: "#666666", Background: "#FAFAFA", Dim: "#CCCCCC"},
+	"amber":      {Pivot: "#FFFFFF", Status: "#B87A00", Background: "#1A1200", Dim: "#4D3800"},
+	"solarized":  {Pivot: "#DC322F", Status: "#839496", Background: "#002B36", Dim: "#073642"},
+	// night is dark and low in brightness, for reading late.
+	"night": {Text: "#7A7A7A", Pivot: "#9C5B4E", Status: "#4A4A4A", Background: "#0C0C0C", Dim: "#242424"},
+}
+
+// themeNames lists the built-in themes and those defined in the config file,
+// sorted.
+func themeNames(cfg config) []string {
+	names := slices.Collect(maps.Keys(builtinThemes))
+	for n := range cfg.Themes {
+		if _, ok := builtinThemes[n]; !ok {
+			names = append(names, n)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// resolveTheme looks a theme up by name, among the built-in themes and those
+// defined in the config file. Colors a config theme leaves out come from the
+// built-in theme of the same name, or the default one.
+func resolveTheme(name string, cfg config) (theme, error) {
+	if name == "" {
+		name = defaultTheme
+	}
+	base, builtin := builtinThemes[name]
+	custom, defined := cfg.Themes[name]
+	if !builtin && !defined {
+		return theme{}, fmt.Errorf("unknown theme %q; available: %s", name, strings.Join(themeNames(cfg), ", "))
+	}
+	if !builtin {
+		base = builtinThemes[defaultTheme]
+	}
+	if custom.Text != "" {
+		base.Text = custom.Text
+	}
+	if custom.Pivot != "" {
+		base.Pivot = custom.Pivot
+	}
+	if custom.Status != "" {
+		base.Status = custom.Status
+	}
+	if custom.Background != "" {
+		base.Background = custom.Background
+	}
+	if custom.Dim != "" {
+		base.Dim = custom.Dim
+	}
+	return base, nil
+}
+
+// monochrome drops a theme's colors, for NO_COLOR and -no-color. The pivot
+// then only stands out by its emphasis.
+func (t theme) monochrome() theme {
+	return theme{emphasis: t.emphasis}
+}
+
+// text is the style for plain words.
+func (t theme) text() lipgloss.Style {
+	s := lipgloss.NewStyle()
+	if t.Background != "" {
+		s = s.Background(lipgloss.Color(t.Background))
+	}
+	if t.Text != "" {
+		s = s.Foreground(lipgloss.Color(t.Text))
+	}
+	return s
+}
+
+func (t theme) pivot() lipgloss.Style {
+	if t.emphasis.off {
+		return t.text()
+	}
+	return t.text().
+		Foreground(lipgloss.Color(t.Pivot)).
+		Bold(t.emphasis.bold).
+		Underline(t.emphasis.underline).
+		Reverse(t.emphasis.reverse)
+}
+
+// themeChoice is a theme the theme key can switch to.
+type themeChoice struct {
+	name  string
+	theme theme
+}
+
+// cycleTheme switches to the next of the available themes.
+func (m *model) cycleTheme() {
+	if len(m.themes) < 2 {
+		return
+	}
+	m.themeIdx = (m.themeIdx + 1) % len(m.themes)
+	m.theme = m.themes[m.themeIdx].theme
+	m.notice = "Theme: " + m.themes[m.themeIdx].name
+}
+
+func (t theme) status() lipgloss.Style {
+	return t.text().Foreground(lipgloss.Color(t.Status))
+}
+
+func (t theme) dim() lipgloss.Style {
+	return t.text().Foreground(lipgloss.Color(t.Dim))
+}
+
+// place centers content in an area filled with the theme's background.
+func (t theme) place(width, height int, hPos, vPos lipgloss.Position, content string) string {
+	if t.Background == "" {
+		return lipgloss.Place(width, height, hPos, vPos, content)
+	}
+	return lipgloss.Place(width, height, hPos, vPos, content, lipgloss.WithWhitespaceBackground(lipgloss.Color(t.Background)))
+}
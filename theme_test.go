@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestResolveTheme(t *testing.T) {
+	cfg := config{Themes: map[string]theme{
+		"light": {Pivot: "#0000FF"},
+		"paper": {Background: "#FFFFF0"},
+	}}
+	th, err := resolveTheme("", cfg)
+	if err != nil || th != builtinThemes[defaultTheme] {
+		t.Fatalf("expected the default theme, got %+v err=%v", th, err)
+	}
+	th, err = resolveTheme("light", cfg)
+	if err != nil || th.Pivot != "#0000FF" || th.Background != builtinThemes["light"].Background {
+		t.Fatalf("expected light with a blue pivot, got %+v err=%v", th, err)
+	}
+	th, err = resolveTheme("paper", cfg)
+	if err != nil || th.Background != "#FFFFF0" || th.Pivot != builtinThemes[defaultTheme].Pivot {
+		t.Fatalf("expected paper to fill in from the default theme, got %+v err=%v", th, err)
+	}
+	if _, err := resolveTheme("neon", cfg); err == nil {
+		t.Fatal("expected an unknown theme to be rejected")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	cfg, err := loadConfig()
+	if err != nil || cfg.Theme != "" {
+		t.Fatalf("expected an empty config without a file, got %+v err=%v", cfg, err)
+	}
+	path, err := configPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := `{"theme": "paper", "themes": {"paper": {"pivot": "#AA0000", "dim": "#DDDDDD"}}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = loadConfig()
+	if err != nil || cfg.Theme != "paper" || cfg.Themes["paper"].Dim != "#DDDDDD" {
+		t.Fatalf("unexpected config %+v err=%v", cfg, err)
+	}
+}
+
+func TestParsePivotStyle(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want pivotEmphasis
+	}{
+		{"", pivotEmphasis{bold: true}},
+		{"plain", pivotEmphasis{}},
+		{"none", pivotEmphasis{off: true}},
+		{"bold, underline", pivotEmphasis{bold: true, underline: true}},
+		{"reverse", pivotEmphasis{reverse: true}},
+	} {
+		got, err := parsePivotStyle(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("parsePivotStyle(%q) = %+v, %v; want %+v", tc.in, got, err, tc.want)
+		}
+	}
+	if _, err := parsePivotStyle("blink"); err == nil {
+		t.Error("expected an unknown style to be rejected")
+	}
+}
+
+func TestMonochromeTheme(t *testing.T) {
+	th := builtinThemes["solarized"]
+	th.emphasis = pivotEmphasis{reverse: true}
+	mono := th.monochrome()
+	if mono.Pivot != "" || mono.Status != "" || mono.Background != "" || mono.Dim != "" {
+		t.Fatalf("expected no colors, got %+v", mono)
+	}
+	if !mono.emphasis.reverse {
+		t.Fatal("expected the pivot emphasis to be kept")
+	}
+}
+
+func TestDefaultPivotStyle(t *testing.T) {
+	for _, tc := range []struct {
+		profile termenv.Profile
+		noColor bool
+		want    string
+	}{
+		{termenv.TrueColor, false, ""},
+		{termenv.ANSI256, false, ""},
+		{termenv.ANSI, false, "bold,underline"},
+		{termenv.TrueColor, true, "reverse"},
+	} {
+		if got := defaultPivotStyle(tc.profile, tc.noColor); got != tc.want {
+			t.Errorf("defaultPivotStyle(%v, %v) = %q, want %q", tc.profile, tc.noColor, got, tc.want)
+		}
+	}
+}
+
+func TestCycleTheme(t *testing.T) {
+	themes := []themeChoice{{"default", builtinThemes["default"]}, {"night", builtinThemes["night"]}}
+	m := model{stream: newEagerStream(tokenize("word"), false), width: 80, height: 3, themes: themes}
+	m = press(m, "T")
+	if m.theme != builtinThemes["night"] || !strings.Contains(m.View(), "Theme: night") {
+		t.Fatalf("expected the night theme, got %+v", m.theme)
+	}
+	m = press(m, "T")
+	if m.theme != builtinThemes["default"] {
+		t.Fatalf("expected to cycle back to the default theme, got %+v", m.theme)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timingHeader names the columns of a timing log.
+var timingHeader = []string{"start", "position", "word", "duration_ms", "scheduled_ms"}
+
+// timingLog records every frame shown during playback for -timing-log: when
+// it appeared, where it is in the document, its words, how long it actually
+// stayed on screen and how long it was meant to. A frame is open from when
+// its tick is scheduled until the tick fires or playback pauses.
+type timingLog struct {
+	w         *csv.Writer
+	open      bool
+	start     time.Time
+	position  int
+	word      string
+	scheduled time.Duration
+}
+
+func newTimingLog(w io.Writer) *timingLog {
+	l := &timingLog{w: csv.NewWriter(w)}
+	l.w.Write(timingHeader)
+	return l
+}
+
+// show opens a frame, unless one is open already.
+func (l *timingLog) show(frame []token, position int, at time.Time, scheduled time.Duration) {
+	if l == nil || l.open || len(frame) == 0 {
+		return
+	}
+	var words []string
+	for _, tok := range frame {
+		words = append(words, tok.text)
+	}
+	l.open, l.start, l.position, l.word, l.scheduled = true, at, position, strings.Join(words, " "), scheduled
+}
+
+// close writes the open frame, if any, as having been shown until at.
+func (l *timingLog) close(at time.Time) {
+	if l == nil || !l.open {
+		return
+	}
+	l.open = false
+	l.w.Write([]string{
+		l.start.Format(time.RFC3339Nano),
+		strconv.Itoa(l.position + 1),
+		l.word,
+		formatMillis(at.Sub(l.start)),
+		formatMillis(l.scheduled),
+	})
+}
+
+// flush writes out what is buffered, returning the first error met.
+func (l *timingLog) flush() error {
+	l.w.Flush()
+	return l.w.Error()
+}
+
+func formatMillis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// timingRow is a frame read back from a timing log.
+type timingRow struct {
+	start     time.Time
+	position  int
+	word      string
+	duration  time.Duration
+	scheduled time.Duration
+}
+
+// readTimingLog reads a log written by -timing-log.
+func readTimingLog(r io.Reader) ([]timingRow, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF || (err == nil && !slices.Equal(header, timingHeader)) {
+		return nil, errors.New("not a timing log: expected the columns " + strings.Join(timingHeader, ","))
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rows []timingRow
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, err1 := time.Parse(time.RFC3339Nano, rec[0])
+		pos, err2 := strconv.Atoi(rec[1])
+		duration, err3 := parseMillis(rec[3])
+		scheduled, err4 := parseMillis(rec[4])
+		if err := errors.Join(err1, err2, err3, err4); err != nil {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, timingRow{start: start, position: pos - 1, word: rec[2], duration: duration, scheduled: scheduled})
+	}
+}
+
+func parseMillis(s string) (time.Duration, error) {
+	ms, err := strconv.ParseFloat(s, 64)
+	if err != nil || ms < 0 {
+		return 0, fmt.Errorf("bad duration %q", s)
+	}
+	return time.Duration(ms * float64(time.Millisecond)), nil
+}
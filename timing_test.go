@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimingLog(t *testing.T) {
+	c := newFakeClock()
+	var b strings.Builder
+	m := model{stream: newEagerStream(tokenize("one two three four"), false), pacing: pacing{wpm: 60}, clock: c, markA: -1, markB: -1, timing: newTimingLog(&b)}
+	next, cmd := m.Update(keyMsg(" "))
+	m = next.(model)
+	m, cmd = step(t, m, cmd)
+	m, _ = step(t, m, cmd)
+	// Pausing halfway through a word ends its row early.
+	c.Advance(400 * time.Millisecond)
+	m = press(m, " ")
+	c.Advance(time.Minute)
+	if err := m.timing.flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "start,position,word,duration_ms,scheduled_ms\n" +
+		"2024-01-01T09:00:00Z,1,one,1000.000,1000.000\n" +
+		"2024-01-01T09:00:01Z,2,two,1000.000,1000.000\n" +
+		"2024-01-01T09:00:02Z,3,three,400.000,1000.000\n"
+	if b.String() != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, b.String())
+	}
+}
+
+func TestReplay(t *testing.T) {
+	log := "start,position,word,duration_ms,scheduled_ms\n" +
+		"2024-01-01T09:00:00Z,1,one,200.000,200.000\n" +
+		"2024-01-01T09:00:00.2Z,2,two words,450.500,400.000\n" +
+		// A pause of a few seconds after "two words".
+		"2024-01-01T09:00:05Z,4,four,200.000,200.000\n"
+	rows, err := readTimingLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[1].word != "two words" || rows[1].position != 1 || rows[1].duration != 450500*time.Microsecond {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	if _, err := readTimingLog(strings.NewReader("a,b\n1,2\n")); err == nil || !strings.Contains(err.Error(), "not a timing log") {
+		t.Fatalf("expected another CSV file to be turned down, got %v", err)
+	}
+
+	m := newReplayModel(rows, false, theme{}, alignORP)
+	if m.shown[1] != 4800*time.Millisecond || m.shown[2] != 200*time.Millisecond {
+		t.Fatalf("expected the pause to be replayed, got %v", m.shown)
+	}
+	if m := newReplayModel(rows, true, theme{}, alignORP); m.shown[1] != 450500*time.Microsecond {
+		t.Fatalf("expected -skip-pauses to keep only the playing time, got %v", m.shown)
+	}
+
+	c := newFakeClock()
+	m.clock, m.width, m.height = c, 80, 5
+	start := c.Now()
+	cmd := m.Init()
+	for cmd != nil {
+		next, nextCmd := m.Update(runCmd(t, cmd))
+		m, cmd = next.(replayModel), nextCmd
+	}
+	if got := c.Now().Sub(start); got != 5200*time.Millisecond {
+		t.Fatalf("expected the frames to take as long as they did, got %v", got)
+	}
+	if view := m.View(); !strings.Contains(view, "four") || !strings.Contains(view, "End of replay") {
+		t.Fatalf("expected to end on the last frame:\n%s", view)
+	}
+}
@@ -9,57 +9,233 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// token is a single displayed word along with what the tokenizer learned
+// about its position in the text.
+type token struct {
+	text string
+	// sentenceWord is the index of the word within its sentence, so zero
+	// marks the first word of a sentence.
+	sentenceWord int
+	// paragraphStart is set on the first word after a blank line, and on the
+	// first word of the text.
+	paragraphStart bool
+	// heading is set on the first word of a markdown heading, which starts a
+	// new chapter titled chapter.
+	heading bool
+	chapter string
+	// headingLine is set on every word of a heading line.
+	headingLine bool
+	// offset is the byte offset of the word in the input.
+	offset int64
+}
+
+func (t token) sentenceStart() bool {
+	return t.sentenceWord == 0
+}
+
 type tokenMsg struct {
-	word string
+	tok  token
 	done bool
 	err  error
+	// gen lets a stream drop tokens requested before it was repositioned,
+	// and from those requested by a stream it replaced on reloading.
+	gen  int
+	from *tokenizer
 }
 
 type tokenizer struct {
 	reader *bufio.Reader
-	buf    strings.Builder
-	done   bool
+	// pending is the rest of a heading line read ahead by readHeading, to be
+	// read again before the reader.
+	pending      *strings.Reader
+	buf          strings.Builder
+	done         bool
+	sentenceWord int
+	// newlines counts line breaks since the last word started, so that a
+	// blank line can be recognised as a paragraph break.
+	newlines  int
+	paragraph bool
+	lineStart bool
+	// heading is set once a heading marker was consumed, until the first word
+	// of the heading is emitted.
+	heading bool
+	chapter string
+	// inHeading is set for the rest of a heading line, so that optional
+	// closing markers can be dropped too.
+	inHeading bool
+	// pos is the byte offset of the next rune and start that of the word
+	// being read.
+	pos         int64
+	start       int64
+	headingLine bool
 }
 
 func newTokenizer(r io.Reader) *tokenizer {
-	return &tokenizer{reader: bufio.NewReader(r)}
+	return &tokenizer{reader: bufio.NewReader(r), newlines: 2}
+}
+
+// newTokenizerAt resumes tokenizing at a word read earlier, from r reading
+// the input at the word's offset, picking up the sentence and chapter it was
+// in. The word's own paragraph and heading flags are not recovered; callers
+// restore them from mark.
+func newTokenizerAt(r io.Reader, mark token) *tokenizer {
+	return &tokenizer{
+		reader:       bufio.NewReader(r),
+		sentenceWord: mark.sentenceWord,
+		chapter:      mark.chapter,
+		inHeading:    mark.headingLine,
+		pos:          mark.offset,
+	}
 }
 
-func (t *tokenizer) next() (string, bool, error) {
+func (t *tokenizer) next() (token, bool, error) {
 	if t.done {
-		return "", true, nil
+		return token{}, true, nil
 	}
 
 	for {
-		r, _, err := t.reader.ReadRune()
+		r, size, err := t.readRune()
+		t.pos += int64(size)
 		if err != nil {
 			if err == io.EOF {
-				if t.buf.Len() > 0 {
-					token := t.buf.String()
+				t.done = true
+				if t.inHeading && isHeadingMarker(t.buf.String()) {
 					t.buf.Reset()
-					t.done = true
-					return token, true, nil
 				}
-				t.done = true
-				return "", true, nil
+				if t.buf.Len() > 0 {
+					return t.emit(), true, nil
+				}
+				return token{}, true, nil
 			}
-			return "", true, err
+			return token{}, true, err
 		}
 		if unicode.IsSpace(r) {
-			if t.buf.Len() > 0 {
-				token := t.buf.String()
+			if r == '\n' {
+				t.newlines++
+			}
+			if r != '\n' && t.lineStart && isHeadingMarker(t.buf.String()) {
+				if err := t.readHeading(); err != nil {
+					return token{}, true, err
+				}
+				continue
+			}
+			if t.inHeading && isHeadingMarker(t.buf.String()) {
 				t.buf.Reset()
-				return token, false, nil
+			}
+			if r == '\n' {
+				t.inHeading = false
+			}
+			if t.buf.Len() > 0 {
+				return t.emit(), false, nil
 			}
 			continue
 		}
+		if t.buf.Len() == 0 {
+			t.start = t.pos - int64(size)
+			t.headingLine = t.inHeading
+			t.paragraph = t.newlines >= 2 || t.heading
+			t.lineStart = t.newlines > 0
+			t.newlines = 0
+			if t.paragraph {
+				t.sentenceWord = 0
+			}
+		}
 		t.buf.WriteRune(r)
 	}
 }
 
-func tokenizeCmd(t *tokenizer) tea.Cmd {
+func (t *tokenizer) readRune() (rune, int, error) {
+	if t.pending != nil {
+		if r, size, err := t.pending.ReadRune(); err == nil {
+			return r, size, nil
+		}
+		t.pending = nil
+	}
+	return t.reader.ReadRune()
+}
+
+func (t *tokenizer) emit() token {
+	tok := token{
+		text:           t.buf.String(),
+		sentenceWord:   t.sentenceWord,
+		paragraphStart: t.paragraph,
+		heading:        t.heading,
+		chapter:        t.chapter,
+		headingLine:    t.headingLine,
+		offset:         t.start,
+	}
+	t.buf.Reset()
+	t.heading = false
+	if endsSentence(tok.text) {
+		t.sentenceWord = 0
+	} else {
+		t.sentenceWord++
+	}
+	return tok
+}
+
+// readHeading drops a heading marker and peeks at the rest of its line for
+// the chapter title, leaving the words themselves to be read as usual. A
+// marker only opens a line, so the previous line read ahead has always been
+// used up by then.
+func (t *tokenizer) readHeading() error {
+	t.buf.Reset()
+	line, err := t.reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line), "#"))
+	if title != "" {
+		t.heading = true
+		t.inHeading = true
+		t.chapter = title
+		t.sentenceWord = 0
+	}
+	t.pending = strings.NewReader(line)
+	return nil
+}
+
+// isHeadingMarker reports whether a word at the start of a line opens a
+// markdown ATX heading.
+func isHeadingMarker(word string) bool {
+	return len(word) >= 1 && len(word) <= 6 && strings.Trim(word, "#") == ""
+}
+
+// abbreviations end in a period without ending the sentence.
+var abbreviations = map[string]bool{
+	"mr.": true, "mrs.": true, "ms.": true, "dr.": true, "st.": true,
+	"jr.": true, "sr.": true, "vs.": true, "e.g.": true, "i.e.": true,
+}
+
+func endsSentence(word string) bool {
+	if abbreviations[strings.ToLower(word)] {
+		return false
+	}
+	trimmed := strings.TrimRight(word, "\"')]}”’»")
+	if trimmed == "" {
+		return false
+	}
+	switch trimmed[len(trimmed)-1] {
+	case '.', '!', '?':
+		return true
+	}
+	return strings.HasSuffix(trimmed, "…")
+}
+
+// endsClause reports whether a word closes a clause, which is where clause
+// frames are broken up.
+func endsClause(word string) bool {
+	if endsSentence(word) {
+		return true
+	}
+	trimmed := strings.TrimRight(word, "\"')]}”’»")
+	return strings.HasSuffix(trimmed, ",") || strings.HasSuffix(trimmed, ";") ||
+		strings.HasSuffix(trimmed, ":") || strings.HasSuffix(trimmed, "—")
+}
+
+func tokenizeCmd(t *tokenizer, gen int) tea.Cmd {
 	return func() tea.Msg {
-		word, done, err := t.next()
-		return tokenMsg{word: word, done: done, err: err}
+		tok, done, err := t.next()
+		return tokenMsg{tok: tok, done: done, err: err, gen: gen, from: t}
 	}
 }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenizeSentenceWords(t *testing.T) {
+	tokens := tokenize("Hi there. Mr. Smith said \"go!\" Then left")
+	want := []int{0, 1, 0, 1, 2, 3, 0, 1}
+	if len(tokens) != len(want) {
+		t.Fatalf("expected %d tokens, got %d", len(want), len(tokens))
+	}
+	for i, tok := range tokens {
+		if tok.sentenceWord != want[i] {
+			t.Fatalf("token %d (%q): expected sentence word %d, got %d", i, tok.text, want[i], tok.sentenceWord)
+		}
+	}
+}
+
+func TestTokenizeParagraphs(t *testing.T) {
+	tokens := tokenize("Title\n\nfirst line\nstill first\r\n\r\nsecond")
+	var starts []string
+	for _, tok := range tokens {
+		if tok.paragraphStart {
+			starts = append(starts, tok.text)
+			if !tok.sentenceStart() {
+				t.Fatalf("expected paragraph start %q to start a sentence", tok.text)
+			}
+		}
+	}
+	want := []string{"Title", "first", "second"}
+	if len(starts) != len(want) {
+		t.Fatalf("expected paragraph starts %q, got %q", want, starts)
+	}
+	for i := range want {
+		if starts[i] != want[i] {
+			t.Fatalf("expected paragraph starts %q, got %q", want, starts)
+		}
+	}
+}
+
+func TestTokenizeHeadings(t *testing.T) {
+	tokens := tokenize("Intro words\n\n## Chapter Two ##\nIt began #here\n# Three\nEnd")
+	var texts []string
+	for _, tok := range tokens {
+		texts = append(texts, tok.text)
+	}
+	want := []string{"Intro", "words", "Chapter", "Two", "It", "began", "#here", "Three", "End"}
+	if len(texts) != len(want) {
+		t.Fatalf("expected tokens %q, got %q", want, texts)
+	}
+	for i := range want {
+		if texts[i] != want[i] {
+			t.Fatalf("expected tokens %q, got %q", want, texts)
+		}
+	}
+	if tokens[0].chapter != "" || tokens[0].heading {
+		t.Fatalf("expected no chapter before the first heading, got %+v", tokens[0])
+	}
+	if !tokens[2].heading || tokens[2].chapter != "Chapter Two" {
+		t.Fatalf("expected heading for Chapter Two, got %+v", tokens[2])
+	}
+	if tokens[5].chapter != "Chapter Two" || tokens[5].heading {
+		t.Fatalf("expected body word in Chapter Two, got %+v", tokens[5])
+	}
+	if !tokens[7].heading || tokens[7].chapter != "Three" {
+		t.Fatalf("expected heading for Three, got %+v", tokens[7])
+	}
+}
+
+func TestTokenizeManyHeadingsKeepsOneReader(t *testing.T) {
+	tz := newTokenizer(strings.NewReader(strings.Repeat("# Part\nText here.\n", 1000)))
+	reader := tz.reader
+	words := 0
+	for {
+		tok, done, err := tz.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.text != "" {
+			words++
+		}
+		if done {
+			break
+		}
+	}
+	if words != 3000 {
+		t.Fatalf("expected 3000 words, got %d", words)
+	}
+	if tz.reader != reader {
+		t.Fatal("expected headings to be read ahead without wrapping the reader")
+	}
+}
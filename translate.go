@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// translateConfig sets up the translate keys under "translate" in the config
+// file.
+type translateConfig struct {
+	// To and From are the languages to translate to and from, as codes such
+	// as "en"; From is detected if left out.
+	To   string `json:"to,omitempty"`
+	From string `json:"from,omitempty"`
+	// Words is a word list that single words are looked up in first: a line
+	// per word, with the word and its translation separated by a tab.
+	Words string `json:"words,omitempty"`
+	// URL is a translation API: a LibreTranslate server's /translate, or a
+	// URL with {text} in it, and optionally {to} and {from}, that answers
+	// with the translation as plain text.
+	URL    string `json:"url,omitempty"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// translator translates words from a word list and anything else with a
+// web API, whichever it has.
+type translator struct {
+	cfg    translateConfig
+	client *http.Client
+
+	load     sync.Once
+	words    map[string][]string
+	wordsErr error
+}
+
+// newTranslator makes a translator from the config, or returns nil if it
+// names neither a word list nor an API.
+func newTranslator(cfg translateConfig) (*translator, error) {
+	if cfg.Words == "" && cfg.URL == "" {
+		return nil, nil
+	}
+	if cfg.URL != "" && cfg.To == "" {
+		return nil, errors.New(`translate: "to" must name the language to translate to`)
+	}
+	if cfg.Words != "" {
+		cfg.Words = expandHome(cfg.Words)
+		if _, err := os.Stat(cfg.Words); err != nil {
+			return nil, fmt.Errorf("translate: %w", err)
+		}
+	}
+	return &translator{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// translate returns the translations of text, a word unless sentence is
+// set. Words missing from the word list go to the API if there is one.
+func (t *translator) translate(text string, sentence bool) ([]string, error) {
+	if !sentence && t.cfg.Words != "" {
+		t.load.Do(func() { t.words, t.wordsErr = loadWordList(t.cfg.Words) })
+		if t.wordsErr != nil {
+			return nil, t.wordsErr
+		}
+		for _, w := range lemmas(strings.ToLower(text)) {
+			if found := t.words[w]; len(found) > 0 {
+				return found, nil
+			}
+		}
+	}
+	if t.cfg.URL == "" {
+		if sentence {
+			return nil, errors.New(`the word list only has words; set "url" under "translate" for sentences`)
+		}
+		return nil, nil
+	}
+	translation, err := t.ask(text)
+	if err != nil || translation == "" {
+		return nil, err
+	}
+	return []string{translation}, nil
+}
+
+// loadWordList reads a word list, keyed by the word in lower case. A word
+// on several lines has all their translations.
+func loadWordList(path string) (map[string][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	words := map[string][]string{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		word, translation, ok := strings.Cut(sc.Text(), "\t")
+		if !ok || strings.HasPrefix(word, "#") {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(word))
+		words[key] = append(words[key], strings.TrimSpace(translation))
+	}
+	return words, sc.Err()
+}
+
+// ask sends text to the API: filled into the URL if it has {text}, or else
+// posted the way LibreTranslate takes it.
+func (t *translator) ask(text string) (string, error) {
+	from := t.cfg.From
+	if from == "" {
+		from = "auto"
+	}
+	var (
+		resp *http.Response
+		err  error
+	)
+	if strings.Contains(t.cfg.URL, "{text}") {
+		u := strings.NewReplacer("{text}", url.QueryEscape(text), "{to}", url.QueryEscape(t.cfg.To), "{from}", url.QueryEscape(from)).Replace(t.cfg.URL)
+		resp, err = t.client.Get(u)
+	} else {
+		body, _ := json.Marshal(map[string]string{"q": text, "source": from, "target": t.cfg.To, "format": "text", "api_key": t.cfg.APIKey})
+		resp, err = t.client.Post(t.cfg.URL, "application/json", bytes.NewReader(body))
+	}
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if kind, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); kind == "text/plain" {
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("translation: %s", resp.Status)
+		}
+		return strings.TrimSpace(string(body)), nil
+	}
+	var reply struct {
+		TranslatedText string `json:"translatedText"`
+		Error          string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &reply); err != nil {
+		return "", fmt.Errorf("translation: unexpected reply (%s)", resp.Status)
+	}
+	if reply.Error != "" {
+		return "", fmt.Errorf("translation: %s", reply.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("translation: %s", resp.Status)
+	}
+	return reply.TranslatedText, nil
+}
+
+// translate shows a translation of the current word, or of its sentence.
+func (m *model) translate(sentence bool) tea.Cmd {
+	if m.translator == nil {
+		m.notice = `No translator; set "translate" in the config file`
+		return nil
+	}
+	tok, ok := m.stream.Current()
+	text := bareWord(tok.text)
+	if sentence {
+		text = m.sentence()
+	}
+	if !ok || text == "" {
+		return nil
+	}
+	t := m.translator
+	return m.openLookup(text, "No translation found.", func() ([]string, error) {
+		return t.translate(text, sentence)
+	})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTranslateWordList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "en-de.tsv")
+	if err := os.WriteFile(path, []byte("# English to German\ncat\tKatze\nrun\trennen\nrun\tlaufen\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tr, err := newTranslator(translateConfig{Words: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := tr.translate("Cats", false); err != nil || strings.Join(got, ",") != "Katze" {
+		t.Fatalf("expected cats to be found as cat, got %v, %v", got, err)
+	}
+	if got, _ := tr.translate("running", false); strings.Join(got, ",") != "rennen,laufen" {
+		t.Fatalf("expected every translation of run, got %v", got)
+	}
+	if got, err := tr.translate("dog", false); err != nil || len(got) != 0 {
+		t.Fatalf("expected nothing for a missing word, got %v, %v", got, err)
+	}
+	if _, err := tr.translate("The cat sat.", true); err == nil {
+		t.Fatal("expected a sentence to need an API")
+	}
+}
+
+func TestTranslateAPI(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"translatedText": "[%s>%s] %s"}`, req["source"], req["target"], req["q"])
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "%s>%s %s\n", r.URL.Query().Get("from"), r.URL.Query().Get("to"), r.URL.Query().Get("q"))
+	}))
+	defer srv.Close()
+
+	if _, err := newTranslator(translateConfig{URL: srv.URL}); err == nil {
+		t.Fatal("expected an API without a language to translate to to be rejected")
+	}
+	tr, _ := newTranslator(translateConfig{URL: srv.URL + "/translate", To: "en"})
+	if got, err := tr.translate("Le chat dort.", true); err != nil || strings.Join(got, "") != "[auto>en] Le chat dort." {
+		t.Fatalf("unexpected LibreTranslate answer %v, %v", got, err)
+	}
+	tr, _ = newTranslator(translateConfig{URL: srv.URL + "/?q={text}&from={from}&to={to}", To: "en", From: "fr"})
+	if got, _ := tr.translate("chat & chien", false); strings.Join(got, "") != "fr>en chat & chien" {
+		t.Fatalf("unexpected plain text answer %v", got)
+	}
+}
+
+func TestTranslateKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fr-en.tsv")
+	if err := os.WriteFile(path, []byte("chat\tcat\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tr, _ := newTranslator(translateConfig{Words: path})
+	m := model{stream: newEagerStream(tokenize("Le chat dort."), true), translator: tr, width: 60, height: 10}
+	m.stream.Seek(1)
+	next, cmd := m.Update(keyMsg("y"))
+	m, _ = step(t, next.(model), cmd)
+	if view := m.View(); !strings.Contains(view, "cat") {
+		t.Fatalf("expected the translation on screen, got %q", view)
+	}
+	m = press(m, "x")
+	next, _ = m.Update(keyMsg("Y"))
+	if m = next.(model); m.lookup == nil || m.lookup.text != "Le chat dort." {
+		t.Fatalf("expected Y to translate the sentence, got %+v", m.lookup)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// openTTY opens the terminal for reading keys when stdin is the text.
+func openTTY() (*os.File, error) {
+	return os.Open("/dev/tty")
+}
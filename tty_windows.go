@@ -0,0 +1,8 @@
+package main
+
+import "os"
+
+// openTTY opens the console for reading keys when stdin is the text.
+func openTTY() (*os.File, error) {
+	return os.OpenFile("CONIN$", os.O_RDWR, 0)
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// highlight is a bookmarked passage, as a notes vault shows it.
+type highlight struct {
+	pos  int
+	text string
+}
+
+// vaultNote is what a note in the notes vault says about a document.
+type vaultNote struct {
+	title      string
+	source     string
+	position   int
+	total      int
+	wordsRead  int
+	readTime   time.Duration
+	wpm        int
+	sessions   int
+	lastRead   time.Time
+	highlights []highlight
+}
+
+// The highlights go between these markers, so that the rest of the note is
+// left to its owner.
+const (
+	highlightsStart = "<!-- zippy:highlights -->"
+	highlightsEnd   = "<!-- /zippy:highlights -->"
+)
+
+// vaultKeys are the frontmatter properties zippy owns; others are kept.
+var vaultKeys = []string{"source", "progress", "position", "words", "words_read", "reading_minutes", "average_wpm", "sessions", "last_read"}
+
+// noteName turns a document's file name into a note's, dropping the
+// characters note apps do not allow in one.
+func noteName(document string) string {
+	name := strings.TrimSuffix(filepath.Base(document), filepath.Ext(document))
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`*"\/<>:|?#^[]`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Untitled"
+	}
+	return name + ".md"
+}
+
+// notePath picks the note for a document in the vault at dir. Documents
+// with the same file name in different folders would share a note, so when
+// the note by that name has another source, the document's gets a short
+// hash of its path after the name instead. It also returns what the note
+// says so far.
+func notePath(dir, key string) (string, []byte, error) {
+	name := noteName(key)
+	sum := sha256.Sum256([]byte(key))
+	for _, candidate := range []string{name, strings.TrimSuffix(name, ".md") + " " + hex.EncodeToString(sum[:4]) + ".md"} {
+		path := filepath.Join(dir, candidate)
+		old, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return path, nil, nil
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if src, ok := noteSource(string(old)); !ok || src == key {
+			return path, old, nil
+		}
+	}
+	return "", nil, fmt.Errorf("notes for other documents are in the way of %s's", name)
+}
+
+// noteSource reads the source property from a note's frontmatter. A note
+// without one is taken to be the document's own.
+func noteSource(note string) (string, bool) {
+	rest, ok := strings.CutPrefix(note, "---\n")
+	if !ok {
+		return "", false
+	}
+	front, _, _ := strings.Cut(rest, "\n---\n")
+	for _, line := range strings.Split(front, "\n") {
+		if v, ok := strings.CutPrefix(line, "source:"); ok {
+			src, err := strconv.Unquote(strings.TrimSpace(v))
+			if err != nil {
+				return strings.TrimSpace(v), true
+			}
+			return src, true
+		}
+	}
+	return "", false
+}
+
+func (n vaultNote) frontmatter() []string {
+	progress := 0
+	if n.total > 0 {
+		progress = min((n.position+1)*100/n.total, 100)
+	}
+	return []string{
+		fmt.Sprintf("source: %q", n.source),
+		fmt.Sprintf("progress: %d", progress),
+		fmt.Sprintf("position: %d", n.position+1),
+		fmt.Sprintf("words: %d", n.total),
+		fmt.Sprintf("words_read: %d", n.wordsRead),
+		fmt.Sprintf("reading_minutes: %d", int(n.readTime.Minutes())),
+		fmt.Sprintf("average_wpm: %d", n.wpm),
+		fmt.Sprintf("sessions: %d", n.sessions),
+		"last_read: " + n.lastRead.Local().Format(time.RFC3339),
+	}
+}
+
+func (n vaultNote) highlightsBlock() string {
+	var b strings.Builder
+	b.WriteString(highlightsStart + "\n## Highlights\n\n")
+	if len(n.highlights) == 0 {
+		b.WriteString("None yet; bookmark a word while reading to add one.\n")
+	}
+	for _, h := range n.highlights {
+		if h.text == "" {
+			fmt.Fprintf(&b, "- Word %d\n", h.pos+1)
+		} else {
+			fmt.Fprintf(&b, "- %s… (word %d)\n", h.text, h.pos+1)
+		}
+	}
+	b.WriteString(highlightsEnd)
+	return b.String()
+}
+
+// render writes the note over old, the note as it was, keeping whatever
+// its owner added to the frontmatter and outside the highlights.
+func (n vaultNote) render(old string) string {
+	var own []string
+	body := "# " + n.title + "\n\n" + highlightsStart + "\n" + highlightsEnd + "\n"
+	if rest, ok := strings.CutPrefix(old, "---\n"); ok {
+		if front, after, ok := strings.Cut(rest, "\n---\n"); ok {
+			ours := false
+			for _, line := range strings.Split(front, "\n") {
+				// Indented lines and list items continue the property
+				// above them.
+				if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-") {
+					key, _, _ := strings.Cut(line, ":")
+					ours = slices.Contains(vaultKeys, strings.TrimSpace(key))
+				}
+				if !ours {
+					own = append(own, line)
+				}
+			}
+			body = after
+		}
+	} else if old != "" {
+		body = old
+	}
+
+	start, end := strings.Index(body, highlightsStart), strings.Index(body, highlightsEnd)
+	if start >= 0 && end > start {
+		body = body[:start] + n.highlightsBlock() + body[end+len(highlightsEnd):]
+	} else {
+		body = strings.TrimRight(body, "\n") + "\n\n" + n.highlightsBlock() + "\n"
+	}
+	front := append(n.frontmatter(), own...)
+	return "---\n" + strings.Join(front, "\n") + "\n---\n" + body
+}
+
+// updateVaultNote writes the note for the document with the given key in
+// the vault at dir, from what the document store and the statistics store
+// know about it.
+func updateVaultNote(dir, key string, highlights []highlight) error {
+	store, err := loadDocStore()
+	if err != nil {
+		return err
+	}
+	d, ok := store.Documents[key]
+	if !ok {
+		return nil
+	}
+	sessions, err := loadSessions()
+	if err != nil {
+		return err
+	}
+	n := vaultNote{
+		title:      strings.TrimSuffix(noteName(key), ".md"),
+		source:     key,
+		position:   d.Position,
+		total:      d.Total,
+		wordsRead:  d.WordsRead,
+		readTime:   d.ReadTime,
+		wpm:        d.averageWPM(),
+		lastRead:   d.LastRead,
+		highlights: highlights,
+	}
+	for _, s := range sessions {
+		if s.Document == key {
+			n.sessions++
+		}
+	}
+
+	path, old, err := notePath(dir, key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(n.render(string(old))), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
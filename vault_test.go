@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVaultNote(t *testing.T) {
+	n := vaultNote{
+		title:      "foo",
+		source:     "/books/foo.txt",
+		position:   499,
+		total:      2000,
+		wordsRead:  6200,
+		readTime:   14 * time.Minute,
+		wpm:        442,
+		sessions:   3,
+		lastRead:   time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC),
+		highlights: []highlight{{pos: 9, text: "call me Ishmael"}, {pos: 1200}},
+	}
+	note := n.render("")
+	for _, want := range []string{"---\nsource: \"/books/foo.txt\"\nprogress: 25\nposition: 500\n", "sessions: 3\n", "# foo\n\n", "- call me Ishmael… (word 10)\n- Word 1201\n"} {
+		if !strings.Contains(note, want) {
+			t.Fatalf("expected %q in the note:\n%s", want, note)
+		}
+	}
+
+	// What the owner adds to the note is kept when it is updated.
+	edited := strings.Replace(note, "sessions: 3\n", "sessions: 3\ntags:\n  - books\n", 1) + "\nMy thoughts.\n"
+	n.sessions, n.highlights = 4, nil
+	note = n.render(edited)
+	for _, want := range []string{"sessions: 4\n", "\ntags:\n  - books\n---\n", "None yet", "My thoughts.\n"} {
+		if !strings.Contains(note, want) {
+			t.Fatalf("expected %q in the updated note:\n%s", want, note)
+		}
+	}
+	if strings.Contains(note, "sessions: 3") || strings.Contains(note, "Ishmael") {
+		t.Fatalf("expected the old stats and highlights to be replaced:\n%s", note)
+	}
+}
+
+func TestUpdateVaultNote(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	vault := t.TempDir()
+	if err := updateDoc("/books/a: b.txt", func(d *docState) { d.Position, d.Total = 9, 10 }); err != nil {
+		t.Fatal(err)
+	}
+	recordSession(session{Document: "/books/a: b.txt", Words: 10, Duration: time.Minute})
+	if err := updateVaultNote(vault, "/books/a: b.txt", nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(vault, "a- b.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "progress: 100\n") || !strings.Contains(string(data), "sessions: 1\n") {
+		t.Fatalf("unexpected note:\n%s", data)
+	}
+}
+
+func TestVaultNotesForSameNamedDocuments(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	vault := t.TempDir()
+	for _, key := range []string{"/books/notes.txt", "/work/notes.txt", "/books/notes.txt"} {
+		if err := updateDoc(key, func(d *docState) { d.Position, d.Total = 0, 10 }); err != nil {
+			t.Fatal(err)
+		}
+		if err := updateVaultNote(vault, key, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := os.ReadDir(vault)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected a note each, got %v (%v)", entries, err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(vault, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "/work/notes.txt"
+		if e.Name() == "notes.md" {
+			want = "/books/notes.txt"
+		}
+		if src, _ := noteSource(string(data)); src != want {
+			t.Errorf("expected %s to be the note for %s, got %s", e.Name(), want, src)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchMode is what -watch does when the file changes on disk: nothing, show
+// that it changed, or reload it.
+type watchMode int
+
+const (
+	watchOff watchMode = iota
+	watchNotify
+	watchReload
+)
+
+var watchNames = []string{"off", "notify", "reload"}
+
+func (w watchMode) String() string { return watchNames[w] }
+
+// Set parses -watch. A bare -watch means notify.
+func (w *watchMode) Set(s string) error {
+	switch s {
+	case "true":
+		*w = watchNotify
+		return nil
+	case "false":
+		*w = watchOff
+		return nil
+	}
+	for i, name := range watchNames {
+		if s == name {
+			*w = watchMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("use notify or reload")
+}
+
+func (w *watchMode) IsBoolFlag() bool { return true }
+
+// strayWatchMode reports whether arg, left over after parsing, is a mode
+// meant for -watch. Being a boolean flag, -watch only takes one after an =,
+// so -watch reload reads as -watch and a file named reload.
+func strayWatchMode(w watchMode, arg string) bool {
+	if w != watchNotify || !slices.Contains(watchNames, arg) {
+		return false
+	}
+	_, err := os.Stat(arg)
+	return err != nil
+}
+
+// fileChangedMsg is sent when the watched file has changed.
+type fileChangedMsg struct{}
+
+// watchSettle is how long the file has to stay unchanged before it counts as
+// changed, since editors often save in several steps.
+const watchSettle = 200 * time.Millisecond
+
+// watchFile sends fileChangedMsg whenever the file at path is written
+// or replaced. The directory is watched rather than the file, so that editors
+// that save by renaming a new file over the old one are noticed too. The
+// returned function stops watching.
+func watchFile(path string, send func(tea.Msg)) (stop func(), err error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	path = filepath.Clean(path)
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		w.Close()
+		return nil, err
+	}
+	go func() {
+		var settle *time.Timer
+		for {
+			select {
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(ev.Name) != path || !ev.Has(fsnotify.Write) && !ev.Has(fsnotify.Create) {
+					continue
+				}
+				if settle != nil {
+					settle.Stop()
+				}
+				settle = time.AfterFunc(watchSettle, func() { send(fileChangedMsg{}) })
+			case _, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return func() { w.Close() }, nil
+}
+
+// fileChanged handles a change to the watched file.
+func (m *model) fileChanged() tea.Cmd {
+	if m.watch == watchReload {
+		return m.reloadFile()
+	}
+	m.changedOnDisk = true
+	return nil
+}
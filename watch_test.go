@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestWatchFlag(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want watchMode
+	}{
+		{nil, watchOff},
+		{[]string{"-watch"}, watchNotify},
+		{[]string{"-watch=reload"}, watchReload},
+	} {
+		var w watchMode
+		fs := flag.NewFlagSet("zippy", flag.ContinueOnError)
+		fs.Var(&w, "watch", "")
+		if err := fs.Parse(tc.args); err != nil || w != tc.want {
+			t.Errorf("%v: got %v, %v; want %v", tc.args, w, err, tc.want)
+		}
+	}
+}
+
+func TestStrayWatchMode(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if !strayWatchMode(watchNotify, "reload") {
+		t.Error("expected -watch reload to be caught")
+	}
+	if strayWatchMode(watchReload, "reload") || strayWatchMode(watchNotify, "book.txt") {
+		t.Error("expected only a mode after a bare -watch to be caught")
+	}
+	if code := runRead([]string{"-watch", "reload", "book.txt"}, pickNone); code != 2 {
+		t.Errorf("expected a usage error, got %d", code)
+	}
+	if err := os.WriteFile("reload", []byte("a file really named reload"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if strayWatchMode(watchNotify, "reload") {
+		t.Error("expected a file named reload to be read")
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.txt")
+	if err := os.WriteFile(path, []byte("one two"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan tea.Msg, 10)
+	stop, err := watchFile(path, func(msg tea.Msg) { changed <- msg })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	// Saving by renaming a new file over the old one counts too.
+	tmp := path + ".new"
+	if err := os.WriteFile(tmp, []byte("one two three"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-changed:
+		if _, ok := msg.(fileChangedMsg); !ok {
+			t.Fatalf("expected fileChangedMsg, got %T", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the change to be noticed")
+	}
+}
+
+func TestFileChangedIndicator(t *testing.T) {
+	m := model{stream: newEagerStream(tokenize("one two"), false), width: 200, height: 3, watch: watchNotify, markA: -1, markB: -1}
+	next, _ := m.Update(fileChangedMsg{})
+	m = next.(model)
+	if !strings.Contains(m.View(), "File changed, R to reload") {
+		t.Fatalf("expected the indicator, got %q", m.View())
+	}
+	// It stays until the file is reloaded.
+	if m = press(m, "l"); !m.changedOnDisk {
+		t.Fatal("expected the indicator to stay")
+	}
+}
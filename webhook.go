@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// webhookConfig is where to report finished sessions to, set under
+// "webhook" in the config file.
+type webhookConfig struct {
+	URL string `json:"url,omitempty"`
+	// Headers are sent with every request, e.g. for an API key.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// webhookPayload is the JSON posted to the webhook when a session ends.
+type webhookPayload struct {
+	Document  string    `json:"document"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	WordsRead int       `json:"words_read"`
+	Duration  float64   `json:"duration_seconds"`
+	WPM       int       `json:"wpm"`
+	Position  int       `json:"position"`
+	Total     int       `json:"total,omitempty"`
+	Percent   int       `json:"percent"`
+	Finished  bool      `json:"finished"`
+}
+
+func newWebhookPayload(s session, m model, end time.Time) webhookPayload {
+	status := m.liveStatus()
+	return webhookPayload{
+		Document:  s.Document,
+		Start:     s.Start,
+		End:       end,
+		WordsRead: s.Words,
+		Duration:  s.Duration.Round(time.Second).Seconds(),
+		WPM:       s.wpm(),
+		Position:  status.Position,
+		Total:     status.Total,
+		Percent:   status.Percent,
+		Finished:  !m.stream.CanAdvance(),
+	}
+}
+
+// sendWebhook posts p to the webhook, failing on any reply but a success.
+func sendWebhook(cfg webhookConfig, p webhookPayload) error {
+	return postJSON(cfg.URL, cfg.Headers, p)
+}
+
+// postJSON posts body as JSON to url with the given headers, failing on any
+// reply but a success.
+func postJSON(url string, headers map[string]string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s replied %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhook(t *testing.T) {
+	var (
+		got  webhookPayload
+		auth string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		if got.Document == "" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	m := model{stream: newEagerStream(tokenize("one two three four"), true)}
+	m.stream.Seek(3)
+	start := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	s := session{Document: "/books/numbers.txt", Start: start, Words: 600, Duration: 2 * time.Minute}
+	cfg := webhookConfig{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer secret"}}
+	if err := sendWebhook(cfg, newWebhookPayload(s, m, start.Add(3*time.Minute))); err != nil {
+		t.Fatal(err)
+	}
+	want := webhookPayload{Document: "/books/numbers.txt", Start: start, End: start.Add(3 * time.Minute), WordsRead: 600, Duration: 120, WPM: 300, Position: 4, Total: 4, Percent: 100, Finished: true}
+	if !got.Start.Equal(want.Start) || !got.End.Equal(want.End) {
+		t.Fatalf("expected the session's times, got %v to %v", got.Start, got.End)
+	}
+	got.Start, got.End = want.Start, want.End
+	if got != want || auth != "Bearer secret" {
+		t.Fatalf("expected %+v with the configured header, got %+v and %q", want, got, auth)
+	}
+
+	s.Document = ""
+	if err := sendWebhook(cfg, newWebhookPayload(s, m, start)); err == nil {
+		t.Fatal("expected a failed request to be reported")
+	}
+}
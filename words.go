@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// unknownWord is a word flagged while reading, kept with the sentence it was
+// met in for building vocabulary.
+type unknownWord struct {
+	Word     string    `json:"word"`
+	Sentence string    `json:"sentence"`
+	Position int       `json:"position"`
+	Added    time.Time `json:"added"`
+}
+
+// bareWord strips the punctuation around a word, keeping what is inside it
+// such as apostrophes and hyphens.
+func bareWord(s string) string {
+	return strings.TrimFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+}
+
+// toggleUnknown flags the current word as unknown, or unflags it if it is
+// already, and persists the result.
+func (m *model) toggleUnknown() {
+	if m.docKey == "" {
+		m.notice = "Flagging words needs file input"
+		return
+	}
+	tok, ok := m.stream.Current()
+	word := bareWord(tok.text)
+	if !ok || word == "" {
+		return
+	}
+	pos := m.stream.Pos()
+	if i := slices.IndexFunc(m.unknown, func(w unknownWord) bool { return w.Position == pos }); i >= 0 {
+		m.unknown = slices.Delete(m.unknown, i, i+1)
+		m.notice = fmt.Sprintf("Unflagged %q", word)
+	} else {
+		m.unknown = append(m.unknown, unknownWord{Word: word, Sentence: m.sentence(), Position: pos, Added: m.now()})
+		m.notice = fmt.Sprintf("Flagged %q as unknown", word)
+	}
+	unknown := slices.Clone(m.unknown)
+	if err := updateDoc(m.docKey, func(d *docState) { d.Unknown = unknown }); err != nil {
+		m.notice = fmt.Sprintf("Could not save flagged words: %v", err)
+	}
+}
+
+// flaggedWords returns the flagged words of every document in the store, or
+// only of the one with the given key, by document and then in reading
+// order.
+func flaggedWords(store *docStore, key string) map[string][]unknownWord {
+	words := map[string][]unknownWord{}
+	for path, d := range store.Documents {
+		if len(d.Unknown) == 0 || (key != "" && path != key) {
+			continue
+		}
+		ws := slices.Clone(d.Unknown)
+		slices.SortFunc(ws, func(a, b unknownWord) int { return a.Position - b.Position })
+		words[path] = ws
+	}
+	return words
+}
+
+func writeWords(w io.Writer, words map[string][]unknownWord) {
+	for i, path := range slices.Sorted(maps.Keys(words)) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, path)
+		for _, word := range words[path] {
+			fmt.Fprintf(w, "  %s: %s\n", word.Word, word.Sentence)
+		}
+	}
+}
+
+// runWords implements `zippy words`, listing the words flagged as unknown,
+// and `zippy words export`.
+func runWords(args []string) int {
+	if len(args) > 0 && args[0] == "export" {
+		return runWordsExport(args[1:])
+	}
+	fs := flag.NewFlagSet("words", flag.ExitOnError)
+	var file string
+	fs.StringVar(&file, "file", "", "only list the words flagged in this file")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s words [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s words export [-file FILE] [-deck NAME]\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "List the words flagged as unknown while reading, with their sentences, or")
+		fmt.Fprintln(os.Stderr, "export them for Anki.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	store, err := loadDocStore()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read saved state:", err)
+		return 1
+	}
+	words := flaggedWords(store, docKey(file))
+	if len(words) == 0 {
+		fmt.Println("No words flagged yet; press f while reading to flag one.")
+		return 0
+	}
+	writeWords(os.Stdout, words)
+	return 0
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFlagUnknownWords(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	m := model{stream: newEagerStream(tokenize("The sky was cerulean. Birds flew by."), true), docKey: "/books/a.txt"}
+	m.stream.Seek(3)
+	m = press(m, "f")
+	m.stream.Seek(5)
+	m = press(m, "f", "f")
+	if len(m.unknown) != 1 || m.unknown[0].Word != "cerulean" || m.unknown[0].Sentence != "The sky was cerulean." {
+		t.Fatalf("expected cerulean flagged with its sentence, got %+v", m.unknown)
+	}
+
+	s, err := loadDocStore()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	var b strings.Builder
+	writeWords(&b, flaggedWords(s, ""))
+	if want := "/books/a.txt\n  cerulean: The sky was cerulean.\n"; b.String() != want {
+		t.Fatalf("expected %q, got %q", want, b.String())
+	}
+	if len(flaggedWords(s, "/books/b.txt")) != 0 {
+		t.Fatal("expected no words for another document")
+	}
+}
+
+func TestAnkiExport(t *testing.T) {
+	words := map[string][]unknownWord{
+		"/books/a.txt": {{Word: "cat", Sentence: "The catalogue <lists> a cat."}},
+	}
+	var b strings.Builder
+	if err := writeAnki(&b, words, "Vocab", func(string) string { return "a small feline" }); err != nil {
+		t.Fatal(err)
+	}
+	want := "#separator:tab\n#html:true\n#columns:Word\tSentence\tSource\tDefinition\n#deck:Vocab\n" +
+		"cat\tThe catalogue &lt;lists&gt; a <b>cat</b>.\ta.txt\ta small feline\n"
+	if b.String() != want {
+		t.Fatalf("expected %q, got %q", want, b.String())
+	}
+}
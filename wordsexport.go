@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// writeAnki prints the flagged words as notes Anki can import: a header
+// telling it how to read the file, then a tab-separated line per word with
+// the word, its sentence with the word in bold, the document's name and a
+// definition if define knows one.
+func writeAnki(w io.Writer, words map[string][]unknownWord, deck string, define func(string) string) error {
+	header := "#separator:tab\n#html:true\n#columns:Word\tSentence\tSource\tDefinition\n"
+	if deck != "" {
+		header += "#deck:" + deck + "\n"
+	}
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	for _, path := range slices.Sorted(maps.Keys(words)) {
+		for _, word := range words[path] {
+			definition := ""
+			if define != nil {
+				definition = define(word.Word)
+			}
+			fields := []string{
+				ankiField(word.Word),
+				boldWord(ankiField(word.Sentence), ankiField(word.Word)),
+				ankiField(filepath.Base(path)),
+				ankiField(definition),
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// definitionText is the first few senses of a word, a line each, or "" if
+// the dictionary does not know it or cannot be reached.
+func definitionText(d dictionary, word string) string {
+	senses, _ := lookUp(d, word)
+	var lines []string
+	for _, s := range senses[:min(len(senses), 3)] {
+		lines = append(lines, s.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ankiField escapes text for an HTML field, with line breaks as <br> since
+// a note takes up one line.
+func ankiField(s string) string {
+	s = html.EscapeString(strings.ReplaceAll(s, "\t", " "))
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "<br>")
+}
+
+// boldWord puts the first whole occurrence of word in the sentence in bold.
+func boldWord(sentence, word string) string {
+	for i := 0; ; {
+		j := strings.Index(sentence[i:], word)
+		if j < 0 || word == "" {
+			return sentence
+		}
+		start, end := i+j, i+j+len(word)
+		if !wordRuneBefore(sentence, start) && !wordRuneAfter(sentence, end) {
+			return sentence[:start] + "<b>" + word + "</b>" + sentence[end:]
+		}
+		i = end
+	}
+}
+
+func wordRuneBefore(s string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return i > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func wordRuneAfter(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return i < len(s) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// runWordsExport implements `zippy words export`, which prints the flagged
+// words for a flashcard app.
+func runWordsExport(args []string) int {
+	fs := flag.NewFlagSet("words export", flag.ExitOnError)
+	var file, deck string
+	fs.StringVar(&file, "file", "", "only export the words flagged in this file")
+	fs.StringVar(&deck, "deck", "", "name of the Anki deck to import the words into")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s words export [options] > words.txt\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Print the flagged words as tab-separated notes for Anki's File > Import, with")
+		fmt.Fprintln(os.Stderr, "definitions from the dictionary the define key uses, if there is one.")
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	store, err := loadDocStore()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read saved state:", err)
+		return 1
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read config:", err)
+		return 1
+	}
+	dict, err := newDictionary(cfg.Dictionary)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Config:", err)
+		return 1
+	}
+	var define func(string) string
+	if dict != nil {
+		define = func(word string) string { return definitionText(dict, word) }
+	}
+	if err := writeAnki(os.Stdout, flaggedWords(store, docKey(file)), deck, define); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}